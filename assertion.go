@@ -0,0 +1,80 @@
+package dagger
+
+import (
+	"context"
+	"fmt"
+)
+
+type requireStep[S any] struct {
+	pred Selector[S]
+	msg  string
+}
+
+var _ StepNamer = (*requireStep[any])(nil)
+
+func (s *requireStep[S]) StepName() fmt.Stringer {
+	return fmtStr(fmt.Sprintf("require(%s)", SelectorName(s.pred)))
+}
+
+func (s *requireStep[S]) Exec(ctx context.Context, state S) error {
+	return checkAssertion(ctx, state, "precondition", s.pred, s.msg)
+}
+
+type ensureStep[S any] struct {
+	step Step[S]
+	pred Selector[S]
+	msg  string
+}
+
+var (
+	_ StepNamer         = (*ensureStep[any])(nil)
+	_ middlewareSkipper = (*ensureStep[any])(nil)
+	_ rebuilder[any]    = (*ensureStep[any])(nil)
+	_ wrapperStep[any]  = (*ensureStep[any])(nil)
+)
+
+func (s *ensureStep[S]) StepName() fmt.Stringer { return StepName(s.step) }
+
+func (s *ensureStep[S]) canSkip() bool { return canSkip(s.step) }
+
+func (s *ensureStep[S]) Exec(ctx context.Context, state S) error {
+	if err := s.step.Exec(ctx, state); ignoreSkip(err) != nil {
+		return err
+	}
+
+	return checkAssertion(ctx, state, "postcondition", s.pred, s.msg)
+}
+
+func (s *ensureStep[S]) Unwrap() Step[S] { return s.step }
+
+func (s *ensureStep[S]) wrapped() Step[S] { return s.step }
+
+func (s *ensureStep[S]) rebuild(wrap wrapFunc[S]) Step[S] {
+	return &ensureStep[S]{step: rebuild(s.step, wrap), pred: s.pred, msg: s.msg}
+}
+
+// Require returns a Step which fails with ErrAssertion if pred returns false,
+// e.g. to validate the invariants of the state between the stages of a DAG,
+// rather than deep inside the logic of the leaf Step(s).
+func Require[S any](pred Selector[S], msg string) Step[S] {
+	return &requireStep[S]{pred: pred, msg: msg}
+}
+
+// Ensure decorates the Step to fail with ErrAssertion if pred returns false
+// once the Step succeeds, i.e. it checks a postcondition of the Step. The
+// error of the Step, if any, is returned as is, without checking pred.
+func Ensure[S any](step Step[S], pred Selector[S], msg string) Step[S] {
+	return &ensureStep[S]{step: step, pred: pred, msg: msg}
+}
+
+// checkAssertion returns an ErrAssertion if pred returns false for the state, the
+// path of the Step is known if the execution is instrumented, see ErrAssertion.
+func checkAssertion[S any](ctx context.Context, state S, kind string, pred Selector[S], msg string) error {
+	if pred(state) {
+		return nil
+	}
+
+	info, _ := stepInfoFromContext(ctx)
+
+	return &ErrAssertion{kind: kind, selector: SelectorName(pred), path: info.Path, msg: msg}
+}
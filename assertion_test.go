@@ -0,0 +1,54 @@
+package dagger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequire(t *testing.T) {
+	assert.NoError(t, Require(alwaysTrue, "quota available").Exec(context.TODO(), testState{}))
+
+	errAssertion := new(ErrAssertion)
+
+	err := Require(alwaysFalse, "quota available").Exec(context.TODO(), testState{})
+	assert.ErrorAs(t, err, &errAssertion)
+	assert.Equal(t, "dagger: precondition 'dagger:alwaysFalse' failed: quota available", err.Error())
+	assert.Equal(t, "dagger:alwaysFalse", errAssertion.Selector().String())
+	assert.Equal(t, "require(dagger:alwaysFalse)", StepName(Require(alwaysFalse, "")).String())
+
+	t.Run("Path", func(t *testing.T) {
+		dag, err := New(Series(NewStep(noopStep), Require(NewSelector("has-quota", alwaysFalse), "quota available")))
+		assert.NoError(t, err)
+
+		err = dag.Exec(WithResults(context.TODO(), NewResults()), testState{})
+		assert.ErrorAs(t, err, &errAssertion)
+		assert.Equal(t, "root/1", errAssertion.Path())
+		assert.Equal(t, "dagger: precondition 'has-quota' failed at 'root/1': quota available", err.Error())
+	})
+}
+
+func TestEnsure(t *testing.T) {
+	executed := 0
+	step := Named("create", NewStep(func(context.Context, testState) error {
+		executed++
+		return nil
+	}))
+
+	assert.NoError(t, Ensure(step, alwaysTrue, "vm created").Exec(context.TODO(), testState{}))
+
+	errAssertion := new(ErrAssertion)
+
+	err := Ensure(step, alwaysFalse, "vm created").Exec(context.TODO(), testState{})
+	assert.ErrorAs(t, err, &errAssertion)
+	assert.Equal(t, "dagger: postcondition 'dagger:alwaysFalse' failed: vm created", err.Error())
+	assert.Equal(t, 2, executed)
+
+	failing := NewStep(func(context.Context, testState) error { return testErrStep })
+	assert.ErrorIs(t, Ensure(failing, alwaysFalse, "vm created").Exec(context.TODO(), testState{}), testErrStep)
+
+	info := stepInfo(Ensure(step, alwaysTrue, ""))
+	assert.Equal(t, "create", info.Name.String())
+	assert.False(t, info.CanSkip)
+}
@@ -0,0 +1,128 @@
+package dagger
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// AuditRecord describes the execution of a leaf Step, see AuditLog.
+type AuditRecord struct {
+	// RunID is the run ID of the execution, if any.
+	RunID string `json:"run_id,omitempty"`
+	// DAG is the name of the Executor, if set with WithName.
+	DAG string `json:"dag,omitempty"`
+	// Meta is the metadata of the execution, if set with WithRunMeta.
+	Meta map[string]string `json:"meta,omitempty"`
+	// Step is the name of the Step.
+	Step string `json:"step"`
+	// Description is the description of the Step, if any, see WithDescription.
+	Description string `json:"description,omitempty"`
+	// Path identifies the Step in the DAG.
+	Path string `json:"path"`
+	// Owner is the owner of the Step, if any, see WithOwner.
+	Owner string `json:"owner,omitempty"`
+	// StateFingerprint is the SHA-256 of the JSON representation of
+	// the state after the Step executed, captured like SnapshotDiff does.
+	StateFingerprint string `json:"state_fingerprint,omitempty"`
+	// Outcome is one of success, failure, skipped or aborted.
+	Outcome string `json:"outcome"`
+	// Error is the error returned by the Step, if any.
+	Error    string        `json:"error,omitempty"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// AuditLog returns a middleware which reports an AuditRecord to sink
+// for every leaf Step executed, e.g. to keep an audit trail of executions.
+// Use AuditWriter to write the records as JSON lines.
+func AuditLog[S any](sink func(record AuditRecord)) MiddlewareFunc[S] {
+	return func(next Step[S], info Info) Step[S] {
+		if info.CanSkip {
+			return next
+		}
+
+		return StepFunc[S](func(ctx context.Context, state S) error {
+			clock := ClockFromContext(ctx)
+			start := clock.Now()
+			err := next.Exec(ctx, state)
+
+			record := AuditRecord{
+				Step:        info.Name.String(),
+				Description: info.Description,
+				Path:        info.Path,
+				Owner:       info.Owner,
+				Outcome:     outcome(err),
+				Start:       start,
+				Duration:    clock.Now().Sub(start),
+			}
+
+			record.RunID, _ = RunIDFromContext(ctx)
+			record.DAG, _ = DAGNameFromContext(ctx)
+			record.Meta = RunMetaFromContext(ctx)
+			record.StateFingerprint, _ = fingerprint(state)
+
+			if err != nil {
+				record.Error = err.Error()
+			}
+
+			sink(record)
+
+			return err
+		})
+	}
+}
+
+// AuditWriter returns a sink for AuditLog, which writes each AuditRecord as
+// a line of JSON to w. Writes are serialized, and their errors are ignored.
+func AuditWriter(w io.Writer) func(record AuditRecord) {
+	var mu sync.Mutex
+
+	return func(record AuditRecord) {
+		b, err := json.Marshal(record)
+		if err != nil {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		_, _ = w.Write(append(b, '\n'))
+	}
+}
+
+func outcome(err error) string {
+	if err == nil {
+		return "success"
+	}
+
+	if Skipped(err) {
+		return "skipped"
+	}
+
+	if _, ok := asAbort(err); ok {
+		return "aborted"
+	}
+
+	return "failure"
+}
+
+// fingerprint returns the SHA-256 of the JSON representation of the state.
+func fingerprint(state any) (string, error) {
+	if s, ok := state.(Snapshotter); ok {
+		state = s.Snapshot()
+	}
+
+	b, err := json.Marshal(state)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,54 @@
+package dagger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type auditState struct {
+	VM string `json:"vm"`
+}
+
+func TestAuditLog(t *testing.T) {
+	dag, err := New(Continue(
+		Named("create", NewStep(func(ctx context.Context, state *auditState) error {
+			state.VM = "vm-1"
+			return nil
+		})),
+		Named("quota", NewStep(func(ctx context.Context, state *auditState) error { return &ErrSkip{} })),
+		Named("notify", NewStep(func(ctx context.Context, state *auditState) error { return testErrStep })),
+	))
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, dag.Use(AuditLog[*auditState](AuditWriter(buf))))
+
+	assert.Error(t, dag.Exec(WithRunID(context.TODO(), "req-42"), &auditState{}))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 3)
+
+	records := make([]AuditRecord, len(lines))
+	for i, line := range lines {
+		assert.NoError(t, json.Unmarshal([]byte(line), &records[i]))
+		assert.Equal(t, "req-42", records[i].RunID)
+		assert.Len(t, records[i].StateFingerprint, 64)
+	}
+
+	assert.Equal(t, "create", records[0].Step)
+	assert.Equal(t, "root/0", records[0].Path)
+	assert.Equal(t, "success", records[0].Outcome)
+	assert.Equal(t, "skipped", records[1].Outcome)
+	assert.Equal(t, "failure", records[2].Outcome)
+	assert.Equal(t, "step error", records[2].Error)
+	assert.Equal(t, records[0].StateFingerprint, records[2].StateFingerprint)
+
+	want, err := fingerprint(&auditState{VM: "vm-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, want, records[0].StateFingerprint)
+}
@@ -0,0 +1,255 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Task is a leaf Step scheduled for execution on a Backend.
+type Task[S any] struct {
+	// ID identifies the Task.
+	ID string
+	// RunID is the run ID of the execution which scheduled the Task, if any.
+	RunID string
+	// Scope identifies the middleware of the Dispatcher which scheduled
+	// the Task, i.e. the Executor of the Step, see Dispatcher.Middleware.
+	Scope int
+	// Path identifies the position of the Step in the DAG.
+	Path string
+	// Step is the name of the Step.
+	Step string
+	// State is the state the Step is executed with.
+	State S
+
+	// ctx is the context of the execution which scheduled the Task, it
+	// is only carried by Backend(s) handing over the Task as is, e.g.
+	// LocalBackend, and is not serialized by the others.
+	ctx context.Context
+}
+
+// Backend drives the execution of leaf Step(s), it decouples scheduling them,
+// within Exec, from executing them, within Dispatcher.Work. It can be
+// implemented on top of durable engines, like a DB backed queue, so that the
+// code of the Step(s) stays unchanged. LocalBackend is the in-process default.
+//
+// Backends crossing process boundaries must serialize the state, see Codec.
+// The changes made to the state by a Step are then only visible to the
+// Step(s) executing after it if the Backend carries them back.
+type Backend[S any] interface {
+	// Schedule schedules the Task for execution.
+	Schedule(ctx context.Context, task Task[S]) error
+	// Next blocks until a scheduled Task is available, and returns it.
+	Next(ctx context.Context) (Task[S], error)
+	// Complete records the completion of the Task with the given ID,
+	// err is the error returned by its Step.
+	Complete(ctx context.Context, taskID string, err error) error
+	// Await blocks until the Task with the given ID is completed,
+	// and returns the error recorded for it.
+	Await(ctx context.Context, taskID string) error
+}
+
+// Dispatcher executes the leaf Step(s) of a DAG via a Backend.
+type Dispatcher[S any] struct {
+	backend Backend[S]
+
+	mu     sync.RWMutex
+	scopes int
+	steps  map[dispatchKey]Step[S]
+}
+
+// dispatchKey identifies a Step of a Dispatcher, see Task.
+type dispatchKey struct {
+	scope int
+	path  string
+}
+
+// NewDispatcher creates a Dispatcher for the Backend.
+func NewDispatcher[S any](backend Backend[S]) *Dispatcher[S] {
+	return &Dispatcher[S]{backend: backend, steps: make(map[dispatchKey]Step[S])}
+}
+
+// Middleware returns the middleware which schedules the leaf Step(s) on the
+// Backend and awaits their completion. It must be added to the Executor with
+// Use, after the middlewares which should wrap the scheduling of the Step(s).
+//
+// Each call returns a middleware with its own Task.Scope, numbered in order,
+// which keeps apart the Step(s) of different Executor(s) at the same path.
+// A middleware must thus be added to one Executor only, and processes
+// sharing a Backend must call Middleware for the same Executor(s), in the
+// same order.
+func (d *Dispatcher[S]) Middleware() MiddlewareFunc[S] {
+	d.mu.Lock()
+	scope := d.scopes
+	d.scopes++
+	d.mu.Unlock()
+
+	return func(next Step[S], info Info) Step[S] {
+		if info.CanSkip {
+			return next
+		}
+
+		d.mu.Lock()
+		d.steps[dispatchKey{scope: scope, path: info.Path}] = next
+		d.mu.Unlock()
+
+		name := info.Name.String()
+
+		warmup := isWarmupStep(info)
+
+		return StepFunc[S](func(ctx context.Context, state S) error {
+			// The workers do not know about Warmup, the Step(s) it skips are not scheduled.
+			if !warmup && warmingUp(ctx) {
+				return &ErrSkip{}
+			}
+
+			runID, _ := RunIDFromContext(ctx)
+			task := Task[S]{ID: NewRunID(), RunID: runID, Scope: scope, Path: info.Path, Step: name, State: state, ctx: ctx}
+
+			if err := d.backend.Schedule(ctx, task); err != nil {
+				return err
+			}
+
+			return d.backend.Await(ctx, task.ID)
+		})
+	}
+}
+
+// Work executes the Task(s) of the Backend, one at a time, until ctx is done
+// or the Backend fails, and returns the error. The Executor(s) the Middleware
+// is added to must be compiled, i.e. the Middleware must be added, before
+// calling Work. Work can be called from multiple goroutines, and processes,
+// to execute Task(s) concurrently.
+//
+// The context of a Task is canceled once the context of the execution which
+// scheduled it is done, if the Backend hands over the Task within the process,
+// e.g. LocalBackend. Otherwise, Task(s) which are no longer awaited are
+// executed nonetheless. The ErrUnknownTask returned by Backend.Complete for
+// Task(s) which are no longer awaited is ignored.
+func (d *Dispatcher[S]) Work(ctx context.Context) error {
+	for {
+		task, err := d.backend.Next(ctx)
+		if err != nil {
+			return err
+		}
+
+		d.mu.RLock()
+		step, ok := d.steps[dispatchKey{scope: task.Scope, path: task.Path}]
+		d.mu.RUnlock()
+
+		if ok {
+			err = d.exec(ctx, step, task)
+		} else {
+			err = &ErrUnknownStep{path: task.Path}
+		}
+
+		if err := d.backend.Complete(ctx, task.ID, err); err != nil {
+			var errUnknownTask *ErrUnknownTask
+			if !errors.As(err, &errUnknownTask) {
+				return err
+			}
+		}
+	}
+}
+
+func (d *Dispatcher[S]) exec(ctx context.Context, step Step[S], task Task[S]) error {
+	if task.RunID != "" {
+		ctx = WithRunID(ctx, task.RunID)
+	}
+
+	if task.ctx != nil {
+		var cancel context.CancelCauseFunc
+		ctx, cancel = context.WithCancelCause(ctx)
+		defer cancel(nil)
+
+		stop := context.AfterFunc(task.ctx, func() { cancel(context.Cause(task.ctx)) })
+		defer stop()
+	}
+
+	return step.Exec(ctx, task.State)
+}
+
+// LocalBackend is a Backend executing the Task(s) within the process, they
+// are handed over from Schedule to Next as is, without serializing the state.
+type LocalBackend[S any] struct {
+	queue chan Task[S]
+
+	mu      sync.Mutex
+	results map[string]chan error
+}
+
+// NewLocalBackend creates a LocalBackend.
+func NewLocalBackend[S any]() *LocalBackend[S] {
+	return &LocalBackend[S]{queue: make(chan Task[S]), results: make(map[string]chan error)}
+}
+
+// Schedule implements Backend, it blocks until a worker picks up the Task.
+func (b *LocalBackend[S]) Schedule(ctx context.Context, task Task[S]) error {
+	b.mu.Lock()
+	b.results[task.ID] = make(chan error, 1)
+	b.mu.Unlock()
+
+	select {
+	case b.queue <- task:
+		return nil
+	case <-ctx.Done():
+		b.forget(task.ID)
+		return ctx.Err()
+	}
+}
+
+// Next implements Backend.
+func (b *LocalBackend[S]) Next(ctx context.Context) (Task[S], error) {
+	select {
+	case task := <-b.queue:
+		return task, nil
+	case <-ctx.Done():
+		return Task[S]{}, ctx.Err()
+	}
+}
+
+// Complete implements Backend. Only the first completion
+// of a Task is recorded, the later ones are ignored.
+func (b *LocalBackend[S]) Complete(_ context.Context, taskID string, err error) error {
+	b.mu.Lock()
+	result, ok := b.results[taskID]
+	b.mu.Unlock()
+
+	if !ok {
+		return &ErrUnknownTask{taskID: taskID}
+	}
+
+	select {
+	case result <- err:
+	default:
+	}
+
+	return nil
+}
+
+// Await implements Backend.
+func (b *LocalBackend[S]) Await(ctx context.Context, taskID string) error {
+	b.mu.Lock()
+	result, ok := b.results[taskID]
+	b.mu.Unlock()
+
+	if !ok {
+		return &ErrUnknownTask{taskID: taskID}
+	}
+
+	defer b.forget(taskID)
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *LocalBackend[S]) forget(taskID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.results, taskID)
+}
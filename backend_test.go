@@ -0,0 +1,180 @@
+package dagger
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type workerKey struct{}
+
+func TestDispatcher(t *testing.T) {
+	backend := NewLocalBackend[testState]()
+	dispatcher := NewDispatcher[testState](backend)
+
+	var (
+		mu       sync.Mutex
+		executed = map[string]any{}
+	)
+
+	step := func(name string) Step[testState] {
+		return NewStep(func(ctx context.Context, _ testState) error {
+			runID, _ := RunIDFromContext(ctx)
+			assert.Equal(t, "run-1", runID)
+
+			mu.Lock()
+			executed[name] = ctx.Value(workerKey{})
+			mu.Unlock()
+
+			return nil
+		})
+	}
+
+	dag, err := New(Series(step("create"), Parallel(step("build-a"), step("build-b"))))
+	assert.NoError(t, err)
+	assert.NoError(t, dag.Use(dispatcher.Middleware()))
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, worker := range []string{"worker-1", "worker-2"} {
+		wg.Add(1)
+
+		go func(worker string) {
+			defer wg.Done()
+			assert.ErrorIs(t, dispatcher.Work(context.WithValue(ctx, workerKey{}, worker)), context.Canceled)
+		}(worker)
+	}
+
+	assert.NoError(t, dag.Exec(WithRunID(context.TODO(), "run-1"), testState{}))
+
+	cancel()
+	wg.Wait()
+
+	assert.Len(t, executed, 3)
+
+	for name, worker := range executed {
+		assert.NotNilf(t, worker, "step %s was not executed by a worker", name)
+	}
+
+	t.Run("UnknownStep", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+
+		go func() { _ = dispatcher.Work(ctx) }()
+
+		assert.NoError(t, backend.Schedule(context.TODO(), Task[testState]{ID: "task-1", Path: "0/9"}))
+
+		errUnknownStep := new(ErrUnknownStep)
+		assert.ErrorAs(t, backend.Await(context.TODO(), "task-1"), &errUnknownStep)
+
+		errUnknownTask := new(ErrUnknownTask)
+		assert.ErrorAs(t, backend.Complete(context.TODO(), "task-1", nil), &errUnknownTask)
+	})
+
+	t.Run("Executors", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+
+		go func() { _ = dispatcher.Work(ctx) }()
+
+		var (
+			executed []string
+			dags     []*Executor[testState]
+		)
+
+		for _, name := range []string{"create", "delete"} {
+			dag, err := New(Named(name, NewStep(func(context.Context, testState) error {
+				executed = append(executed, name)
+				return nil
+			})))
+			assert.NoError(t, err)
+			assert.NoError(t, dag.Use(dispatcher.Middleware()))
+
+			dags = append(dags, dag)
+		}
+
+		for _, dag := range dags {
+			assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+		}
+
+		assert.Equal(t, []string{"create", "delete"}, executed, "steps at the same path are kept apart")
+	})
+
+	t.Run("AwaitCanceled", func(t *testing.T) {
+		started := make(chan struct{}, 1)
+		release := make(chan struct{})
+
+		dag, err := New(NewStep(func(context.Context, testState) error {
+			started <- struct{}{}
+			<-release
+
+			return nil
+		}))
+		assert.NoError(t, err)
+		assert.NoError(t, dag.Use(dispatcher.Middleware()))
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+
+		worked := make(chan error)
+		go func() { worked <- dispatcher.Work(ctx) }()
+
+		execCtx, cancelExec := context.WithCancel(context.TODO())
+		go func() {
+			<-started
+			cancelExec()
+		}()
+
+		assert.ErrorIs(t, dag.Exec(execCtx, testState{}), context.Canceled)
+		close(release)
+
+		assert.NoError(t, dag.Exec(context.TODO(), testState{}), "the worker keeps working")
+
+		cancel()
+		assert.ErrorIs(t, <-worked, context.Canceled)
+	})
+
+	t.Run("ExecCanceled", func(t *testing.T) {
+		started := make(chan struct{}, 1)
+		stopped := make(chan error, 1)
+
+		dag, err := New(NewStep(func(ctx context.Context, _ testState) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			stopped <- ctx.Err()
+
+			return ctx.Err()
+		}))
+		assert.NoError(t, err)
+		assert.NoError(t, dag.Use(dispatcher.Middleware()))
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+
+		go func() { _ = dispatcher.Work(ctx) }()
+
+		execCtx, cancelExec := context.WithCancel(context.TODO())
+		go func() {
+			<-started
+			cancelExec()
+		}()
+
+		assert.ErrorIs(t, dag.Exec(execCtx, testState{}), context.Canceled)
+		assert.ErrorIs(t, <-stopped, context.Canceled, "the worker step is canceled with the execution")
+	})
+
+	t.Run("CompleteTwice", func(t *testing.T) {
+		backend := NewLocalBackend[testState]()
+
+		go func() { _, _ = backend.Next(context.TODO()) }()
+
+		assert.NoError(t, backend.Schedule(context.TODO(), Task[testState]{ID: "task-1"}))
+		assert.NoError(t, backend.Complete(context.TODO(), "task-1", testErrStep))
+		assert.NoError(t, backend.Complete(context.TODO(), "task-1", nil), "does not block")
+		assert.ErrorIs(t, backend.Await(context.TODO(), "task-1"), testErrStep)
+	})
+}
@@ -0,0 +1,92 @@
+package dagger
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff returns the delay before the retry following the given attempt,
+// starting at 1, prev is the delay it returned for the previous attempt,
+// zero for the first one. It is used by Retry, see WithRetryBackoff.
+type Backoff func(attempt int, prev time.Duration) time.Duration
+
+// ConstantBackoff waits for d before each retry.
+func ConstantBackoff(d time.Duration) Backoff {
+	return func(int, time.Duration) time.Duration { return d }
+}
+
+// ExponentialBackoff waits for base before the first retry,
+// and multiplies the delay by factor after each attempt.
+func ExponentialBackoff(base time.Duration, factor float64) Backoff {
+	return func(attempt int, prev time.Duration) time.Duration {
+		if attempt <= 1 || prev <= 0 {
+			return base
+		}
+
+		return saturate(float64(prev) * factor)
+	}
+}
+
+// DecorrelatedJitterBackoff waits for a random delay between base and three
+// times the previous delay, which spreads the retries of concurrent clients
+// better than exponential backoff does, it is usually Capped.
+func DecorrelatedJitterBackoff(base time.Duration) Backoff {
+	return func(_ int, prev time.Duration) time.Duration {
+		if prev < base {
+			prev = base
+		}
+
+		return base + randDuration(saturate(3*float64(prev))-base)
+	}
+}
+
+// FullJitter waits for a random delay between zero and the one of b.
+func FullJitter(b Backoff) Backoff {
+	return func(attempt int, prev time.Duration) time.Duration {
+		return randDuration(b(attempt, prev))
+	}
+}
+
+// Capped caps the delays of b to max.
+func Capped(b Backoff, max time.Duration) Backoff {
+	return func(attempt int, prev time.Duration) time.Duration {
+		return min(b(attempt, prev), max)
+	}
+}
+
+// saturate converts d to a Duration, capped to the maximum Duration.
+func saturate(d float64) time.Duration {
+	if d >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+
+	return time.Duration(d)
+}
+
+// randDuration returns a random duration in [0, d).
+func randDuration(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Int63n(int64(d)))
+}
+
+// sleep waits for d, it returns false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+
+	timer := ClockFromContext(ctx).NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,58 @@
+package dagger
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// delays returns the delays of the Backoff for n attempts.
+func delays(b Backoff, n int) []time.Duration {
+	var ds []time.Duration
+
+	var prev time.Duration
+	for attempt := 1; attempt <= n; attempt++ {
+		prev = b(attempt, prev)
+		ds = append(ds, prev)
+	}
+
+	return ds
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, []time.Duration{time.Second, time.Second, time.Second}, delays(ConstantBackoff(time.Second), 3))
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second}, delays(ExponentialBackoff(time.Second, 2), 3))
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second},
+		delays(Capped(ExponentialBackoff(time.Second, 2), 3*time.Second), 4))
+
+	t.Run("DecorrelatedJitter", func(t *testing.T) {
+		var prev time.Duration
+		for attempt := 1; attempt <= 100; attempt++ {
+			d := DecorrelatedJitterBackoff(time.Second)(attempt, prev)
+			assert.GreaterOrEqual(t, d, time.Second)
+			assert.Less(t, d, saturate(3*float64(max(prev, time.Second))))
+
+			prev = d
+		}
+
+		for _, d := range delays(Capped(DecorrelatedJitterBackoff(time.Second), 5*time.Second), 100) {
+			assert.LessOrEqual(t, d, 5*time.Second)
+		}
+	})
+
+	t.Run("FullJitter", func(t *testing.T) {
+		for _, d := range delays(FullJitter(ConstantBackoff(time.Second)), 100) {
+			assert.GreaterOrEqual(t, d, time.Duration(0))
+			assert.Less(t, d, time.Second)
+		}
+
+		assert.Equal(t, time.Duration(0), FullJitter(ConstantBackoff(0))(1, 0))
+	})
+
+	t.Run("Overflow", func(t *testing.T) {
+		assert.Equal(t, time.Duration(math.MaxInt64), ExponentialBackoff(time.Second, 2)(2, math.MaxInt64))
+		assert.Positive(t, DecorrelatedJitterBackoff(time.Second)(2, math.MaxInt64))
+	})
+}
@@ -0,0 +1,179 @@
+package dagger
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// BatchOption configures ExecAll and ExecStream.
+type BatchOption func(*batchConfig)
+
+type batchConfig struct {
+	concurrency int
+	failFast    bool
+}
+
+// WithConcurrency sets the number of states executed concurrently,
+// it defaults to runtime.GOMAXPROCS.
+func WithConcurrency(n int) BatchOption {
+	return func(c *batchConfig) {
+		if n > 0 {
+			c.concurrency = n
+		}
+	}
+}
+
+// WithFailFast stops executing new states after the first error, and cancels
+// the context of the running ones with that error as the cause.
+func WithFailFast() BatchOption {
+	return func(c *batchConfig) { c.failFast = true }
+}
+
+func newBatchConfig(opts []BatchOption) batchConfig {
+	c := batchConfig{concurrency: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return c
+}
+
+// BatchResult is the outcome of executing a state received by ExecStream.
+type BatchResult[S any] struct {
+	// Index is the position of the state in the stream.
+	Index int
+	State S
+	Err   error
+}
+
+// ExecAll executes the DAG for each of the states using a pool of workers.
+// The returned errors are in the order of the states.
+//
+// With WithFailFast, states which did not start executing before the
+// first error get an ErrNotExecuted, wrapping the cause of the cancellation.
+func (e *Executor[S]) ExecAll(ctx context.Context, states []S, opts ...BatchOption) []error {
+	in := make(chan S)
+	errs := make([]error, len(states))
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	out := e.execStream(ctx, cancel, in, newBatchConfig(opts))
+
+	go func() {
+		defer close(in)
+
+		for _, state := range states {
+			select {
+			case in <- state:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	received := make([]bool, len(states))
+
+	for res := range out {
+		errs[res.Index] = res.Err
+		received[res.Index] = true
+	}
+
+	for i, ok := range received {
+		if !ok {
+			errs[i] = &ErrNotExecuted{cause: context.Cause(ctx)}
+		}
+	}
+
+	return errs
+}
+
+// ExecStream executes the DAG for each state received from states using a pool
+// of workers, and sends the results on the returned channel, in the order they
+// finish. The returned channel is closed once states is closed and all received
+// states are executed, or, with WithFailFast, after the first error.
+// The returned channel must be drained.
+func (e *Executor[S]) ExecStream(ctx context.Context, states <-chan S, opts ...BatchOption) <-chan BatchResult[S] {
+	ctx, cancel := context.WithCancelCause(ctx)
+
+	out := e.execStream(ctx, cancel, states, newBatchConfig(opts))
+	res := make(chan BatchResult[S])
+
+	go func() {
+		defer close(res)
+		defer cancel(nil)
+
+		for r := range out {
+			res <- r
+		}
+	}()
+
+	return res
+}
+
+type indexedState[S any] struct {
+	index int
+	state S
+}
+
+func (e *Executor[S]) execStream(
+	ctx context.Context,
+	cancel context.CancelCauseFunc,
+	states <-chan S,
+	cfg batchConfig,
+) <-chan BatchResult[S] {
+	jobs := make(chan indexedState[S])
+	out := make(chan BatchResult[S])
+
+	go func() {
+		defer close(jobs)
+
+		for i := 0; ; i++ {
+			select {
+			case state, ok := <-states:
+				if !ok {
+					return
+				}
+
+				select {
+				case jobs <- indexedState[S]{index: i, state: state}:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < cfg.concurrency; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for job := range jobs {
+				// a job may be picked after a failure canceled ctx,
+				// since select does not prefer ctx.Done over jobs
+				var err error
+				if cause := context.Cause(ctx); cause != nil {
+					err = &ErrNotExecuted{cause: cause}
+				} else if err = e.Exec(ctx, job.state); err != nil && cfg.failFast {
+					cancel(err)
+				}
+
+				out <- BatchResult[S]{Index: job.index, State: job.state, Err: err}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
@@ -0,0 +1,110 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type batchState struct{ id int }
+
+var errOdd = errors.New("odd id")
+
+func newBatchDAG(t *testing.T, running, maxRunning *atomic.Int32) *Executor[batchState] {
+	t.Helper()
+
+	dag, err := New(NewStep(func(ctx context.Context, state batchState) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+
+		if state.id%2 == 1 {
+			return errOdd
+		}
+
+		return nil
+	}))
+	assert.NoError(t, err)
+
+	return dag
+}
+
+func TestExecutor_ExecAll(t *testing.T) {
+	states := make([]batchState, 20)
+	for i := range states {
+		states[i] = batchState{id: i}
+	}
+
+	t.Run("AllStates", func(t *testing.T) {
+		var running, maxRunning atomic.Int32
+
+		errs := newBatchDAG(t, &running, &maxRunning).ExecAll(context.TODO(), states, WithConcurrency(3))
+
+		assert.Len(t, errs, len(states))
+
+		for i, err := range errs {
+			if i%2 == 1 {
+				assert.ErrorIs(t, err, errOdd)
+			} else {
+				assert.NoError(t, err)
+			}
+		}
+
+		assert.LessOrEqual(t, maxRunning.Load(), int32(3))
+	})
+
+	t.Run("FailFast", func(t *testing.T) {
+		var running, maxRunning atomic.Int32
+
+		errs := newBatchDAG(t, &running, &maxRunning).ExecAll(context.TODO(), states, WithConcurrency(1), WithFailFast())
+
+		assert.NoError(t, errs[0])
+		assert.ErrorIs(t, errs[1], errOdd)
+
+		notExecuted := new(ErrNotExecuted)
+		assert.False(t, errors.As(errs[1], &notExecuted))
+
+		for _, err := range errs[2:] {
+			assert.ErrorAs(t, err, &notExecuted)
+			assert.ErrorIs(t, err, errOdd)
+		}
+	})
+}
+
+func TestExecutor_ExecStream(t *testing.T) {
+	var running, maxRunning atomic.Int32
+
+	in := make(chan batchState)
+
+	go func() {
+		defer close(in)
+
+		for i := 0; i < 10; i++ {
+			in <- batchState{id: i}
+		}
+	}()
+
+	failed := 0
+	seen := make(map[int]bool)
+
+	for res := range newBatchDAG(t, &running, &maxRunning).ExecStream(context.TODO(), in, WithConcurrency(2)) {
+		assert.Equal(t, res.Index, res.State.id)
+		seen[res.Index] = true
+
+		if res.Err != nil {
+			failed++
+		}
+	}
+
+	assert.Len(t, seen, 10)
+	assert.Equal(t, 5, failed)
+}
@@ -0,0 +1,126 @@
+package dagger
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func noopStep(_ context.Context, _ testState) error { return nil }
+
+func noopMiddleware(next Step[testState], _ Info) Step[testState] {
+	return NewStep(func(ctx context.Context, state testState) error {
+		return next.Exec(ctx, state)
+	})
+}
+
+func nSteps(n int) []Step[testState] {
+	steps := make([]Step[testState], n)
+
+	for i := range steps {
+		steps[i] = NewStep(noopStep)
+	}
+
+	return steps
+}
+
+func deepSeries(depth int) Step[testState] {
+	step := Step[testState](NewStep(noopStep))
+
+	for i := 0; i < depth; i++ {
+		step = Series(NewStep(noopStep), step)
+	}
+
+	return step
+}
+
+func benchmarkExec(b *testing.B, step Step[testState], mws ...MiddlewareFunc[testState]) {
+	b.Helper()
+
+	dag, err := New(step)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	dag.Use(mws...)
+
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := dag.Exec(ctx, testState{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkExec(b *testing.B) {
+	b.Run("DeepSeries", func(b *testing.B) {
+		benchmarkExec(b, deepSeries(100))
+	})
+
+	b.Run("WideContinue", func(b *testing.B) {
+		benchmarkExec(b, Continue(nSteps(1000)...))
+	})
+
+	b.Run("MiddlewareHeavy", func(b *testing.B) {
+		mws := make([]MiddlewareFunc[testState], 10)
+		for i := range mws {
+			mws[i] = noopMiddleware
+		}
+
+		benchmarkExec(b, Series(
+			deepSeries(10),
+			Continue(nSteps(100)...),
+			IfElse(alwaysTrue, Series(nSteps(10)...), Series(nSteps(10)...)),
+		), mws...)
+	})
+}
+
+func BenchmarkStepName(b *testing.B) {
+	b.Run("StepFunc", func(b *testing.B) {
+		step := NewStep(noopStep)
+
+		b.Run("Cached", func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				_ = StepName[testState](step)
+			}
+		})
+
+		b.Run("Uncached", func(b *testing.B) {
+			pc := reflect.ValueOf(step).Pointer()
+
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				_, _ = parseFuncName(pc)
+			}
+		})
+	})
+
+	b.Run("Type", func(b *testing.B) {
+		step := Series[testState]()
+
+		b.Run("Cached", func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				_ = StepName(step)
+			}
+		})
+
+		b.Run("Uncached", func(b *testing.B) {
+			t := reflect.TypeOf(step)
+
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				_ = parseTypeName(t)
+			}
+		})
+	})
+}
@@ -0,0 +1,54 @@
+package dagger
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// WithRunBudget returns a copy of ctx which limits the total wall-clock time
+// of the next execution of an Executor with it. Unlike a deadline of ctx, the
+// budget starts with the execution, and if it is exceeded, Exec returns an
+// ErrBudgetExceeded naming the Step which was executing at the time.
+func WithRunBudget(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, runBudgetKey, d)
+}
+
+// budgetTracker tracks the leaf Step executing within a run budget.
+type budgetTracker struct {
+	current atomic.Pointer[Info]
+}
+
+// withBudget executes the Step within the run budget carried by ctx, if any.
+func withBudget[S any](ctx context.Context, step Step[S], state S) error {
+	d, ok := ctx.Value(runBudgetKey).(time.Duration)
+	if !ok || d <= 0 {
+		return step.Exec(ctx, state)
+	}
+
+	parent := ctx
+	tracker := new(budgetTracker)
+
+	// the budget is consumed by this execution, nested executions must not reapply it
+	ctx = context.WithValue(context.WithValue(ctx, runBudgetKey, time.Duration(0)), budgetTrackerKey, tracker)
+
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+
+	err := step.Exec(ctx, state)
+	if err == nil || ctx.Err() != context.DeadlineExceeded || parent.Err() != nil {
+		return err
+	}
+
+	budgetErr := &ErrBudgetExceeded{budget: d, err: err}
+	if info := tracker.current.Load(); info != nil {
+		budgetErr.stepName = info.Name
+	}
+
+	return budgetErr
+}
+
+func budgetTrackerFromContext(ctx context.Context) *budgetTracker {
+	t, _ := ctx.Value(budgetTrackerKey).(*budgetTracker)
+	return t
+}
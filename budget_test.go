@@ -0,0 +1,48 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRunBudget(t *testing.T) {
+	dag, err := New(Series(
+		Named("validate", NewStep(noopStep)),
+		Named("create", NewStep(func(ctx context.Context, state testState) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})),
+	))
+	assert.NoError(t, err)
+
+	t.Run("Exceeded", func(t *testing.T) {
+		err := dag.Exec(WithRunBudget(context.TODO(), 5*time.Millisecond), testState{})
+
+		errBudget := new(ErrBudgetExceeded)
+		assert.ErrorAs(t, err, &errBudget)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, "create", errBudget.StepName().String())
+	})
+
+	t.Run("ParentCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+
+		err := dag.Exec(WithRunBudget(ctx, time.Minute), testState{})
+		assert.ErrorIs(t, err, context.Canceled)
+
+		errBudget := new(ErrBudgetExceeded)
+		assert.False(t, errors.As(err, &errBudget))
+	})
+
+	t.Run("WithinBudget", func(t *testing.T) {
+		dag, err := New(Named("validate", NewStep(noopStep)))
+		assert.NoError(t, err)
+
+		assert.NoError(t, dag.Exec(WithRunBudget(context.TODO(), time.Minute), testState{}))
+	})
+}
@@ -0,0 +1,89 @@
+package dagger
+
+// Builder composes a DAG step by step, as an alternative to nesting the
+// constructors, which gets hard to read for long DAG(s). It is created
+// by Build, and its methods return the Builder for chaining:
+//
+//	dag, err := Build[*VM]().
+//		Step(createVM).
+//		Then(attachDisk).
+//		If(needsIP, assignIP).
+//		OnFailure(cleanup).
+//		Parallel(notifyUser, updateInventory).
+//		Done()
+//
+// The Step(s) added to a Builder are executed in series.
+type Builder[S any] struct {
+	steps []Step[S]
+	err   error
+}
+
+// Build creates an empty Builder.
+func Build[S any]() *Builder[S] { return &Builder[S]{} }
+
+// Step adds the given Step(s), in order.
+func (b *Builder[S]) Step(steps ...Step[S]) *Builder[S] {
+	b.steps = append(b.steps, steps...)
+	return b
+}
+
+// Then is the same as Step, it reads better after the first Step.
+func (b *Builder[S]) Then(steps ...Step[S]) *Builder[S] { return b.Step(steps...) }
+
+// If adds a Step executing thenStep if condition returns true, see If.
+func (b *Builder[S]) If(condition Selector[S], thenStep Step[S]) *Builder[S] {
+	return b.Step(If(condition, thenStep))
+}
+
+// IfElse adds a Step executing thenStep if condition returns true,
+// and elseStep otherwise, see IfElse.
+func (b *Builder[S]) IfElse(condition Selector[S], thenStep, elseStep Step[S]) *Builder[S] {
+	return b.Step(IfElse(condition, thenStep, elseStep))
+}
+
+// Parallel adds a Step executing the given Step(s) concurrently, see Parallel.
+func (b *Builder[S]) Parallel(steps ...Step[S]) *Builder[S] {
+	return b.Step(Parallel(steps...))
+}
+
+// OnFailure handles the failure of the last added Step with failureHandler,
+// see OnFailure. If no Step was added yet, Build returns an ErrNoPreviousStep.
+func (b *Builder[S]) OnFailure(failureHandler StepErrorHandler[S], opts ...ResultOption) *Builder[S] {
+	if len(b.steps) == 0 {
+		if b.err == nil {
+			b.err = &ErrNoPreviousStep{method: "OnFailure"}
+		}
+
+		return b
+	}
+
+	last := len(b.steps) - 1
+	b.steps[last] = OnFailure(b.steps[last], failureHandler, opts...)
+
+	return b
+}
+
+// Build returns the composed Step, without validating it. It returns an
+// ErrInvalid if the Builder was misused, e.g. see OnFailure.
+func (b *Builder[S]) Build() (Step[S], error) {
+	if b.err != nil {
+		return nil, &ErrInvalid{err: b.err}
+	}
+
+	if len(b.steps) == 1 {
+		return b.steps[0], nil
+	}
+
+	return Series(b.steps...), nil
+}
+
+// Done validates the composed Step with New, and returns the Executor
+// created with the given options.
+func (b *Builder[S]) Done(opts ...ExecutorOption[S]) (*Executor[S], error) {
+	step, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return New(step, opts...)
+}
@@ -0,0 +1,71 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuild(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		executed []string
+	)
+
+	step := func(name string, err error) Step[testState] {
+		return NewStep(func(ctx context.Context, _ testState) error {
+			mu.Lock()
+			executed = append(executed, name)
+			mu.Unlock()
+
+			return err
+		})
+	}
+
+	errAttach := errors.New("attach failed")
+
+	dag, err := Build[testState]().
+		Step(step("create", nil)).
+		Then(step("attach", errAttach)).
+		OnFailure(func(ctx context.Context, _ testState, err error) Step[testState] {
+			assert.ErrorIs(t, err, errAttach)
+			return step("detach", nil)
+		}).
+		If(alwaysFalse, step("assign-ip", nil)).
+		IfElse(alwaysTrue, step("start", nil), step("stop", nil)).
+		Parallel(step("notify", nil), step("inventory", nil)).
+		Done()
+	assert.NoError(t, err)
+
+	assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+	assert.Equal(t, []string{"create", "attach", "detach", "start"}, executed[:4])
+	assert.ElementsMatch(t, []string{"notify", "inventory"}, executed[4:])
+
+	t.Run("Single", func(t *testing.T) {
+		s := step("create", nil)
+
+		built, err := Build[testState]().Step(s).Build()
+		assert.NoError(t, err)
+		assert.Equal(t, StepName(s), StepName(built))
+	})
+
+	t.Run("OnFailureFirst", func(t *testing.T) {
+		dag, err := Build[testState]().OnFailure(nil).Step(step("create", nil)).Done()
+		assert.Nil(t, dag)
+
+		errInvalid := new(ErrInvalid)
+		assert.ErrorAs(t, err, &errInvalid)
+
+		errNoPreviousStep := new(ErrNoPreviousStep)
+		assert.ErrorAs(t, err, &errNoPreviousStep)
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		dag, err := Build[testState]().Step(step("create", nil)).Done(WithName[testState]("provision"))
+		assert.NoError(t, err)
+		assert.Equal(t, "provision", dag.Stats().Name)
+	})
+}
@@ -0,0 +1,66 @@
+package dagger
+
+import (
+	"context"
+	"sync"
+)
+
+// Bulkhead returns a middleware which limits the number of concurrent
+// executions of Step(s) to limit per key, across all the executions of
+// the Executor(s) it is added to, e.g. to allow at most 3 database
+// migrations at a time however many DAG(s) are running:
+//
+//	dag.Use(Bulkhead[S](ByLabel("resource"), 3))
+//
+// The key of each Step is decided once, by calling key with its Info, Step(s)
+// with an empty key are not limited. Step(s) waiting for their turn return
+// ctx.Err() if ctx is done first. A Step must not share its key with the
+// Step(s) nested within it, they would wait on each other.
+func Bulkhead[S any](key func(info Info) string, limit int) MiddlewareFunc[S] {
+	var (
+		mu   sync.Mutex
+		sems = make(map[string]chan struct{})
+	)
+
+	return func(next Step[S], info Info) Step[S] {
+		k := key(info)
+		if k == "" || limit <= 0 {
+			return next
+		}
+
+		mu.Lock()
+		sem, ok := sems[k]
+		if !ok {
+			sem = make(chan struct{}, limit)
+			sems[k] = sem
+		}
+		mu.Unlock()
+
+		return StepFunc[S](func(ctx context.Context, state S) error {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			defer func() { <-sem }()
+
+			return next.Exec(ctx, state)
+		})
+	}
+}
+
+// ByName is a key for Bulkhead, which limits each leaf Step by its name.
+func ByName(info Info) string {
+	if info.CanSkip {
+		return ""
+	}
+
+	return info.Name.String()
+}
+
+// ByLabel returns a key for Bulkhead, which limits the Step(s)
+// by the value of their label key, if they have it.
+func ByLabel(key string) func(info Info) string {
+	return func(info Info) string { return info.Labels[key] }
+}
@@ -0,0 +1,79 @@
+package dagger
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkhead(t *testing.T) {
+	var running, maxRunning atomic.Int32
+
+	migrate := NewStep(func(ctx context.Context, _ testState) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+
+		for m := maxRunning.Load(); n > m && !maxRunning.CompareAndSwap(m, n); m = maxRunning.Load() {
+		}
+
+		time.Sleep(5 * time.Millisecond)
+
+		return nil
+	})
+
+	bulkhead := Bulkhead[testState](ByLabel("resource"), 2)
+
+	newDAG := func() *Executor[testState] {
+		dag, err := New(Parallel(
+			WithLabels[testState](migrate, map[string]string{"resource": "db"}),
+			WithLabels[testState](migrate, map[string]string{"resource": "db"}),
+			NewStep(noopStep),
+		))
+		assert.NoError(t, err)
+		assert.NoError(t, dag.Use(bulkhead))
+
+		return dag
+	}
+
+	var wg sync.WaitGroup
+	for _, dag := range []*Executor[testState]{newDAG(), newDAG(), newDAG()} {
+		wg.Add(1)
+
+		go func(dag *Executor[testState]) {
+			defer wg.Done()
+			assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+		}(dag)
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int32(2), maxRunning.Load())
+
+	t.Run("Canceled", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+
+		dag, err := New(NewStep(func(ctx context.Context, _ testState) error {
+			close(started)
+			<-release
+
+			return nil
+		}))
+		assert.NoError(t, err)
+		assert.NoError(t, dag.Use(Bulkhead[testState](ByName, 1)))
+
+		go func() { _ = dag.Exec(context.TODO(), testState{}) }()
+
+		<-started
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+
+		assert.ErrorIs(t, dag.Exec(ctx, testState{}), context.Canceled)
+		close(release)
+	})
+}
@@ -0,0 +1,132 @@
+package dagger
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CatalogEntry describes an Executor registered with a Catalog.
+type CatalogEntry struct {
+	Name        string
+	Version     string
+	Fingerprint string
+}
+
+// Catalog is a registry of Executor(s) by name and version, e.g. to route
+// executions to a specific version of a workflow, or to list the workflows
+// of a service. It is safe for concurrent use.
+type Catalog struct {
+	mu      sync.RWMutex
+	entries map[catalogKey]catalogEntry
+	latest  map[string]string
+}
+
+type catalogKey struct{ name, version string }
+
+type catalogEntry struct {
+	named    NamedExecutor
+	executor any
+}
+
+// NewCatalog creates an empty Catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{entries: make(map[catalogKey]catalogEntry), latest: make(map[string]string)}
+}
+
+// Register registers the Executor with the Catalog under the name and version,
+// it becomes the latest version of the name. It returns ErrRegistered if
+// an Executor is already registered under the same name and version.
+func Register[S any](c *Catalog, name, version string, e *Executor[S]) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := catalogKey{name: name, version: version}
+	if _, ok := c.entries[key]; ok {
+		return &ErrRegistered{name: name, version: version}
+	}
+
+	c.entries[key] = catalogEntry{named: NewNamedExecutor(name+"@"+version, e), executor: e}
+	c.latest[name] = version
+
+	return nil
+}
+
+// Lookup returns the Executor registered under the name and version, the
+// latest registered version is returned if version is empty. It returns
+// false if there is no such Executor, or its state is not of type S.
+func Lookup[S any](c *Catalog, name, version string) (*Executor[S], bool) {
+	entry, ok := c.entry(name, version)
+	if !ok {
+		return nil, false
+	}
+
+	e, ok := entry.executor.(*Executor[S])
+
+	return e, ok
+}
+
+func (c *Catalog) entry(name, version string) (catalogEntry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if version == "" {
+		version = c.latest[name]
+	}
+
+	entry, ok := c.entries[catalogKey{name: name, version: version}]
+
+	return entry, ok
+}
+
+// List returns the registered Executor(s), sorted by name and version.
+func (c *Catalog) List() []CatalogEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	list := make([]CatalogEntry, 0, len(c.entries))
+	for key, entry := range c.entries {
+		list = append(list, CatalogEntry{Name: key.name, Version: key.version, Fingerprint: entry.named.fingerprint()})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Name != list[j].Name {
+			return list[i].Name < list[j].Name
+		}
+
+		return list[i].Version < list[j].Version
+	})
+
+	return list
+}
+
+// Describe returns the structure of the DAG of the Executor registered
+// under the name and version, as rendered by DebugHandler. The latest
+// registered version is described if version is empty.
+func (c *Catalog) Describe(name, version string) (string, bool) {
+	entry, ok := c.entry(name, version)
+	if !ok {
+		return "", false
+	}
+
+	var sb strings.Builder
+	entry.named.writeStructure(&sb)
+
+	return sb.String(), true
+}
+
+// Executors returns the registered Executor(s), sorted by name and version,
+// and named `name@version`, to be passed to DebugHandler.
+func (c *Catalog) Executors() []NamedExecutor {
+	list := c.List()
+	executors := make([]NamedExecutor, 0, len(list))
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, e := range list {
+		executors = append(executors, c.entries[catalogKey{name: e.Name, version: e.Version}].named)
+	}
+
+	return executors
+}
@@ -0,0 +1,64 @@
+package dagger
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalog(t *testing.T) {
+	v1, err := New(Named("create", NewStep(noopStep)))
+	assert.NoError(t, err)
+
+	v2, err := New(Series(Named("create", NewStep(noopStep)), Named("publish", NewStep(noopStep))))
+	assert.NoError(t, err)
+
+	catalog := NewCatalog()
+	assert.NoError(t, Register(catalog, "provision", "v1", v1))
+	assert.NoError(t, Register(catalog, "provision", "v2", v2))
+
+	errRegistered := new(ErrRegistered)
+	assert.ErrorAs(t, Register(catalog, "provision", "v2", v1), &errRegistered)
+
+	t.Run("Lookup", func(t *testing.T) {
+		e, ok := Lookup[testState](catalog, "provision", "v1")
+		assert.True(t, ok)
+		assert.Same(t, v1, e)
+
+		e, ok = Lookup[testState](catalog, "provision", "")
+		assert.True(t, ok)
+		assert.Same(t, v2, e)
+
+		_, ok = Lookup[testState](catalog, "provision", "v3")
+		assert.False(t, ok)
+
+		_, ok = Lookup[*testState](catalog, "provision", "v1")
+		assert.False(t, ok, "state of another type")
+	})
+
+	t.Run("List", func(t *testing.T) {
+		assert.Equal(t, []CatalogEntry{
+			{Name: "provision", Version: "v1", Fingerprint: v1.Fingerprint()},
+			{Name: "provision", Version: "v2", Fingerprint: v2.Fingerprint()},
+		}, catalog.List())
+	})
+
+	t.Run("Describe", func(t *testing.T) {
+		structure, ok := catalog.Describe("provision", "")
+		assert.True(t, ok)
+		assert.Equal(t, "dagger:seriesStep[testState]\n\tcreate\n\tpublish\n", structure)
+
+		_, ok = catalog.Describe("deprovision", "")
+		assert.False(t, ok)
+	})
+
+	t.Run("DebugHandler", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		DebugHandler(catalog.Executors()...).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?name=provision@v1", nil))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "== provision@v1 ==\ncreate\n")
+	})
+}
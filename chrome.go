@@ -0,0 +1,180 @@
+package dagger
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChromeTrace records the start and end times of Step(s) across executions,
+// and writes them in the Chrome trace event format, which can be viewed in
+// Perfetto or chrome://tracing. It is safe for concurrent use.
+type ChromeTrace[S any] struct {
+	cfg traceConfig[S]
+
+	mu     sync.Mutex
+	events []traceEvent
+}
+
+type traceEvent struct {
+	name        string
+	description string
+	path        string
+	err         error
+	start       time.Time
+	end         time.Time
+	attrs       map[string]string
+}
+
+// NewChromeTrace creates an empty ChromeTrace, the attributes added
+// by WithAttrsFromState are written to the args of the events.
+func NewChromeTrace[S any](opts ...TraceOption[S]) *ChromeTrace[S] {
+	return &ChromeTrace[S]{cfg: newTraceConfig(opts)}
+}
+
+// Middleware returns the middleware which records the Step(s),
+// it must be added to the Executor with Use.
+func (c *ChromeTrace[S]) Middleware() MiddlewareFunc[S] {
+	return func(next Step[S], info Info) Step[S] {
+		name := info.Name.String()
+
+		return StepFunc[S](func(ctx context.Context, state S) error {
+			clock := ClockFromContext(ctx)
+			start := clock.Now()
+			err := next.Exec(ctx, state)
+			end := clock.Now()
+
+			var attrs map[string]string
+			if c.cfg.attrs != nil {
+				attrs = c.cfg.attrs(state)
+			}
+
+			c.mu.Lock()
+			c.events = append(c.events, traceEvent{
+				name: name, description: info.Description, path: info.Path, err: err, start: start, end: end,
+				attrs: attrs,
+			})
+			c.mu.Unlock()
+
+			return err
+		})
+	}
+}
+
+// Reset clears the recorded Step(s).
+func (c *ChromeTrace[S]) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.events = nil
+}
+
+type chromeEvent struct {
+	Name string            `json:"name"`
+	Cat  string            `json:"cat"`
+	Ph   string            `json:"ph"`
+	Ts   int64             `json:"ts"`
+	Dur  int64             `json:"dur"`
+	Pid  int               `json:"pid"`
+	Tid  int               `json:"tid"`
+	Args map[string]string `json:"args"`
+}
+
+// WriteTo writes the recorded Step(s) as a JSON object in the Chrome trace event
+// format. Step(s) executing concurrently, e.g. within Parallel, are placed on
+// separate lanes, while nested Step(s) share the lane of their parent.
+func (c *ChromeTrace[S]) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	events := append([]traceEvent(nil), c.events...)
+	c.mu.Unlock()
+
+	// parents start before, and end after their children,
+	// sort them first so that children can be nested in them.
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].start.Equal(events[j].start) {
+			return events[i].start.Before(events[j].start)
+		}
+
+		return events[i].end.After(events[j].end)
+	})
+
+	var (
+		origin time.Time
+		lanes  [][]traceEvent // stack of open events per lane
+	)
+
+	if len(events) > 0 {
+		origin = events[0].start
+	}
+
+	out := struct {
+		TraceEvents []chromeEvent `json:"traceEvents"`
+	}{TraceEvents: make([]chromeEvent, 0, len(events))}
+
+	for _, e := range events {
+		lane := -1
+
+		for i, stack := range lanes {
+			for len(stack) > 0 && !stack[len(stack)-1].end.After(e.start) {
+				stack = stack[:len(stack)-1]
+			}
+
+			lanes[i] = stack
+
+			if lane == -1 && (len(stack) == 0 || contains(stack[len(stack)-1], e)) {
+				lane = i
+			}
+		}
+
+		if lane == -1 {
+			lanes = append(lanes, nil)
+			lane = len(lanes) - 1
+		}
+
+		lanes[lane] = append(lanes[lane], e)
+
+		args := make(map[string]string, len(e.attrs)+3)
+		for k, v := range e.attrs {
+			args[k] = v
+		}
+
+		args["path"] = e.path
+
+		if e.err != nil {
+			args["error"] = e.err.Error()
+		}
+
+		if e.description != "" {
+			args["description"] = e.description
+		}
+
+		out.TraceEvents = append(out.TraceEvents, chromeEvent{
+			Name: e.name,
+			Cat:  "dagger",
+			Ph:   "X",
+			Ts:   e.start.Sub(origin).Microseconds(),
+			Dur:  e.end.Sub(e.start).Microseconds(),
+			Pid:  1,
+			Tid:  lane + 1,
+			Args: args,
+		})
+	}
+
+	b, err := json.Marshal(out)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(b)
+
+	return int64(n), err
+}
+
+// contains reports whether the child event is nested within the parent event.
+func contains(parent, child traceEvent) bool {
+	return !parent.end.Before(child.end) && strings.HasPrefix(child.path, parent.path+"/")
+}
@@ -0,0 +1,93 @@
+package dagger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChromeTrace(t *testing.T) {
+	aStarted, bStarted := make(chan struct{}), make(chan struct{})
+
+	dag, err := New(Series(
+		Named("validate", NewStep(noopStep)),
+		Named("create", Parallel(
+			Named("disk", NewStep(func(ctx context.Context, state testState) error {
+				close(aStarted)
+				<-bStarted
+				return nil
+			})),
+			Named("nic", NewStep(func(ctx context.Context, state testState) error {
+				close(bStarted)
+				<-aStarted
+				return testErrStep
+			})),
+		)),
+	))
+	assert.NoError(t, err)
+
+	ct := NewChromeTrace[testState]()
+	assert.NoError(t, dag.Use(ct.Middleware()))
+	assert.Error(t, dag.Exec(context.TODO(), testState{}))
+
+	buf := new(bytes.Buffer)
+	_, err = ct.WriteTo(buf)
+	assert.NoError(t, err)
+
+	var out struct {
+		TraceEvents []struct {
+			Name string            `json:"name"`
+			Ph   string            `json:"ph"`
+			Tid  int               `json:"tid"`
+			Args map[string]string `json:"args"`
+		} `json:"traceEvents"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	assert.Len(t, out.TraceEvents, 5)
+
+	lanes := make(map[string]int)
+	for _, e := range out.TraceEvents {
+		assert.Equal(t, "X", e.Ph)
+		lanes[e.Name] = e.Tid
+	}
+
+	assert.Equal(t, 1, lanes["dagger:seriesStep[testState]"])
+	assert.Equal(t, 1, lanes["validate"])
+	assert.Equal(t, 1, lanes["create"])
+	assert.ElementsMatch(t, []int{1, 2}, []int{lanes["disk"], lanes["nic"]})
+	assert.Equal(t, "root", out.TraceEvents[0].Args["path"])
+
+	for _, e := range out.TraceEvents {
+		if e.Name == "nic" {
+			assert.Equal(t, "step error", e.Args["error"])
+		}
+	}
+
+	t.Run("WithAttrsFromState", func(t *testing.T) {
+		dag, err := New(Named("validate", NewStep(noopStep)))
+		assert.NoError(t, err)
+
+		ct := NewChromeTrace(WithAttrsFromState(func(testState) map[string]string {
+			return map[string]string{"tenant": "acme-corp", "path": "overridden"}
+		}))
+		assert.NoError(t, dag.Use(ct.Middleware()))
+		assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+
+		buf := new(bytes.Buffer)
+		_, err = ct.WriteTo(buf)
+		assert.NoError(t, err)
+
+		out.TraceEvents = nil
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+		assert.Equal(t, map[string]string{"tenant": "acme-corp", "path": "root"}, out.TraceEvents[0].Args)
+	})
+
+	ct.Reset()
+	buf.Reset()
+	_, err = ct.WriteTo(buf)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"traceEvents":[]}`, buf.String())
+}
@@ -0,0 +1,77 @@
+package dagger
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of time of an Executor, see WithClock.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a timer created by a Clock, like time.Timer.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// Ticker is a ticker created by a Clock, like time.Ticker.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// WithClock sets the Clock used by the time-based features of the Executor,
+// e.g. the delays of Retry, the intervals of Schedule and Watchdog, and the
+// timestamps and durations of AuditLog, ChromeTrace and EnableStepStats, by
+// default the system clock. It lets tests
+// use a fake clock, like daggertest.FakeClock, to run them deterministically.
+// Step(s) can get it with ClockFromContext.
+func WithClock[S any](c Clock) ExecutorOption[S] {
+	return func(cfg *executorConfig[S]) { cfg.clock = c }
+}
+
+// ClockFromContext returns the Clock of the Executor executing ctx,
+// or the system clock if it has none.
+func ClockFromContext(ctx context.Context) Clock {
+	if c, ok := ctx.Value(clockKey).(Clock); ok {
+		return c
+	}
+
+	return systemClock{}
+}
+
+// clock returns the Clock of the Executor.
+func (e *Executor[S]) clock() Clock {
+	if e.cfg.clock == nil {
+		return systemClock{}
+	}
+
+	return e.cfg.clock
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func (systemClock) NewTimer(d time.Duration) Timer { return systemTimer{time.NewTimer(d)} }
+
+func (systemClock) NewTicker(d time.Duration) Ticker { return systemTicker{time.NewTicker(d)} }
+
+type systemTimer struct{ t *time.Timer }
+
+func (t systemTimer) C() <-chan time.Time { return t.t.C }
+
+func (t systemTimer) Stop() bool { return t.t.Stop() }
+
+type systemTicker struct{ t *time.Ticker }
+
+func (t systemTicker) C() <-chan time.Time { return t.t.C }
+
+func (t systemTicker) Stop() { t.t.Stop() }
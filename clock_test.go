@@ -0,0 +1,34 @@
+package dagger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// frozenClock is a Clock whose time never moves.
+type frozenClock struct{ systemClock }
+
+func (frozenClock) Now() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+func TestWithClock(t *testing.T) {
+	var records []AuditRecord
+
+	dag, err := New(Named("create", NewStep(noopStep)), WithClock[testState](frozenClock{}))
+	assert.NoError(t, err)
+	assert.NoError(t, dag.Use(AuditLog[testState](func(record AuditRecord) { records = append(records, record) })))
+
+	assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+	assert.Equal(t, 1, len(records))
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), records[0].Start)
+	assert.Equal(t, time.Duration(0), records[0].Duration)
+
+	r := dag.ExecAsync(context.TODO(), testState{})
+	assert.NoError(t, r.Wait())
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), r.started)
+
+	_, ok := ClockFromContext(context.TODO()).(systemClock)
+	assert.True(t, ok)
+}
@@ -0,0 +1,89 @@
+package dagger
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+)
+
+// Codec serializes the state for StateStore(s) which persist it, see WithCodec.
+// A state holding handles that can not be serialized, like database connections,
+// can use a Codec to declare what is persisted and how the handles are rehydrated.
+type Codec[S any] interface {
+	// Marshal returns the serialized state.
+	Marshal(state S) ([]byte, error)
+	// Unmarshal returns the state serialized in data.
+	Unmarshal(data []byte) (S, error)
+}
+
+// CodecFuncs is a Codec built from a pair of functions.
+type CodecFuncs[S any] struct {
+	MarshalFunc   func(state S) ([]byte, error)
+	UnmarshalFunc func(data []byte) (S, error)
+}
+
+// Marshal implements Codec.
+func (c CodecFuncs[S]) Marshal(state S) ([]byte, error) { return c.MarshalFunc(state) }
+
+// Unmarshal implements Codec.
+func (c CodecFuncs[S]) Unmarshal(data []byte) (S, error) { return c.UnmarshalFunc(data) }
+
+// DefaultCodec returns the Codec used when none is configured. It uses
+// encoding.BinaryMarshaler and encoding.BinaryUnmarshaler if the state
+// implements them, and its JSON representation otherwise, so a state can
+// also customize it via json.Marshaler and json.Unmarshaler.
+//
+// When S is a pointer type, Unmarshal allocates the value it points to.
+func DefaultCodec[S any]() Codec[S] { return defaultCodec[S]{} }
+
+type defaultCodec[S any] struct{}
+
+func (defaultCodec[S]) Marshal(state S) ([]byte, error) {
+	if m, ok := any(state).(encoding.BinaryMarshaler); ok {
+		return m.MarshalBinary()
+	}
+
+	if m, ok := any(&state).(encoding.BinaryMarshaler); ok {
+		return m.MarshalBinary()
+	}
+
+	return json.Marshal(state)
+}
+
+func (defaultCodec[S]) Unmarshal(data []byte) (S, error) {
+	var state S
+	if t := reflect.TypeOf(state); t != nil && t.Kind() == reflect.Pointer {
+		state = reflect.New(t.Elem()).Interface().(S)
+	}
+
+	if u, ok := any(state).(encoding.BinaryUnmarshaler); ok {
+		return state, u.UnmarshalBinary(data)
+	}
+
+	if u, ok := any(&state).(encoding.BinaryUnmarshaler); ok {
+		return state, u.UnmarshalBinary(data)
+	}
+
+	return state, json.Unmarshal(data, &state)
+}
+
+// StoreOption configures a StateStore.
+type StoreOption[S any] func(*storeConfig[S])
+
+type storeConfig[S any] struct {
+	codec Codec[S]
+}
+
+// WithCodec sets the Codec used to serialize the state, by default DefaultCodec.
+func WithCodec[S any](codec Codec[S]) StoreOption[S] {
+	return func(c *storeConfig[S]) { c.codec = codec }
+}
+
+func newStoreConfig[S any](opts []StoreOption[S]) storeConfig[S] {
+	cfg := storeConfig[S]{codec: DefaultCodec[S]()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return cfg
+}
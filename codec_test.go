@@ -0,0 +1,91 @@
+package dagger
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type binaryState struct{ fields []string }
+
+func (s *binaryState) MarshalBinary() ([]byte, error) {
+	return []byte(strings.Join(s.fields, ",")), nil
+}
+
+func (s *binaryState) UnmarshalBinary(data []byte) error {
+	s.fields = strings.Split(string(data), ",")
+	return nil
+}
+
+type connState struct {
+	VMID string `json:"vm_id"`
+	conn *strings.Builder
+}
+
+func TestDefaultCodec(t *testing.T) {
+	t.Run("BinaryMarshaler", func(t *testing.T) {
+		codec := DefaultCodec[*binaryState]()
+
+		data, err := codec.Marshal(&binaryState{fields: []string{"a", "b"}})
+		assert.NoError(t, err)
+		assert.Equal(t, "a,b", string(data))
+
+		state, err := codec.Unmarshal(data)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, state.fields)
+	})
+
+	t.Run("BinaryMarshalerValue", func(t *testing.T) {
+		codec := DefaultCodec[binaryState]()
+
+		data, err := codec.Marshal(binaryState{fields: []string{"a"}})
+		assert.NoError(t, err)
+		assert.Equal(t, "a", string(data))
+
+		state, err := codec.Unmarshal(data)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a"}, state.fields)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		codec := DefaultCodec[*connState]()
+
+		data, err := codec.Marshal(&connState{VMID: "vm-1", conn: new(strings.Builder)})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"vm_id":"vm-1"}`, string(data))
+
+		state, err := codec.Unmarshal(data)
+		assert.NoError(t, err)
+		assert.Equal(t, &connState{VMID: "vm-1"}, state)
+	})
+}
+
+func TestFileStore_WithCodec(t *testing.T) {
+	conn := new(strings.Builder)
+
+	codec := CodecFuncs[*connState]{
+		MarshalFunc: func(state *connState) ([]byte, error) { return json.Marshal(state) },
+		UnmarshalFunc: func(data []byte) (*connState, error) {
+			state := &connState{conn: conn}
+			return state, json.Unmarshal(data, state)
+		},
+	}
+
+	store, err := NewFileStore(t.TempDir(), WithCodec[*connState](codec))
+	assert.NoError(t, err)
+
+	err = store.Save(context.TODO(), "run-1", Checkpoint[*connState]{
+		State:     &connState{VMID: "vm-1", conn: new(strings.Builder)},
+		Completed: []string{"0"},
+	})
+	assert.NoError(t, err)
+
+	cp, err := store.Load(context.TODO(), "run-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "vm-1", cp.State.VMID)
+	assert.Same(t, conn, cp.State.conn)
+	assert.Equal(t, []string{"0"}, cp.Completed)
+}
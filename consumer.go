@@ -0,0 +1,113 @@
+package dagger
+
+import "context"
+
+// Message is a message received by a Consumer, it is implemented by adapters
+// of messaging clients, e.g. for Kafka or SQS.
+type Message interface {
+	// Ack acknowledges the message, it is not delivered again.
+	Ack(ctx context.Context) error
+	// Nack rejects the message, so that it is delivered again.
+	Nack(ctx context.Context) error
+}
+
+// ConsumerOption configures a Consumer.
+type ConsumerOption[M Message] func(*consumerConfig[M])
+
+type consumerConfig[M Message] struct {
+	retryable  func(err error) bool
+	deadLetter func(ctx context.Context, msg M, err error) error
+}
+
+// WithRetryable sets the function deciding whether a message whose execution
+// failed with err is delivered again, by default every error is retryable.
+func WithRetryable[M Message](retryable func(err error) bool) ConsumerOption[M] {
+	return func(c *consumerConfig[M]) { c.retryable = retryable }
+}
+
+// WithDeadLetter sets the function called with the messages which failed
+// with an error which is not retryable, or could not be decoded. The message
+// is acknowledged if it returns nil, and rejected otherwise.
+//
+// Without it, the messages which failed are rejected, leaving them to the
+// redelivery and dead letter policies of the broker, while the messages which
+// could not be decoded are acknowledged and dropped, since they would never
+// be decoded when delivered again.
+func WithDeadLetter[M Message](deadLetter func(ctx context.Context, msg M, err error) error) ConsumerOption[M] {
+	return func(c *consumerConfig[M]) { c.deadLetter = deadLetter }
+}
+
+// Consumer executes the DAG for each message received, with the state decoded
+// from it. The outcome of the execution decides what happens to the message:
+//   - it is acknowledged if the execution succeeds, or is aborted
+//   - it is rejected, to be delivered again, if the error is retryable
+//   - it is handed to the dead letter function otherwise, see WithDeadLetter
+type Consumer[M Message, S any] struct {
+	e       *Executor[S]
+	receive func(ctx context.Context) (M, error)
+	decode  func(msg M) (S, error)
+	cfg     consumerConfig[M]
+}
+
+// NewConsumer creates a Consumer executing the DAG for the messages returned by
+// receive, which blocks until a message is available, with the state returned by decode.
+func NewConsumer[M Message, S any](
+	e *Executor[S],
+	receive func(ctx context.Context) (M, error),
+	decode func(msg M) (S, error),
+	opts ...ConsumerOption[M],
+) *Consumer[M, S] {
+	cfg := consumerConfig[M]{retryable: func(error) bool { return true }}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return &Consumer[M, S]{e: e, receive: receive, decode: decode, cfg: cfg}
+}
+
+// Run consumes messages, one at a time, until receive, Ack or Nack fail,
+// and returns the error, e.g. ctx.Err() once ctx is done. Run can be
+// called from multiple goroutines to consume messages concurrently.
+func (c *Consumer[M, S]) Run(ctx context.Context) error {
+	for {
+		msg, err := c.receive(ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := c.handle(ctx, msg); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Consumer[M, S]) handle(ctx context.Context, msg M) error {
+	state, err := c.decode(msg)
+	if err != nil {
+		if c.cfg.deadLetter == nil {
+			return msg.Ack(ctx)
+		}
+
+		return c.fail(ctx, msg, err)
+	}
+
+	err = c.e.Exec(ctx, state)
+
+	switch {
+	case err == nil:
+		return msg.Ack(ctx)
+	case c.cfg.retryable(err):
+		return msg.Nack(ctx)
+	default:
+		return c.fail(ctx, msg, err)
+	}
+}
+
+// fail hands a message which can not be processed to the dead letter function.
+func (c *Consumer[M, S]) fail(ctx context.Context, msg M, err error) error {
+	if c.cfg.deadLetter == nil || c.cfg.deadLetter(ctx, msg, err) != nil {
+		return msg.Nack(ctx)
+	}
+
+	return msg.Ack(ctx)
+}
@@ -0,0 +1,105 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testMessage struct {
+	body    string
+	outcome *string
+}
+
+func (m testMessage) Ack(context.Context) error {
+	*m.outcome = "ack"
+	return nil
+}
+
+func (m testMessage) Nack(context.Context) error {
+	*m.outcome = "nack"
+	return nil
+}
+
+func TestConsumer(t *testing.T) {
+	errFatal := errors.New("fatal")
+	errRetry := errors.New("retry")
+
+	dag, err := New(NewStep(func(ctx context.Context, disk int) error {
+		switch disk {
+		case 1:
+			return errRetry
+		case 2:
+			return errFatal
+		case 3:
+			return Abort("nothing to do")
+		}
+
+		return nil
+	}))
+	assert.NoError(t, err)
+
+	testcases := []struct {
+		name        string
+		body        string
+		deadLetter  bool
+		want        string
+		wantDeadErr error
+	}{
+		{name: "Success", body: "0", want: "ack"},
+		{name: "Abort", body: "3", want: "ack"},
+		{name: "Retryable", body: "1", deadLetter: true, want: "nack"},
+		{name: "Fatal", body: "2", deadLetter: true, want: "ack", wantDeadErr: errFatal},
+		{name: "FatalWithoutDeadLetter", body: "2", want: "nack"},
+		{name: "DecodeError", body: "x", deadLetter: true, want: "ack", wantDeadErr: strconv.ErrSyntax},
+		{name: "DecodeErrorWithoutDeadLetter", body: "x", want: "ack"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var outcome string
+			var deadErr error
+
+			msgs := make(chan testMessage, 1)
+			msgs <- testMessage{body: tc.body, outcome: &outcome}
+			close(msgs)
+
+			errDone := errors.New("no more messages")
+			opts := []ConsumerOption[testMessage]{
+				WithRetryable[testMessage](func(err error) bool { return !errors.Is(err, errFatal) }),
+			}
+
+			if tc.deadLetter {
+				opts = append(opts, WithDeadLetter(func(ctx context.Context, msg testMessage, err error) error {
+					deadErr = err
+					return nil
+				}))
+			}
+
+			consumer := NewConsumer(dag,
+				func(ctx context.Context) (testMessage, error) {
+					msg, ok := <-msgs
+					if !ok {
+						return msg, errDone
+					}
+
+					return msg, nil
+				},
+				func(msg testMessage) (int, error) { return strconv.Atoi(msg.body) },
+				opts...,
+			)
+
+			assert.ErrorIs(t, consumer.Run(context.TODO()), errDone)
+			assert.Equal(t, tc.want, outcome)
+
+			if tc.wantDeadErr != nil {
+				assert.ErrorIs(t, deadErr, tc.wantDeadErr)
+			} else {
+				assert.NoError(t, deadErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package dagger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Branch is a branch of a conditional Step.
+type Branch struct {
+	// Step is the name of the conditional Step.
+	Step fmt.Stringer
+	// Path identifies the conditional Step in the DAG.
+	Path string
+	// Name is the name of the branch, one of
+	//   - then, else or skip for If, IfNot and IfElse
+	//   - success or failure for Result
+	//   - the edge of each case for Switch, e.g. case=gcp or default,
+	//     and none if it has no Default case
+	Name string
+}
+
+func (b Branch) String() string { return fmt.Sprintf("%s at %s: %s", b.Step, b.Path, b.Name) }
+
+type coverageBranch struct {
+	Branch
+	// target is the path of the Step executed when the branch is taken.
+	// If empty, the branch is taken whenever the conditional Step
+	// executes without executing any of the Step(s) at alternatives.
+	target       string
+	alternatives []string
+}
+
+// caseStep is implemented by the Switch Step(s), whatever the type of their key.
+type caseStep interface {
+	branchStep
+	// caseEdges returns the edges of the cases, in order.
+	caseEdges() []string
+}
+
+var _ caseStep = (*switchStep[any, int])(nil)
+
+func (s *switchStep[S, K]) caseEdges() []string {
+	edges := make([]string, len(s.cases))
+	for i, c := range s.cases {
+		edges[i] = c.label
+	}
+
+	return edges
+}
+
+// Coverage collects the branches of conditional Step(s) exercised across
+// executions of a DAG, which helps making sure all branches, e.g. rollbacks,
+// are tested. It is safe for concurrent use.
+type Coverage[S any] struct {
+	branches []coverageBranch
+
+	mu   sync.Mutex
+	hits map[string]int
+}
+
+// NewCoverage creates a Coverage for the branches of the given Step, which must be
+// the start Step of the Executor the Coverage.Middleware is added to.
+func NewCoverage[S any](step Step[S]) *Coverage[S] {
+	c := &Coverage[S]{hits: make(map[string]int)}
+
+	walk(step, rootPath, "", 0, func(step Step[S], info Info, _ int) bool {
+		branch := func(name string) coverageBranch {
+			return coverageBranch{Branch: Branch{Step: info.Name, Path: info.Path, Name: name}}
+		}
+
+		switch s := unwrapNode(step).(type) {
+		case *ifStep[S]:
+			then, skip := branch("then"), branch("skip")
+			then.target = childPath(info.Path, "then")
+			skip.alternatives = []string{then.target}
+			c.branches = append(c.branches, then, skip)
+		case *ifElseStep[S]:
+			then, els := branch("then"), branch("else")
+			then.target = childPath(info.Path, "then")
+			els.target = childPath(info.Path, "else")
+			c.branches = append(c.branches, then, els)
+		case *resultStep[S]:
+			success, failure := branch("success"), branch("failure")
+			success.target = childPath(info.Path, "success")
+			failure.target = childPath(info.Path, "failure")
+
+			// OnFailure and OnSuccess only execute a Step for one of the branches,
+			// the other branch is taken whenever that Step is not executed.
+			if s.successStep == nil {
+				success.target, success.alternatives = "", []string{failure.target}
+			} else if s.failureHandler == nil {
+				failure.target, failure.alternatives = "", []string{success.target}
+			}
+
+			c.branches = append(c.branches, success, failure)
+		case caseStep:
+			none := branch("none")
+
+			for _, edge := range s.caseEdges() {
+				b := branch(edge)
+				b.target = childPath(info.Path, edge)
+				c.branches = append(c.branches, b)
+
+				none.alternatives = append(none.alternatives, b.target)
+			}
+
+			// Without a Default case, a Switch may execute none of its cases.
+			if !s.exhaustive() {
+				c.branches = append(c.branches, none)
+			}
+		}
+
+		return true
+	})
+
+	return c
+}
+
+// Middleware returns the middleware which records the executed branches,
+// it must be added to the Executor with Use.
+func (c *Coverage[S]) Middleware() MiddlewareFunc[S] {
+	return func(next Step[S], info Info) Step[S] {
+		return NewStep(func(ctx context.Context, state S) error {
+			c.mu.Lock()
+			c.hits[info.Path]++
+			c.mu.Unlock()
+
+			return next.Exec(ctx, state)
+		})
+	}
+}
+
+// Branches returns all the branches of the DAG.
+func (c *Coverage[S]) Branches() []Branch {
+	branches := make([]Branch, len(c.branches))
+	for i, b := range c.branches {
+		branches[i] = b.Branch
+	}
+
+	return branches
+}
+
+// Uncovered returns the branches which were never taken.
+func (c *Coverage[S]) Uncovered() []Branch {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var uncovered []Branch
+
+	for _, b := range c.branches {
+		covered := c.hits[b.target] > 0
+		if b.target == "" {
+			taken := c.hits[b.Path]
+			for _, alt := range b.alternatives {
+				taken -= c.hits[alt]
+			}
+
+			covered = taken > 0
+		}
+
+		if !covered {
+			uncovered = append(uncovered, b.Branch)
+		}
+	}
+
+	return uncovered
+}
+
+// Report writes the uncovered branches to w, one per line.
+func (c *Coverage[S]) Report(w io.Writer) error {
+	uncovered := c.Uncovered()
+
+	if _, err := fmt.Fprintf(w, "%d/%d branches covered\n", len(c.branches)-len(uncovered), len(c.branches)); err != nil {
+		return err
+	}
+
+	for _, b := range uncovered {
+		if _, err := fmt.Fprintf(w, "uncovered: %s\n", b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package dagger
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type coverageState struct {
+	quota bool
+	fail  bool
+}
+
+func TestCoverage(t *testing.T) {
+	hasQuota := NewSelector("has-quota", func(state coverageState) bool { return state.quota })
+	noop := func(context.Context, coverageState) error { return nil }
+
+	step := Series(
+		If(hasQuota, NewStep(noop)),
+		Named("provision", IfElse(hasQuota, NewStep(noop), NewStep(noop))),
+		Result(
+			NewStep(func(_ context.Context, state coverageState) error {
+				if state.fail {
+					return testErrStep
+				}
+
+				return nil
+			}),
+			NewStep(noop),
+			func(context.Context, coverageState, error) Step[coverageState] { return NewStep(noop) },
+		),
+	)
+
+	dag, err := New(step)
+	assert.NoError(t, err)
+
+	coverage := NewCoverage(step)
+	assert.NoError(t, dag.Use(coverage.Middleware()))
+
+	assert.Len(t, coverage.Branches(), 6)
+	assert.Len(t, coverage.Uncovered(), 6)
+
+	assert.NoError(t, dag.Exec(context.TODO(), coverageState{quota: true}))
+
+	assert.Equal(t, []Branch{
+		{Step: fmtStr("dagger:ifStep[coverageState]"), Path: "root/0", Name: "skip"},
+		{Step: fmtStr("provision"), Path: "root/1", Name: "else"},
+		{Step: fmtStr("dagger:resultStep[coverageState]"), Path: "root/2", Name: "failure"},
+	}, stringified(coverage.Uncovered()))
+
+	assert.NoError(t, dag.Exec(context.TODO(), coverageState{fail: true}))
+	assert.Empty(t, coverage.Uncovered())
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, coverage.Report(buf))
+	assert.Equal(t, "6/6 branches covered\n", buf.String())
+}
+
+func TestCoverage_OnFailure(t *testing.T) {
+	step := OnFailure(
+		NewStep(func(_ context.Context, state coverageState) error {
+			if state.fail {
+				return testErrStep
+			}
+
+			return nil
+		}),
+		func(context.Context, coverageState, error) Step[coverageState] {
+			return NewStep(func(context.Context, coverageState) error { return nil })
+		},
+	)
+
+	dag, err := New(step)
+	assert.NoError(t, err)
+
+	coverage := NewCoverage(step)
+	assert.NoError(t, dag.Use(coverage.Middleware()))
+
+	assert.NoError(t, dag.Exec(context.TODO(), coverageState{}))
+	assert.Equal(t, []Branch{
+		{Step: fmtStr("dagger:resultStep[coverageState]"), Path: "root", Name: "failure"},
+	}, stringified(coverage.Uncovered()))
+
+	assert.NoError(t, dag.Exec(context.TODO(), coverageState{fail: true}))
+	assert.Empty(t, coverage.Uncovered())
+}
+
+func TestCoverage_Switch(t *testing.T) {
+	step := Switch(cloudOf,
+		Case(gcp, NewStep(func(context.Context, cloudState) error { return nil })),
+		Case(aws, NewStep(func(context.Context, cloudState) error { return nil })),
+	)
+
+	dag, err := New(step)
+	assert.NoError(t, err)
+
+	coverage := NewCoverage(step)
+	assert.NoError(t, dag.Use(coverage.Middleware()))
+
+	assert.NoError(t, dag.Exec(context.TODO(), cloudState{cloud: gcp}))
+	assert.Equal(t, []Branch{
+		{Step: fmtStr("dagger:switchStep[cloudState,cloud]"), Path: "root", Name: "case=aws"},
+		{Step: fmtStr("dagger:switchStep[cloudState,cloud]"), Path: "root", Name: "none"},
+	}, stringified(coverage.Uncovered()))
+
+	assert.NoError(t, dag.Exec(context.TODO(), cloudState{cloud: aws}))
+	assert.NoError(t, dag.Exec(context.TODO(), cloudState{cloud: azure}))
+	assert.Empty(t, coverage.Uncovered())
+}
+
+func TestCoverage_Report(t *testing.T) {
+	step := IfElse(alwaysTrue, NewStep(noopStep), NewStep(noopStep))
+
+	dag, err := New(step)
+	assert.NoError(t, err)
+
+	coverage := NewCoverage(step)
+	assert.NoError(t, dag.Use(coverage.Middleware()))
+	assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, coverage.Report(buf))
+	assert.Equal(t, `1/2 branches covered
+uncovered: dagger:ifElseStep[testState] at root: else
+`, buf.String())
+}
+
+// stringified replaces the names of the branches with their
+// string representation, so that they can be compared.
+func stringified(branches []Branch) []Branch {
+	for i := range branches {
+		branches[i].Step = fmtStr(branches[i].Step.String())
+	}
+
+	return branches
+}
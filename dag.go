@@ -6,61 +6,491 @@ package dagger
 import (
 	"context"
 	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
 )
 
 // Executor is the main struct that holds the DAG and the middlewares.
+//
+// An Executor is safe for concurrent use. It is frozen on the first call
+// to Exec, after which no more middlewares can be added to it.
 type Executor[S any] struct {
-	start       Step[S]
+	start Step[S]
+
+	mu          sync.Mutex
+	frozen      atomic.Bool
 	middlewares MiddlewareChain[S]
+	dagMws      []DAGMiddleware[S]
+	decorators  []ContextDecorator[S]
+	// compiled is the start Step with middlewares applied
+	// to every Step in the DAG, wrapped by the DAGMiddleware(s).
+	compiled Step[S]
+
+	// instrumented is compiled with Step Info of every leaf Step
+	// added to the context, it is built on demand once frozen.
+	instrumented     Step[S]
+	instrumentedOnce sync.Once
+
+	cfg executorConfig[S]
+	// names are the names of the Step(s) disambiguated
+	// by WithUniqueNames, keyed by stepIdentity.
+	names map[any]fmt.Stringer
+	// lifecycle tracks the SetupStep(s) which are set up.
+	lifecycle *lifecycle[S]
+
+	drain  drainer
+	recent recentRuns
+	stats  executorStats
+	// stepStats reports whether EnableStepStats was called.
+	stepStats atomic.Bool
 }
 
-// New validates a Step and makes sure it does have any cycles.
-func New[S any](startStep Step[S]) (*Executor[S], error) {
+// ExecutorOption configures an Executor created by New.
+type ExecutorOption[S any] func(*executorConfig[S])
+
+type executorConfig[S any] struct {
+	name        string
+	namer       func(step Step[S]) fmt.Stringer
+	uniqueNames bool
+	nesting     NestingPolicy
+	debug       io.Writer
+	clock       Clock
+
+	recoverSelectors bool
+
+	deadlineSkip *deadlineSkip
+}
+
+// WithName names the Executor, e.g. vm-provisioning, which identifies it
+// rather than the name of its start Step in reports and traces: the DAG of
+// AuditRecord, Stats, DebugHandler, and the task created by TraceTask.
+// Step(s) can get it with DAGNameFromContext.
+func WithName[S any](name string) ExecutorOption[S] {
+	return func(c *executorConfig[S]) { c.name = name }
+}
+
+// Name returns the name of the Executor set by WithName,
+// or the name of its start Step if it has none.
+func (e *Executor[S]) Name() string {
+	if e.cfg.name != "" {
+		return e.cfg.name
+	}
+
+	return e.stepName(e.start).String()
+}
+
+// DAGNameFromContext returns the name of the Executor executing
+// ctx, if it was named with WithName.
+func DAGNameFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(dagNameKey).(string)
+	return name, ok
+}
+
+// WithNamer names every Step of the DAG with namer instead of StepName, e.g. to
+// strip module prefixes, or to map Step(s) to the IDs of a service catalog.
+// The names are used in middleware Info, errors, Replace and DebugHandler.
+//
+// The namer is called with the Step as given to New, and may fall back to
+// StepName, e.g. to keep the names of Step(s) returned by Named.
+func WithNamer[S any](namer func(step Step[S]) fmt.Stringer) ExecutorOption[S] {
+	return func(c *executorConfig[S]) { c.namer = namer }
+}
+
+// WithUniqueNames disambiguates distinct Step(s) of the DAG which have the same
+// name, typically closures created by the same function, which would otherwise
+// share their metrics, or be matched by the same scoped middlewares. The first
+// one in depth-first order keeps its name, the others get an index suffix,
+// e.g. pkg:build.func1#2. The same Step used several times keeps its name.
+//
+// Step(s) are told apart by identity, i.e. pointer Step(s) by their address,
+// and functions by their closure, closures which do not capture any
+// variables can not be told apart.
+func WithUniqueNames[S any]() ExecutorOption[S] {
+	return func(c *executorConfig[S]) { c.uniqueNames = true }
+}
+
+// RecoverSelectorPanics recovers the panics of the Selector(s) of If, IfElse
+// and their variants, and of the key func of Switch, and fails the conditional
+// Step with an ErrSelector wrapping an ErrPanic instead, which names the Selector. By default,
+// the panic crashes the execution, like the ones of any Step.
+func RecoverSelectorPanics[S any]() ExecutorOption[S] {
+	return func(c *executorConfig[S]) { c.recoverSelectors = true }
+}
+
+// New validates a Step and makes sure it does have any cycles,
+// and that no DataStep consumes data before it is produced.
+// Then it sets up the Step(s) implementing SetupStep, which
+// are torn down by Close, and returns ErrSetup if one fails.
+func New[S any](startStep Step[S], opts ...ExecutorOption[S]) (*Executor[S], error) {
 	err := checkDAGCycles(startStep)
 	if err != nil {
 		return nil, &ErrInvalid{err: err}
 	}
 
-	return &Executor[S]{
+	if err := checkDataDependencies(startStep); err != nil {
+		return nil, &ErrInvalid{err: err}
+	}
+
+	if err := checkFailureBranches(startStep); err != nil {
+		return nil, &ErrInvalid{err: err}
+	}
+
+	e := &Executor[S]{
 		start:       startStep,
 		middlewares: make(MiddlewareChain[S], 0),
-	}, nil
+		compiled:    startStep,
+		lifecycle:   &lifecycle[S]{},
+	}
+
+	for _, opt := range opts {
+		opt(&e.cfg)
+	}
+
+	if err := e.lifecycle.setup(context.Background(), e.start); err != nil {
+		return nil, err
+	}
+
+	if e.cfg.uniqueNames {
+		e.names = uniqueNames(e.start, e.cfg.namer)
+	}
+
+	if e.cfg.namer != nil || e.cfg.uniqueNames || e.cfg.debug != nil || e.cfg.deadlineSkip != nil || e.cfg.name != "" ||
+		hasWarmupSteps(e.start) {
+		e.compiled = e.build(e.middlewares)
+	}
+
+	return e, nil
+}
+
+// Use adds the given MiddlewareFunc(s) to the Executor, they are ordered
+// along with the ones added by UseOrdered, as described by OrderedMiddleware.
+// It returns ErrFrozen if the Executor has already executed.
+func (e *Executor[S]) Use(mwf ...MiddlewareFunc[S]) error {
+	mws := make([]middleware[S], len(mwf))
+	for i, m := range mwf {
+		mws[i] = m
+	}
+
+	return e.use(mws)
+}
+
+// UseOrdered adds the given Ordered middlewares to the Executor, they are
+// ordered along with the ones added by Use, as described by OrderedMiddleware.
+// It returns ErrFrozen if the Executor has already executed.
+func (e *Executor[S]) UseOrdered(mws ...Ordered[S]) error {
+	ms := make([]middleware[S], len(mws))
+	for i, m := range mws {
+		ms[i] = m
+	}
+
+	return e.use(ms)
+}
+
+func (e *Executor[S]) use(mws []middleware[S]) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.frozen.Load() {
+		return &ErrFrozen{}
+	}
+
+	e.middlewares = append(e.middlewares, mws...)
+	e.compiled = e.build(e.middlewares.sorted())
+
+	return nil
+}
+
+// DAGMiddleware wraps the whole DAG, i.e. every call to Exec, as opposed to
+// the MiddlewareFunc(s) which wrap each Step. It is meant for concerns of
+// the execution as a whole, like a top level tracing span, or a timeout.
+type DAGMiddleware[S any] func(next Step[S]) Step[S]
+
+// UseDAG adds the given DAGMiddleware(s) to the Executor, the first
+// one is the outermost. DAGMiddleware(s) wrap the MiddlewareFunc(s)
+// added with Use, regardless of the order of the calls.
+// It returns ErrFrozen if the Executor has already executed.
+func (e *Executor[S]) UseDAG(mws ...DAGMiddleware[S]) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.frozen.Load() {
+		return &ErrFrozen{}
+	}
+
+	e.dagMws = append(e.dagMws, mws...)
+	e.compiled = e.build(e.middlewares.sorted())
+
+	return nil
+}
+
+// ContextDecorator returns the context a leaf Step is executed with, e.g.
+// with a Step scoped logger or pprof labels added to it.
+type ContextDecorator[S any] func(ctx context.Context, info Info, state S) context.Context
+
+// UseContext adds the given ContextDecorator(s) to the Executor, they are
+// called in order before every leaf Step. All ContextDecorator(s) share a single
+// wrapper per Step, which is innermost, and cheaper than a middleware each.
+// It returns ErrFrozen if the Executor has already executed.
+func (e *Executor[S]) UseContext(decorators ...ContextDecorator[S]) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.frozen.Load() {
+		return &ErrFrozen{}
+	}
+
+	e.decorators = append(e.decorators, decorators...)
+	e.compiled = e.build(e.middlewares.sorted())
+
+	return nil
+}
+
+// build compiles the DAG with the given chain, followed by warmupOnly if a Step
+// is labeled with WarmupLabel, and the ContextDecorator(s), and preceded by the
+// middleware of WithDebugWriter, if any, and wraps it with the DAGMiddleware(s).
+func (e *Executor[S]) build(chain MiddlewareChain[S]) Step[S] {
+	if hasWarmupSteps(e.start) {
+		chain = append(chain, MiddlewareFunc[S](warmupOnly[S]))
+	}
+
+	if len(e.decorators) > 0 {
+		chain = append(chain, MiddlewareFunc[S](decorate(e.decorators)))
+	}
+
+	if e.cfg.deadlineSkip != nil {
+		chain = append(chain, MiddlewareFunc[S](skipNearDeadline[S](e.cfg.deadlineSkip)))
+	}
+
+	if e.cfg.debug != nil {
+		chain = append(MiddlewareChain[S]{MiddlewareFunc[S](debugTree[S](e.cfg.debug))}, chain...)
+	}
+
+	step := chain.compileNamed(e.start, e.namer())
+	if e.cfg.name != "" {
+		step = Named(e.cfg.name, step)
+	}
+
+	for i := len(e.dagMws) - 1; i >= 0; i-- {
+		step = e.dagMws[i](step)
+	}
+
+	return step
 }
 
-// Use adds the given MiddlewareFunc(s) to the Executor.
-func (e *Executor[S]) Use(mwf ...MiddlewareFunc[S]) {
-	for _, m := range mwf {
-		e.middlewares = append(e.middlewares, m)
+// Clone returns an independent copy of the Executor, which shares the
+// DAG but not the middlewares, nor the DAGMiddleware(s). Clone(s) are not frozen, so different
+// middlewares can be added to each of them.
+func (e *Executor[S]) Clone() *Executor[S] {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	c := &Executor[S]{
+		start:       e.start,
+		middlewares: append(make(MiddlewareChain[S], 0, len(e.middlewares)), e.middlewares...),
+		dagMws:      append([]DAGMiddleware[S](nil), e.dagMws...),
+		decorators:  append([]ContextDecorator[S](nil), e.decorators...),
+		compiled:    e.compiled,
+		cfg:         e.cfg,
+		names:       e.names,
+		lifecycle:   e.lifecycle,
 	}
+	c.stepStats.Store(e.stepStats.Load())
+
+	return c
 }
 
+// WithAdditionalMiddleware returns a Clone of the Executor
+// with the given MiddlewareFunc(s) added to it.
+func (e *Executor[S]) WithAdditionalMiddleware(mwf ...MiddlewareFunc[S]) *Executor[S] {
+	c := e.Clone()
+	_ = c.Use(mwf...) // a Clone is never frozen
+
+	return c
+}
+
+// Exec executes the DAG with the given state.
+// It returns ErrShutdown if the Executor is shut down,
+// and nil if a Step aborts the DAG with ErrAbort.
 func (e *Executor[S]) Exec(ctx context.Context, state S) error {
-	s := e.middlewares.apply(e.start, stepInfo(e.start))
+	err := ignoreSkip(e.exec(e.withConfig(e.nest(ctx)), state))
+	if _, ok := asAbort(err); ok {
+		err = nil
+	}
+
+	e.stats.record(err)
+
+	return err
+}
+
+// withConfig adds the options of the Executor read by Step(s) to ctx,
+// see WithClock, RecoverSelectorPanics and WithName, and its stats
+// if they are recorded by EnableStepStats.
+func (e *Executor[S]) withConfig(ctx context.Context) context.Context {
+	if e.stepStats.Load() {
+		ctx = context.WithValue(ctx, statsKey, &e.stats)
+	}
+
+	if e.cfg.clock != nil {
+		ctx = context.WithValue(ctx, clockKey, e.cfg.clock)
+	}
+
+	if e.cfg.recoverSelectors {
+		ctx = context.WithValue(ctx, recoverSelectorsKey, true)
+	}
+
+	if e.cfg.name != "" {
+		ctx = context.WithValue(ctx, dagNameKey, e.cfg.name)
+	}
 
-	return s.Exec(withMiddlewares(ctx, e.middlewares), state)
+	return ctx
+}
+
+func (e *Executor[S]) exec(ctx context.Context, state S) error {
+	if !e.drain.acquire() {
+		return &ErrShutdown{}
+	}
+	defer e.drain.release()
+
+	step := e.freeze()
+
+	if needsInstrumentation(ctx) {
+		step = e.instrument()
+	}
+
+	return withBudget(ctx, step, state)
+}
+
+// needsInstrumentation reports whether an execution
+// with ctx needs the instrumented DAG.
+func needsInstrumentation(ctx context.Context) bool {
+	return ResultsFromContext(ctx) != nil || runFromContext(ctx) != nil || ctx.Value(runBudgetKey) != nil ||
+		ctx.Value(checkpointKey) != nil
+}
+
+// freeze marks the Executor as frozen and returns the compiled DAG.
+func (e *Executor[S]) freeze() Step[S] {
+	if !e.frozen.Load() {
+		e.mu.Lock()
+		e.frozen.Store(true)
+		e.mu.Unlock()
+	}
+
+	return e.compiled
+}
+
+// instrument returns the instrumented DAG, the Executor must be frozen.
+func (e *Executor[S]) instrument() Step[S] {
+	e.instrumentedOnce.Do(func() {
+		chain := append(e.middlewares.sorted(), MiddlewareFunc[S](withLeafInfo[S]))
+		e.instrumented = e.build(chain)
+	})
+
+	return e.instrumented
 }
 
 type ctxKey int
 
 const (
-	middlewareKey ctxKey = iota
+	stepInfoKey ctxKey = iota
+	resultsKey
+	runKey
+	failureKey
+	runIDKey
+	progressKey
+	runBudgetKey
+	budgetTrackerKey
+	checkpointKey
+	pathPrefixKey
+	attemptKey
+	clockKey
+	recoverSelectorsKey
+	verbosityKey
+	traceContextKey
+	dagNameKey
+	runMetaKey
+	statsKey
+	warmupKey
 )
 
-func withMiddlewares[S any](ctx context.Context, chain MiddlewareChain[S]) context.Context {
-	return context.WithValue(ctx, middlewareKey, chain)
+// withLeafInfo adds the Info of leaf Step(s) to their context,
+// and reports them to the Run executing them, if any, after
+// waiting for the Run to be resumed if it is paused. A canceled
+// Run does not start any more leaf Step(s). They are also reported
+// to the run budget, if any.
+func withLeafInfo[S any](next Step[S], info Info) Step[S] {
+	if info.CanSkip {
+		return next
+	}
+
+	return StepFunc[S](func(ctx context.Context, state S) error {
+		info := &info
+		if prefix, ok := ctx.Value(pathPrefixKey).(string); ok {
+			scoped := *info
+			scoped.Path = childPath(prefix, scoped.Path)
+			info = &scoped
+		}
+
+		if r := runFromContext(ctx); r != nil {
+			if err := r.waitResumed(ctx); err != nil {
+				return err
+			}
+
+			if err := context.Cause(ctx); err != nil {
+				return err
+			}
+
+			r.current.Store(info)
+		}
+
+		if t := budgetTrackerFromContext(ctx); t != nil {
+			t.current.Store(info)
+		}
+
+		c := checkpointFromContext[S](ctx)
+		if c == nil {
+			return next.Exec(context.WithValue(ctx, stepInfoKey, *info), state)
+		}
+
+		if c.isCompleted(info.Path) {
+			return &ErrSkip{}
+		}
+
+		err := next.Exec(context.WithValue(ctx, stepInfoKey, *info), state)
+		if ignoreSkip(err) != nil {
+			return err
+		}
+
+		if saveErr := c.complete(ctx, info.Path); saveErr != nil {
+			return saveErr
+		}
+
+		return err
+	})
 }
 
-// execWithContext runs the given stage with MiddlewareChain in context.
-// Meta Step(s) must use this function to call Step.Exec.
-func execWithContext[S any](ctx context.Context, step Step[S], state S) error {
-	s := step
+// decorate returns a middleware applying the ContextDecorator(s) to leaf Step(s).
+func decorate[S any](decorators []ContextDecorator[S]) MiddlewareFunc[S] {
+	return func(next Step[S], info Info) Step[S] {
+		if info.CanSkip {
+			return next
+		}
+
+		return StepFunc[S](func(ctx context.Context, state S) error {
+			for _, d := range decorators {
+				ctx = d(ctx, info, state)
+			}
 
-	c, ok := ctx.Value(middlewareKey).(MiddlewareChain[S])
-	if ok {
-		s = c.apply(step, stepInfo(s))
+			return next.Exec(ctx, state)
+		})
 	}
+}
 
-	return s.Exec(ctx, state)
+// stepInfoFromContext returns the Info of the leaf Step being executed, if any.
+func stepInfoFromContext(ctx context.Context) (Info, bool) {
+	info, ok := ctx.Value(stepInfoKey).(Info)
+	return info, ok
 }
 
 // checkDAGCycles takes a step and checks for cycles.
@@ -80,17 +510,144 @@ func checkDAGRecursive[S any](step Step[S], visited map[string]struct{}) error {
 
 	visited[ptr] = struct{}{}
 
+	for _, childStep := range children(step) {
+		if err := checkDAGRecursive(childStep, visited); err != nil {
+			return err
+		}
+	}
+
+	delete(visited, ptr)
+	return nil
+}
+
+// Walk calls visit for the given Step and every Step nested within it in
+// depth-first order, along with the Info of the Step and its depth relative
+// to the given Step. The children of a Step are skipped if visit returns false.
+//
+// Step(s) which only decorate another Step, like the ones returned by Named,
+// are visited in place of the decorated Step. The Step must not contain
+// any cycles, which is guaranteed for Step(s) accepted by New.
+func Walk[S any](step Step[S], visit func(step Step[S], info Info, depth int) bool) {
+	walk(step, rootPath, "", 0, visit)
+}
+
+// walk visits step at path, and the Step(s) nested within it, the owner
+// inherited from the Step(s) step is nested within is reported in the Info
+// unless step sets it, like wrapAt does.
+func walk[S any](step Step[S], path, owner string, depth int, visit func(step Step[S], info Info, depth int) bool) {
+	info := stepInfo(step)
+	info.Path = path
+
+	if info.Owner == "" {
+		info.Owner = owner
+	}
+
+	if !visit(step, info, depth) {
+		return
+	}
+
+	for _, e := range edges(step) {
+		walk(e.step, childPath(path, e.edge), info.Owner, depth+1, visit)
+	}
+}
+
+// namer returns the func naming the Step(s) of the Executor, see WithNamer
+// and WithUniqueNames, it is nil if they are named by StepName.
+func (e *Executor[S]) namer() func(step Step[S]) fmt.Stringer {
+	if e.names == nil {
+		return e.cfg.namer
+	}
+
+	return func(step Step[S]) fmt.Stringer {
+		if name, ok := e.names[stepIdentity(step)]; ok {
+			return name
+		}
+
+		if e.cfg.namer != nil {
+			return e.cfg.namer(step)
+		}
+
+		return StepName(step)
+	}
+}
+
+// stepName returns the name of the Step, see namer.
+func (e *Executor[S]) stepName(step Step[S]) fmt.Stringer {
+	if namer := e.namer(); namer != nil {
+		return namer(step)
+	}
+
+	return StepName(step)
+}
+
+// walk is Walk over the DAG of the Executor, with Step(s) named by namer.
+func (e *Executor[S]) walk(visit func(step Step[S], info Info, depth int) bool) {
+	namer := e.namer()
+
+	Walk(e.start, func(step Step[S], info Info, depth int) bool {
+		if namer != nil {
+			info.Name = namer(step)
+		}
+
+		return visit(step, info, depth)
+	})
+}
+
+// wrapperStep is implemented by Step(s) which only decorate another Step,
+// they are not a separate node of the DAG.
+type wrapperStep[S any] interface {
+	wrapped() Step[S]
+}
+
+type childEdge[S any] struct {
+	edge string
+	step Step[S]
+}
+
+// edges returns the child Step(s) of a node of the DAG, along with the
+// edges identifying them, which are the same as the ones used by rebuild.
+func edges[S any](step Step[S]) []childEdge[S] {
+	step = unwrapNode(step)
+
+	var es []childEdge[S]
+
+	switch step.(type) {
+	case rebuilder[S], Rebuilder[S]:
+		rebuild(step, func(e string, child Step[S]) Step[S] {
+			es = append(es, childEdge[S]{edge: e, step: child})
+			return child
+		})
+
+		return es
+	}
+
+	for i, child := range children(step) {
+		es = append(es, childEdge[S]{edge: strconv.Itoa(i), step: child})
+	}
+
+	return es
+}
+
+// unwrapNode returns the Step decorated by wrapperStep(s), if any.
+func unwrapNode[S any](step Step[S]) Step[S] {
+	for {
+		w, ok := step.(wrapperStep[S])
+		if !ok {
+			return step
+		}
+
+		step = w.wrapped()
+	}
+}
+
+// children returns the Step(s) nested within the given Step.
+func children[S any](step Step[S]) []Step[S] {
 	switch s := step.(type) {
 	case interface{ Unwrap() Step[S] }:
-		return checkDAGRecursive(s.Unwrap(), visited)
+		return []Step[S]{s.Unwrap()}
 	case interface{ Unwrap() []Step[S] }:
-		for _, childStep := range s.Unwrap() {
-			if err := checkDAGRecursive(childStep, visited); err != nil {
-				return err
-			}
-		}
+		return s.Unwrap()
 	}
 
-	delete(visited, ptr)
 	return nil
 }
@@ -3,7 +3,12 @@ package dagger
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -186,6 +191,301 @@ L2: seriesStep[useState·1] done
 L1: seriesStep[useState·1] done
 `, buf.String())
 	})
+
+	t.Run("FrozenAfterExec", func(t *testing.T) {
+		dag, err := New(NewStep(validateResource))
+		assert.NoError(t, err)
+
+		assert.NoError(t, dag.Use(testLogMiddleware[useState](io.Discard, "L1")))
+		assert.NoError(t, dag.Exec(context.TODO(), useState{}))
+
+		errFrozen := new(ErrFrozen)
+		assert.ErrorAs(t, dag.Use(testLogMiddleware[useState](io.Discard, "L2")), &errFrozen)
+		assert.Len(t, dag.middlewares, 1)
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		dag, err := New(Series(NewStep(validateResource), NewStep(createResource)))
+		assert.NoError(t, err)
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+
+			go func() {
+				defer wg.Done()
+				_ = dag.Use(testLogMiddleware[useState](io.Discard, "L"))
+			}()
+
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, dag.Exec(context.TODO(), useState{}))
+			}()
+		}
+
+		wg.Wait()
+	})
+}
+
+func TestExecutor_Clone(t *testing.T) {
+	step := Series(
+		Named("s1", NewStep(func(ctx context.Context, state testState) error { return nil })),
+		Named("s2", NewStep(func(ctx context.Context, state testState) error { return nil })),
+	)
+
+	dag, err := New(step)
+	assert.NoError(t, err)
+
+	base := new(bytes.Buffer)
+	assert.NoError(t, dag.Use(testLogMiddleware[testState](base, "base")))
+	assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+
+	base.Reset()
+	extra := new(bytes.Buffer)
+	clone := dag.WithAdditionalMiddleware(testLogMiddleware[testState](extra, "extra"))
+	assert.NoError(t, clone.Exec(context.TODO(), testState{}))
+
+	assert.Equal(t, `base: Starting step seriesStep[testState]
+base: Starting step s1
+base: s1 done
+base: Starting step s2
+base: s2 done
+base: seriesStep[testState] done
+`, base.String())
+	assert.Equal(t, `extra: Starting step seriesStep[testState]
+extra: Starting step s1
+extra: s1 done
+extra: Starting step s2
+extra: s2 done
+extra: seriesStep[testState] done
+`, extra.String())
+
+	base.Reset()
+	assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+	assert.Len(t, dag.middlewares, 1)
+	assert.NotContains(t, base.String(), "extra")
+
+	other := dag.Clone()
+	assert.NoError(t, other.Use(testLogMiddleware[testState](io.Discard, "other")))
+	assert.Len(t, other.middlewares, 2)
+	assert.Len(t, clone.middlewares, 2)
+}
+
+func TestExecutor_UseDAG(t *testing.T) {
+	var order []string
+
+	record := func(name string) DAGMiddleware[testState] {
+		return func(next Step[testState]) Step[testState] {
+			return NewStep(func(ctx context.Context, state testState) error {
+				order = append(order, "start "+name)
+				defer func() { order = append(order, "end "+name) }()
+
+				return next.Exec(ctx, state)
+			})
+		}
+	}
+
+	dag, err := New(Series(
+		Named("s1", NewStep(func(ctx context.Context, state testState) error { return nil })),
+		Named("s2", NewStep(func(ctx context.Context, state testState) error { return nil })),
+	))
+	assert.NoError(t, err)
+
+	assert.NoError(t, dag.UseDAG(record("trace"), record("timeout")))
+	assert.NoError(t, dag.Use(func(next Step[testState], info Info) Step[testState] {
+		return NewStep(func(ctx context.Context, state testState) error {
+			order = append(order, info.Name.String())
+			return next.Exec(ctx, state)
+		})
+	}))
+
+	assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+	assert.Equal(t, []string{
+		"start trace", "start timeout",
+		"dagger:seriesStep[testState]", "s1", "s2",
+		"end timeout", "end trace",
+	}, order)
+
+	order = nil
+	assert.NoError(t, dag.Exec(WithResults(context.TODO(), NewResults()), testState{}))
+	assert.Equal(t, "start trace", order[0])
+
+	errFrozen := new(ErrFrozen)
+	assert.ErrorAs(t, dag.UseDAG(record("late")), &errFrozen)
+
+	order = nil
+	assert.NoError(t, dag.Clone().Exec(context.TODO(), testState{}))
+	assert.Equal(t, "start trace", order[0])
+}
+
+type loggerKey struct{}
+
+func TestExecutor_UseContext(t *testing.T) {
+	var loggers []string
+
+	log := NewStep(func(ctx context.Context, state testState) error {
+		loggers = append(loggers, ctx.Value(loggerKey{}).(string))
+		return nil
+	})
+
+	dag, err := New(Series(Named("s1", log), Named("s2", log)))
+	assert.NoError(t, err)
+
+	assert.NoError(t, dag.UseContext(
+		func(ctx context.Context, info Info, _ testState) context.Context {
+			return context.WithValue(ctx, loggerKey{}, "logger."+info.Name.String())
+		},
+		func(ctx context.Context, info Info, _ testState) context.Context {
+			return context.WithValue(ctx, loggerKey{}, ctx.Value(loggerKey{}).(string)+".debug")
+		},
+	))
+
+	assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+	assert.Equal(t, []string{"logger.s1.debug", "logger.s2.debug"}, loggers)
+
+	errFrozen := new(ErrFrozen)
+	assert.ErrorAs(t, dag.UseContext(nil), &errFrozen)
+
+	loggers = nil
+	assert.NoError(t, dag.WithAdditionalMiddleware().Exec(WithResults(context.TODO(), NewResults()), testState{}))
+	assert.Equal(t, []string{"logger.s1.debug", "logger.s2.debug"}, loggers)
+}
+
+func TestWalk(t *testing.T) {
+	step := Series(
+		Named("validate", NewStep(noopStep)),
+		Named("group", Continue(NewStep(noopStep), NewStep(noopStep))),
+		If(alwaysTrue, NewStep(noopStep)),
+	)
+
+	t.Run("All", func(t *testing.T) {
+		var visited []string
+
+		Walk(step, func(_ Step[testState], info Info, depth int) bool {
+			visited = append(visited, strings.Repeat("-", depth)+info.Name.String())
+			return true
+		})
+
+		assert.Equal(t, []string{
+			"dagger:seriesStep[testState]",
+			"-validate",
+			"-group",
+			"--dagger:noopStep",
+			"--dagger:noopStep",
+			"-dagger:ifStep[testState]",
+			"--dagger:noopStep",
+		}, visited)
+	})
+
+	t.Run("Path", func(t *testing.T) {
+		var paths []string
+
+		Walk(step, func(_ Step[testState], info Info, _ int) bool {
+			paths = append(paths, info.Path)
+			return true
+		})
+
+		assert.Equal(t, []string{"root", "root/0", "root/1", "root/1/0", "root/1/1", "root/2", "root/2/then"}, paths)
+
+		var compiled []string
+
+		dag, err := New(step)
+		assert.NoError(t, err)
+		assert.NoError(t, dag.Use(func(next Step[testState], info Info) Step[testState] {
+			compiled = append(compiled, info.Path)
+			return next
+		}))
+
+		assert.ElementsMatch(t, paths, compiled)
+
+		wrapped := "unset"
+
+		NewChain(func(next Step[testState], info Info) Step[testState] {
+			wrapped = info.Path
+			return next
+		}).Wrap(NewStep(noopStep))
+
+		assert.Empty(t, wrapped)
+	})
+
+	t.Run("SkipChildren", func(t *testing.T) {
+		var visited []string
+
+		Walk(step, func(_ Step[testState], info Info, depth int) bool {
+			visited = append(visited, info.Name.String())
+			return !info.CanSkip || depth == 0
+		})
+
+		assert.Equal(t, []string{
+			"dagger:seriesStep[testState]",
+			"validate",
+			"group",
+			"dagger:ifStep[testState]",
+		}, visited)
+	})
+}
+
+func TestExecutor_Exec(t *testing.T) {
+	t.Run("NoMiddlewareAllocations", func(t *testing.T) {
+		noop := NewStep(func(ctx context.Context, state testState) error { return nil })
+
+		dag, err := New(Series(
+			noop,
+			If(alwaysTrue, noop),
+			IfElse(alwaysFalse, noop, noop),
+			Continue(noop, noop),
+		))
+		assert.NoError(t, err)
+
+		dag.Use()
+
+		allocs := testing.AllocsPerRun(100, func() {
+			_ = dag.Exec(context.TODO(), testState{})
+		})
+		assert.Zero(t, allocs)
+	})
+
+	t.Run("Skip", func(t *testing.T) {
+		var skipped []string
+
+		dag, err := New(Named("quota", Step[testState](NewStep(func(ctx context.Context, state testState) error {
+			return &ErrSkip{}
+		}))))
+		assert.NoError(t, err)
+
+		assert.NoError(t, dag.Use(func(next Step[testState], info Info) Step[testState] {
+			return NewStep(func(ctx context.Context, state testState) error {
+				err := next.Exec(ctx, state)
+				if Skipped(err) {
+					skipped = append(skipped, info.Name.String())
+				}
+
+				return err
+			})
+		}))
+
+		assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+		assert.Equal(t, []string{"quota"}, skipped)
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		published := false
+
+		dag, err := New(Series(
+			NewStep(func(ctx context.Context, state testState) error {
+				return fmt.Errorf("validate: %w", Abort("already provisioned"))
+			}),
+			NewStep(func(ctx context.Context, state testState) error {
+				published = true
+				return nil
+			}),
+		))
+		assert.NoError(t, err)
+
+		assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+		assert.False(t, published)
+	})
 }
 
 func Test_buildDAG(t *testing.T) {
@@ -293,3 +593,41 @@ func setDBErr(ctx context.Context, state dummyState) error {
 func updateDB(ctx context.Context, state dummyState) error {
 	return nil
 }
+
+func TestWithName(t *testing.T) {
+	var records []AuditRecord
+
+	dag, err := New(Named("create", NewStep(func(ctx context.Context, state testState) error {
+		name, ok := DAGNameFromContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, "vm-provisioning", name)
+
+		return nil
+	})), WithName[testState]("vm-provisioning"))
+	assert.NoError(t, err)
+	assert.Equal(t, "vm-provisioning", dag.Name())
+
+	var tasks []string
+	assert.NoError(t, dag.UseDAG(func(next Step[testState]) Step[testState] {
+		tasks = append(tasks, StepName(next).String())
+		return next
+	}))
+	assert.NoError(t, dag.Use(AuditLog[testState](func(record AuditRecord) { records = append(records, record) })))
+
+	assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+	assert.Equal(t, "vm-provisioning", tasks[len(tasks)-1])
+	assert.Equal(t, "vm-provisioning", records[0].DAG)
+	assert.Equal(t, "vm-provisioning", dag.Stats().Name)
+
+	rec := httptest.NewRecorder()
+	DebugHandler(dag).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/dagger?name=vm-provisioning", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "== vm-provisioning ==\ncreate\n")
+
+	unnamed, err := New(Named("create", NewStep(noopStep)))
+	assert.NoError(t, err)
+	assert.Equal(t, "create", unnamed.Name())
+
+	_, ok := DAGNameFromContext(context.TODO())
+	assert.False(t, ok)
+}
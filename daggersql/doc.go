@@ -0,0 +1,2 @@
+// Package daggersql provides Step(s) for working with database/sql in DAGs built with dagger.
+package daggersql
@@ -0,0 +1,67 @@
+package daggersql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/ajatprabha/dagger"
+)
+
+type txKey struct{}
+
+// InTx returns a Step which executes the given steps in series, like
+// dagger.Series, within a transaction. The transaction is committed if all
+// the steps succeed, and rolled back if any of them fails, or panics.
+// The steps access the transaction with TxFromContext.
+//
+// A dagger.ErrSkip or dagger.ErrAbort returned by the steps counts as success,
+// like it does for dagger.Executor.Exec: the transaction is committed, and
+// the error is returned as is, unless the commit fails.
+//
+// Nested InTx Step(s) join the transaction of the outermost one,
+// which alone commits or rolls it back.
+func InTx[S any](db *sql.DB, opts *sql.TxOptions, steps ...dagger.Step[S]) dagger.Step[S] {
+	return dagger.Named("daggersql:InTx", dagger.Scoped(dagger.Series(steps...),
+		func(ctx context.Context, _ S) (context.Context, func(err error) error, error) {
+			if _, ok := TxFromContext(ctx); ok {
+				return ctx, func(err error) error { return err }, nil
+			}
+
+			tx, err := db.BeginTx(ctx, opts)
+			if err != nil {
+				return ctx, nil, err
+			}
+
+			return context.WithValue(ctx, txKey{}, tx), func(err error) error {
+				if err != nil && !succeeded(err) {
+					// the transaction is already rolled back if ctx is done
+					if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+						return errors.Join(err, rbErr)
+					}
+
+					return err
+				}
+
+				if cErr := tx.Commit(); cErr != nil {
+					return cErr
+				}
+
+				return err
+			}, nil
+		},
+	))
+}
+
+// succeeded reports whether err is treated as success by dagger.Executor.Exec.
+func succeeded(err error) bool {
+	var abort *dagger.ErrAbort
+
+	return dagger.Skipped(err) || errors.As(err, &abort)
+}
+
+// TxFromContext returns the transaction of the InTx Step executing ctx, if any.
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return tx, ok
+}
@@ -0,0 +1,134 @@
+package daggersql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ajatprabha/dagger"
+)
+
+// fakeDriver records the outcome of the transactions of its connections.
+type fakeDriver struct {
+	mu       sync.Mutex
+	outcomes []string
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return &fakeConn{d: d}, nil }
+
+func (d *fakeDriver) record(outcome string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.outcomes = append(d.outcomes, outcome)
+}
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.d.record("begin")
+	return fakeTx{d: c.d}, nil
+}
+
+type fakeTx struct{ d *fakeDriver }
+
+func (t fakeTx) Commit() error {
+	t.d.record("commit")
+	return nil
+}
+
+func (t fakeTx) Rollback() error {
+	t.d.record("rollback")
+	return nil
+}
+
+var testDriver = &fakeDriver{}
+
+func init() { sql.Register("daggersql-fake", testDriver) }
+
+func TestInTx(t *testing.T) {
+	db, err := sql.Open("daggersql-fake", "")
+	assert.NoError(t, err)
+
+	defer db.Close()
+
+	errStep := errors.New("step failed")
+
+	usesTx := dagger.NewStep(func(ctx context.Context, _ int) error {
+		_, ok := TxFromContext(ctx)
+		assert.True(t, ok)
+
+		return nil
+	})
+
+	testcases := []struct {
+		name      string
+		step      dagger.Step[int]
+		wantErr   error
+		wantPanic bool
+		want      []string
+	}{
+		{
+			name: "Commit",
+			step: InTx(db, nil, usesTx, usesTx),
+			want: []string{"begin", "commit"},
+		},
+		{
+			name:    "Rollback",
+			step:    InTx(db, nil, usesTx, dagger.NewStep(func(context.Context, int) error { return errStep })),
+			wantErr: errStep,
+			want:    []string{"begin", "rollback"},
+		},
+		{
+			name: "Abort",
+			step: InTx(db, nil, usesTx, dagger.NewStep(func(context.Context, int) error { return dagger.Abort("up to date") })),
+			want: []string{"begin", "commit"},
+		},
+		{
+			name: "Skip",
+			step: InTx(db, nil, dagger.NewStep(func(context.Context, int) error { return &dagger.ErrSkip{} })),
+			want: []string{"begin", "commit"},
+		},
+		{
+			name:      "Panic",
+			step:      InTx(db, nil, dagger.NewStep(func(context.Context, int) error { panic("boom") })),
+			wantPanic: true,
+			want:      []string{"begin", "rollback"},
+		},
+		{
+			name: "Nested",
+			step: InTx(db, nil, usesTx, InTx(db, nil, usesTx)),
+			want: []string{"begin", "commit"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			testDriver.outcomes = nil
+
+			dag, err := dagger.New(tc.step)
+			assert.NoError(t, err)
+
+			if tc.wantPanic {
+				assert.Panics(t, func() { _ = dag.Exec(context.TODO(), 0) })
+			} else {
+				assert.ErrorIs(t, dag.Exec(context.TODO(), 0), tc.wantErr)
+			}
+
+			assert.Equal(t, tc.want, testDriver.outcomes)
+			assert.Equal(t, "daggersql:InTx", dagger.StepName(tc.step).String())
+		})
+	}
+
+	_, ok := TxFromContext(context.TODO())
+	assert.False(t, ok)
+}
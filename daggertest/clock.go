@@ -0,0 +1,137 @@
+package daggertest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ajatprabha/dagger"
+)
+
+// FakeClock is a dagger.Clock for tests, its time only moves when
+// advanced, which fires the timers and tickers due by then. It is safe for
+// concurrent use.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+var _ dagger.Clock = (*FakeClock)(nil)
+
+// NewFakeClock creates a FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock { return &FakeClock{now: now} }
+
+// Now returns the current time of the FakeClock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// After returns a channel receiving the time once the FakeClock is advanced by d.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time { return c.NewTimer(d).C() }
+
+// NewTimer creates a dagger.Timer firing once the FakeClock is advanced by d.
+func (c *FakeClock) NewTimer(d time.Duration) dagger.Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, at: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- c.now
+		return t
+	}
+
+	c.timers = append(c.timers, t)
+
+	return t
+}
+
+// NewTicker creates a dagger.Ticker ticking every d, as the FakeClock
+// is advanced. Like time.Ticker, it drops the ticks of slow receivers,
+// and it panics if d is not positive.
+func (c *FakeClock) NewTicker(d time.Duration) dagger.Ticker {
+	if d <= 0 {
+		panic("daggertest: non-positive interval for NewTicker")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTimer{clock: c, at: c.now.Add(d), period: d, c: make(chan time.Time, 1)}
+	c.timers = append(c.timers, t)
+
+	return fakeTicker{t}
+}
+
+// Advance moves the time of the FakeClock forward by d, and fires the
+// timers and tickers due by then, in order.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	for {
+		sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].at.Before(c.timers[j].at) })
+
+		if len(c.timers) == 0 || c.timers[0].at.After(c.now) {
+			return
+		}
+
+		t := c.timers[0]
+		if t.period <= 0 {
+			c.timers = c.timers[1:]
+			t.c <- t.at
+
+			continue
+		}
+
+		select {
+		case t.c <- t.at:
+		default:
+		}
+
+		t.at = t.at.Add(t.period)
+	}
+}
+
+// Timers returns the number of timers and tickers waiting to fire, e.g.
+// to wait for a Step to start waiting before advancing the FakeClock.
+func (c *FakeClock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.timers)
+}
+
+type fakeTimer struct {
+	clock  *FakeClock
+	at     time.Time
+	period time.Duration
+	c      chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	for i, timer := range t.clock.timers {
+		if timer == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+type fakeTicker struct{ t *fakeTimer }
+
+func (t fakeTicker) C() <-chan time.Time { return t.t.c }
+
+func (t fakeTicker) Stop() { t.t.Stop() }
@@ -0,0 +1,125 @@
+package daggertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ajatprabha/dagger"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	after := clock.After(time.Minute)
+	stopped := clock.NewTimer(time.Second)
+	assert.Equal(t, 2, clock.Timers())
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, start.Add(30*time.Second), clock.Now())
+	assert.Len(t, after, 0)
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, start.Add(time.Minute), <-after)
+	assert.Equal(t, 0, clock.Timers())
+
+	assert.Equal(t, start.Add(time.Minute), <-clock.After(0))
+
+	t.Run("Ticker", func(t *testing.T) {
+		clock := NewFakeClock(start)
+
+		ticker := clock.NewTicker(time.Minute)
+		assert.Equal(t, 1, clock.Timers())
+
+		clock.Advance(time.Minute)
+		assert.Equal(t, start.Add(time.Minute), <-ticker.C())
+
+		clock.Advance(3 * time.Minute)
+		assert.Equal(t, start.Add(2*time.Minute), <-ticker.C(), "the ticks of slow receivers are dropped")
+		assert.Len(t, ticker.C(), 0)
+
+		ticker.Stop()
+		assert.Equal(t, 0, clock.Timers())
+	})
+
+	t.Run("Schedule", func(t *testing.T) {
+		clock := NewFakeClock(start)
+
+		dag, err := dagger.New(NewFakeStep[testState](nil), dagger.WithClock[testState](clock))
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+
+		runs := make(chan dagger.ScheduledRun, 1)
+		done := make(chan error)
+
+		go func() {
+			done <- dag.Schedule(ctx, time.Hour, func() testState { return testState{} },
+				dagger.OnScheduledRun(func(run dagger.ScheduledRun) { runs <- run }))
+		}()
+
+		assert.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+		clock.Advance(time.Hour)
+
+		assert.Equal(t, dagger.ScheduledRun{Due: start.Add(time.Hour)}, <-runs)
+
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+	})
+
+	t.Run("Watchdog", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		heartbeats := make(chan time.Duration)
+
+		dag, err := dagger.New(dagger.Watchdog[testState](
+			dagger.NewStep(func(ctx context.Context, _ testState) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}),
+			dagger.WatchdogConfig{
+				Interval:   time.Minute,
+				Heartbeat:  func(_ context.Context, elapsed time.Duration) { heartbeats <- elapsed },
+				StallAfter: 2 * time.Minute,
+			},
+		), dagger.WithClock[testState](clock))
+		assert.NoError(t, err)
+
+		done := make(chan error)
+		go func() { done <- dag.Exec(context.TODO(), testState{}) }()
+
+		assert.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+
+		for i := 1; i <= 3; i++ {
+			clock.Advance(time.Minute)
+			assert.Equal(t, time.Duration(i)*time.Minute, <-heartbeats)
+		}
+
+		errStalled := new(dagger.ErrStalled)
+		assert.ErrorAs(t, <-done, &errStalled)
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		clock := NewFakeClock(start)
+		fake := NewFakeStep[testState](errors.New("unavailable"))
+
+		dag, err := dagger.New(dagger.Retry[testState](fake, 2, dagger.WithRetryDelay[testState](time.Hour)),
+			dagger.WithClock[testState](clock))
+		assert.NoError(t, err)
+
+		done := make(chan error)
+		go func() { done <- dag.Exec(context.TODO(), testState{}) }()
+
+		assert.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+		clock.Advance(time.Hour)
+
+		assert.NoError(t, <-done)
+		assert.Equal(t, 2, fake.Calls())
+	})
+}
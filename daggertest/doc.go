@@ -0,0 +1,2 @@
+// Package daggertest provides utilities for testing DAGs built with dagger.
+package daggertest
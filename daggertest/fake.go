@@ -0,0 +1,102 @@
+package daggertest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ajatprabha/dagger"
+)
+
+// FakeStep is a configurable Step for tests. It returns errors from a
+// sequence, counts calls, captures the states it was executed with, and
+// can block until released to test concurrency deterministically.
+// It is safe for concurrent use.
+type FakeStep[S any] struct {
+	mu       sync.Mutex
+	errs     []error
+	states   []S
+	blocking bool
+
+	started     chan struct{}
+	release     chan struct{}
+	releaseOnce sync.Once
+}
+
+var _ dagger.Step[any] = (*FakeStep[any])(nil)
+
+// NewFakeStep creates a FakeStep, which returns the given errors in sequence,
+// i.e. the n-th call returns errs[n-1], calls beyond the sequence return nil.
+func NewFakeStep[S any](errs ...error) *FakeStep[S] {
+	return &FakeStep[S]{
+		errs:    errs,
+		started: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+}
+
+// Blocking makes every call wait for Release, or ReleaseAll, before returning.
+func (f *FakeStep[S]) Blocking() *FakeStep[S] {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.blocking = true
+
+	return f
+}
+
+// Started receives a value whenever a blocking call starts.
+func (f *FakeStep[S]) Started() <-chan struct{} { return f.started }
+
+// Release unblocks a single started blocking call, it waits for one to start.
+func (f *FakeStep[S]) Release() { f.release <- struct{}{} }
+
+// ReleaseAll unblocks all current and future blocking calls.
+func (f *FakeStep[S]) ReleaseAll() { f.releaseOnce.Do(func() { close(f.release) }) }
+
+// Calls returns the number of times the FakeStep was executed.
+func (f *FakeStep[S]) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.states)
+}
+
+// States returns the states the FakeStep was executed with, in order.
+func (f *FakeStep[S]) States() []S {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]S(nil), f.states...)
+}
+
+func (f *FakeStep[S]) Exec(ctx context.Context, state S) error {
+	f.mu.Lock()
+	f.states = append(f.states, state)
+
+	var err error
+	if n := len(f.states); n <= len(f.errs) {
+		err = f.errs[n-1]
+	}
+
+	blocking := f.blocking
+	f.mu.Unlock()
+
+	if !blocking {
+		return err
+	}
+
+	select {
+	case f.started <- struct{}{}:
+	case <-f.release:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-f.release:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
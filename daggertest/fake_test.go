@@ -0,0 +1,62 @@
+package daggertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ajatprabha/dagger"
+)
+
+func TestFakeStep(t *testing.T) {
+	errFirst := errors.New("first")
+	errSecond := errors.New("second")
+
+	t.Run("ErrorSequence", func(t *testing.T) {
+		type idState struct{ id int }
+
+		fake := NewFakeStep[idState](errFirst, errSecond)
+
+		assert.ErrorIs(t, fake.Exec(context.TODO(), idState{id: 1}), errFirst)
+		assert.ErrorIs(t, fake.Exec(context.TODO(), idState{id: 2}), errSecond)
+		assert.NoError(t, fake.Exec(context.TODO(), idState{id: 3}))
+
+		assert.Equal(t, 3, fake.Calls())
+		assert.Equal(t, []idState{{id: 1}, {id: 2}, {id: 3}}, fake.States())
+	})
+
+	t.Run("Blocking", func(t *testing.T) {
+		fake := NewFakeStep[testState](errFirst).Blocking()
+		other := NewFakeStep[testState]()
+
+		done := make(chan error)
+		go func() { done <- dagger.Series[testState](fake, other).Exec(context.TODO(), testState{}) }()
+
+		<-fake.Started()
+		assert.Equal(t, 0, other.Calls())
+
+		fake.Release()
+		assert.ErrorIs(t, <-done, errFirst)
+		assert.Equal(t, 0, other.Calls())
+	})
+
+	t.Run("ReleaseAll", func(t *testing.T) {
+		fake := NewFakeStep[testState]().Blocking()
+		fake.ReleaseAll()
+		fake.ReleaseAll()
+
+		assert.NoError(t, fake.Exec(context.TODO(), testState{}))
+		assert.NoError(t, fake.Exec(context.TODO(), testState{}))
+	})
+
+	t.Run("ContextCanceled", func(t *testing.T) {
+		fake := NewFakeStep[testState]().Blocking()
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+
+		assert.ErrorIs(t, fake.Exec(ctx, testState{}), context.Canceled)
+	})
+}
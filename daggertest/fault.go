@@ -0,0 +1,123 @@
+package daggertest
+
+import (
+	"context"
+	"math/rand"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/ajatprabha/dagger"
+)
+
+// Fault is a rule of a FaultInjector.
+type Fault struct {
+	// Pattern is matched against the names of leaf Step(s),
+	// using the syntax of path.Match.
+	Pattern string
+	// Probability of injecting the fault on a matching Step,
+	// zero injects it on every execution.
+	Probability float64
+	// Delay is waited before the Step, or Err, or Panic.
+	Delay time.Duration
+	// Err is returned instead of executing the Step, if set.
+	Err error
+	// Panic is panicked with instead of executing the Step, if set.
+	Panic any
+}
+
+// FaultInjector injects errors, delays and panics into leaf Step(s) matching
+// its rules, to test how failure handling in a DAG behaves, without modifying
+// the Step(s). It is safe for concurrent use.
+type FaultInjector[S any] struct {
+	faults []Fault
+
+	mu       sync.Mutex
+	rnd      *rand.Rand
+	injected []string
+}
+
+// NewFaultInjector creates a FaultInjector with the given rules, the first
+// matching rule applies. The seed makes probabilistic injection reproducible.
+func NewFaultInjector[S any](seed int64, faults ...Fault) *FaultInjector[S] {
+	return &FaultInjector[S]{
+		faults: faults,
+		rnd:    rand.New(rand.NewSource(seed)), //nolint:gosec
+	}
+}
+
+// Middleware returns the middleware which injects the faults,
+// it must be added to the Executor with Use.
+func (f *FaultInjector[S]) Middleware() dagger.MiddlewareFunc[S] {
+	return func(next dagger.Step[S], info dagger.Info) dagger.Step[S] {
+		if info.CanSkip {
+			return next
+		}
+
+		name := info.Name.String()
+
+		fault, ok := f.match(name)
+		if !ok {
+			return next
+		}
+
+		return dagger.NewStep(func(ctx context.Context, state S) error {
+			if !f.inject(name, fault) {
+				return next.Exec(ctx, state)
+			}
+
+			if fault.Delay > 0 {
+				timer := dagger.ClockFromContext(ctx).NewTimer(fault.Delay)
+
+				select {
+				case <-timer.C():
+				case <-ctx.Done():
+					timer.Stop()
+					return ctx.Err()
+				}
+			}
+
+			if fault.Panic != nil {
+				panic(fault.Panic)
+			}
+
+			if fault.Err != nil {
+				return fault.Err
+			}
+
+			return next.Exec(ctx, state)
+		})
+	}
+}
+
+// Injected returns the names of the Step(s) faults were injected into,
+// in the order of injection.
+func (f *FaultInjector[S]) Injected() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]string(nil), f.injected...)
+}
+
+func (f *FaultInjector[S]) match(name string) (Fault, bool) {
+	for _, fault := range f.faults {
+		if ok, _ := path.Match(fault.Pattern, name); ok {
+			return fault, true
+		}
+	}
+
+	return Fault{}, false
+}
+
+func (f *FaultInjector[S]) inject(name string, fault Fault) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if fault.Probability > 0 && f.rnd.Float64() >= fault.Probability {
+		return false
+	}
+
+	f.injected = append(f.injected, name)
+
+	return true
+}
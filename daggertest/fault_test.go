@@ -0,0 +1,80 @@
+package daggertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ajatprabha/dagger"
+)
+
+func TestFaultInjector(t *testing.T) {
+	errInjected := errors.New("injected")
+
+	newDAG := func(t *testing.T, injector *FaultInjector[testState]) (*dagger.Executor[testState], *Recorder[testState]) {
+		dag, err := dagger.New(dagger.Series(
+			namedStep("validate", nil),
+			dagger.Result(
+				namedStep("create-vm", nil),
+				namedStep("publish", nil),
+				func(context.Context, testState, error) dagger.Step[testState] {
+					return namedStep("rollback", nil)
+				},
+			),
+		))
+		assert.NoError(t, err)
+
+		recorder := NewRecorder[testState]()
+		assert.NoError(t, dag.Use(injector.Middleware(), recorder.Middleware()))
+
+		return dag, recorder
+	}
+
+	t.Run("Error", func(t *testing.T) {
+		injector := NewFaultInjector[testState](1, Fault{Pattern: "create-*", Err: errInjected})
+		dag, recorder := newDAG(t, injector)
+
+		assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+		assert.Equal(t, []string{"validate", "rollback"}, recorder.Steps())
+		assert.Equal(t, []string{"create-vm"}, injector.Injected())
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		injector := NewFaultInjector[testState](1, Fault{Pattern: "publish", Panic: "boom"})
+		dag, _ := newDAG(t, injector)
+
+		assert.PanicsWithValue(t, "boom", func() { _ = dag.Exec(context.TODO(), testState{}) })
+	})
+
+	t.Run("Delay", func(t *testing.T) {
+		injector := NewFaultInjector[testState](1, Fault{Pattern: "validate", Delay: time.Hour})
+		dag, recorder := newDAG(t, injector)
+
+		ctx, cancel := context.WithTimeout(context.TODO(), time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, dag.Exec(ctx, testState{}), context.DeadlineExceeded)
+		assert.Empty(t, recorder.Steps())
+	})
+
+	t.Run("Probability", func(t *testing.T) {
+		run := func(seed int64) []string {
+			injector := NewFaultInjector[testState](seed, Fault{Pattern: "*", Probability: 0.5, Err: errInjected})
+			dag, _ := newDAG(t, injector)
+
+			for i := 0; i < 20; i++ {
+				_ = dag.Exec(context.TODO(), testState{})
+			}
+
+			return injector.Injected()
+		}
+
+		injected := run(42)
+		assert.NotEmpty(t, injected)
+		assert.Less(t, len(injected), 60)
+		assert.Equal(t, injected, run(42))
+	})
+}
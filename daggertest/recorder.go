@@ -0,0 +1,101 @@
+package daggertest
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ajatprabha/dagger"
+)
+
+// Recorder records the names of leaf Step(s) in the order they are executed.
+// It is safe for concurrent use.
+type Recorder[S any] struct {
+	mu    sync.Mutex
+	steps []string
+}
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder[S any]() *Recorder[S] { return &Recorder[S]{} }
+
+// Middleware returns the middleware which records the Step(s),
+// it must be added to the Executor with Use.
+func (r *Recorder[S]) Middleware() dagger.MiddlewareFunc[S] {
+	return func(next dagger.Step[S], info dagger.Info) dagger.Step[S] {
+		if info.CanSkip {
+			return next
+		}
+
+		name := info.Name.String()
+
+		return dagger.NewStep(func(ctx context.Context, state S) error {
+			r.mu.Lock()
+			r.steps = append(r.steps, name)
+			r.mu.Unlock()
+
+			return next.Exec(ctx, state)
+		})
+	}
+}
+
+// Steps returns the names of the recorded Step(s) in execution order.
+func (r *Recorder[S]) Steps() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.steps...)
+}
+
+// Reset clears the recorded Step(s).
+func (r *Recorder[S]) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.steps = nil
+}
+
+// AssertOrder asserts that the Step(s) with the given names were executed
+// in the given order, other Step(s) may have executed in between.
+func (r *Recorder[S]) AssertOrder(t testing.TB, names ...string) bool {
+	t.Helper()
+
+	steps := r.Steps()
+	i := 0
+
+	for _, step := range steps {
+		if i < len(names) && step == names[i] {
+			i++
+		}
+	}
+
+	if i < len(names) {
+		t.Errorf("daggertest: expected steps to run in order [%s], step %q did not run in order, recorded: [%s]",
+			strings.Join(names, ", "), names[i], strings.Join(steps, ", "))
+
+		return false
+	}
+
+	return true
+}
+
+// AssertNotRun asserts that none of the Step(s) with the given names were executed.
+func (r *Recorder[S]) AssertNotRun(t testing.TB, names ...string) bool {
+	t.Helper()
+
+	steps := r.Steps()
+	ok := true
+
+	for _, name := range names {
+		for _, step := range steps {
+			if step == name {
+				t.Errorf("daggertest: expected step %q not to run, recorded: [%s]", name, strings.Join(steps, ", "))
+				ok = false
+
+				break
+			}
+		}
+	}
+
+	return ok
+}
@@ -0,0 +1,75 @@
+package daggertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ajatprabha/dagger"
+)
+
+type testState struct{}
+
+// fakeT captures failures reported by assertions.
+type fakeT struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func namedStep(name string, err error) dagger.Step[testState] {
+	return dagger.Named(name, dagger.NewStep(func(context.Context, testState) error { return err }))
+}
+
+func TestRecorder(t *testing.T) {
+	dag, err := dagger.New(dagger.Series(
+		namedStep("validate", nil),
+		dagger.Result(
+			namedStep("create", errors.New("quota exceeded")),
+			namedStep("publish", nil),
+			func(context.Context, testState, error) dagger.Step[testState] {
+				return namedStep("rollback", nil)
+			},
+		),
+	))
+	assert.NoError(t, err)
+
+	recorder := NewRecorder[testState]()
+	assert.NoError(t, dag.Use(recorder.Middleware()))
+	assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+
+	assert.Equal(t, []string{"validate", "create", "rollback"}, recorder.Steps())
+
+	t.Run("AssertOrder", func(t *testing.T) {
+		assert.True(t, recorder.AssertOrder(t, "validate", "rollback"))
+
+		ft := &fakeT{}
+		assert.False(t, recorder.AssertOrder(ft, "create", "validate"))
+		assert.Equal(t, []string{
+			`daggertest: expected steps to run in order [create, validate], step "validate" did not run in order, recorded: [validate, create, rollback]`,
+		}, ft.errors)
+	})
+
+	t.Run("AssertNotRun", func(t *testing.T) {
+		assert.True(t, recorder.AssertNotRun(t, "publish"))
+
+		ft := &fakeT{}
+		assert.False(t, recorder.AssertNotRun(ft, "publish", "rollback"))
+		assert.Equal(t, []string{
+			`daggertest: expected step "rollback" not to run, recorded: [validate, create, rollback]`,
+		}, ft.errors)
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		recorder.Reset()
+		assert.Empty(t, recorder.Steps())
+	})
+}
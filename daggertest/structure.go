@@ -0,0 +1,36 @@
+package daggertest
+
+import (
+	"strings"
+
+	"github.com/ajatprabha/dagger"
+)
+
+// Structure returns a stable, indented textual tree of the DAG,
+// suitable for snapshot tests asserting its topology. Every line holds
+// the name of a Step, followed by the name of its Selector, if any.
+//
+//	dagger:seriesStep[state]
+//		validate
+//		dagger:ifStep[state] (quota-available)
+//			create
+func Structure[S any](step dagger.Step[S]) string {
+	sb := new(strings.Builder)
+
+	dagger.Walk(step, func(_ dagger.Step[S], info dagger.Info, depth int) bool {
+		sb.WriteString(strings.Repeat("\t", depth))
+		sb.WriteString(info.Name.String())
+
+		if info.Selector != nil {
+			sb.WriteString(" (")
+			sb.WriteString(info.Selector.String())
+			sb.WriteString(")")
+		}
+
+		sb.WriteString("\n")
+
+		return true
+	})
+
+	return sb.String()
+}
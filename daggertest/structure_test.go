@@ -0,0 +1,40 @@
+package daggertest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ajatprabha/dagger"
+)
+
+func TestStructure(t *testing.T) {
+	quotaAvailable := dagger.NewSelector("quota-available", func(testState) bool { return true })
+
+	step := dagger.Series(
+		namedStep("validate", nil),
+		dagger.IfElse(
+			quotaAvailable,
+			dagger.Named("provision", dagger.Series(
+				namedStep("create", nil),
+				namedStep("publish", nil),
+			)),
+			namedStep("reject", nil),
+		),
+		dagger.Continue(
+			dagger.NewStep(func(context.Context, testState) error { return nil }),
+		),
+	)
+
+	assert.Equal(t, `dagger:seriesStep[testState]
+	validate
+	dagger:ifElseStep[testState] (quota-available)
+		provision
+			create
+			publish
+		reject
+	dagger:continueStep[testState]
+		daggertest:TestStructure.func2
+`, Structure(step))
+}
@@ -0,0 +1,310 @@
+package dagger
+
+import (
+	"context"
+	"fmt"
+)
+
+// DataStep is implemented by Step(s) which declare the data they
+// produce and consume, identified by arbitrary keys.
+//
+// New rejects DAGs in which a DataStep consumes a key before another
+// Step produces it, and Plan uses the declarations to order Step(s).
+// A key produced within If, IfElse or Switch only counts as produced
+// after it if every branch produces it, and one of them always executes.
+type DataStep interface {
+	// Produces returns the keys of data the Step produces.
+	Produces() []string
+	// Consumes returns the keys of data the Step consumes.
+	Consumes() []string
+}
+
+type dataStep[S any] struct {
+	step     Step[S]
+	produces []string
+	consumes []string
+}
+
+var (
+	_ DataStep          = (*dataStep[any])(nil)
+	_ StepNamer         = (*dataStep[any])(nil)
+	_ middlewareSkipper = (*dataStep[any])(nil)
+	_ rebuilder[any]    = (*dataStep[any])(nil)
+	_ wrapperStep[any]  = (*dataStep[any])(nil)
+)
+
+func (s *dataStep[S]) Produces() []string { return s.produces }
+
+func (s *dataStep[S]) Consumes() []string { return s.consumes }
+
+func (s *dataStep[S]) StepName() fmt.Stringer { return StepName(s.step) }
+
+func (s *dataStep[S]) canSkip() bool { return canSkip(s.step) }
+
+func (s *dataStep[S]) Exec(ctx context.Context, state S) error { return s.step.Exec(ctx, state) }
+
+func (s *dataStep[S]) Unwrap() Step[S] { return s.step }
+
+func (s *dataStep[S]) wrapped() Step[S] { return s.step }
+
+func (s *dataStep[S]) rebuild(wrap wrapFunc[S]) Step[S] {
+	return &dataStep[S]{step: rebuild(s.step, wrap), produces: s.produces, consumes: s.consumes}
+}
+
+// WithData declares the data produced and consumed by the given Step,
+// it makes any Step a DataStep.
+func WithData[S any](step Step[S], produces, consumes []string) Step[S] {
+	return &dataStep[S]{step: step, produces: produces, consumes: consumes}
+}
+
+// Plan orders the given Step(s) using the data they produce and consume.
+// Step(s) which do not depend on each other are grouped in Parallel,
+// and the groups are executed in Series.
+//
+// The data of a Step is the data declared by any DataStep nested within it,
+// data consumed but not produced by any of the Step(s) is expected to be
+// present in the state beforehand.
+//
+// Plan returns an error if a key is produced by more than one Step,
+// or the Step(s) depend on each other in a cycle.
+func Plan[S any](steps ...Step[S]) (Step[S], error) {
+	producers := make(map[string]int)
+	consumes := make([][]string, len(steps))
+
+	for i, step := range steps {
+		produced, consumed := subtreeData(step)
+		consumes[i] = consumed
+
+		for _, key := range produced {
+			if j, found := producers[key]; found && j != i {
+				return nil, &ErrDuplicateProducer{key: key, stepNames: [2]fmt.Stringer{StepName(steps[j]), StepName(step)}}
+			}
+
+			producers[key] = i
+		}
+	}
+
+	dependsOn := make([]map[int]struct{}, len(steps))
+
+	for i := range steps {
+		dependsOn[i] = make(map[int]struct{})
+
+		for _, key := range consumes[i] {
+			if j, found := producers[key]; found && j != i {
+				dependsOn[i][j] = struct{}{}
+			}
+		}
+	}
+
+	done := make([]bool, len(steps))
+	stages := make([]Step[S], 0)
+
+	for remaining := len(steps); remaining > 0; {
+		var stage []Step[S]
+		var ready []int
+
+		for i, step := range steps {
+			if done[i] || !allDone(dependsOn[i], done) {
+				continue
+			}
+
+			stage = append(stage, step)
+			ready = append(ready, i)
+		}
+
+		if len(stage) == 0 {
+			for i, step := range steps {
+				if !done[i] {
+					return nil, &ErrCycle{stepName: StepName(step)}
+				}
+			}
+		}
+
+		for _, i := range ready {
+			done[i] = true
+		}
+
+		remaining -= len(stage)
+
+		if len(stage) == 1 {
+			stages = append(stages, stage[0])
+		} else {
+			stages = append(stages, Parallel(stage...))
+		}
+	}
+
+	return Series(stages...), nil
+}
+
+func allDone(deps map[int]struct{}, done []bool) bool {
+	for j := range deps {
+		if !done[j] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// subtreeData returns the keys produced within the Step, and the keys
+// consumed within it which are not produced by the Step itself.
+func subtreeData[S any](step Step[S]) (produces, consumes []string) {
+	produced := make(map[string]struct{})
+	consumed := make(map[string]struct{})
+
+	var walk func(s Step[S])
+	walk = func(s Step[S]) {
+		if d, ok := s.(DataStep); ok {
+			for _, key := range d.Consumes() {
+				consumed[key] = struct{}{}
+			}
+
+			for _, key := range d.Produces() {
+				if _, found := produced[key]; !found {
+					produced[key] = struct{}{}
+					produces = append(produces, key)
+				}
+			}
+		}
+
+		for _, child := range children(s) {
+			walk(child)
+		}
+	}
+
+	walk(step)
+
+	for key := range consumed {
+		if _, found := produced[key]; !found {
+			consumes = append(consumes, key)
+		}
+	}
+
+	return produces, consumes
+}
+
+// branchStep is implemented by the meta Step(s)
+// executing at most one of their children.
+type branchStep interface {
+	// exhaustive reports whether one of the children always executes.
+	exhaustive() bool
+}
+
+var (
+	_ branchStep = (*ifStep[any])(nil)
+	_ branchStep = (*ifElseStep[any])(nil)
+	_ branchStep = (*switchStep[any, int])(nil)
+)
+
+func (s *ifStep[S]) exhaustive() bool { return false }
+
+func (s *ifElseStep[S]) exhaustive() bool { return true }
+
+func (s *switchStep[S, K]) exhaustive() bool {
+	for _, c := range s.cases {
+		if c.isDefault {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkDataDependencies makes sure that no DataStep consumes
+// a key before the Step producing it is executed.
+func checkDataDependencies[S any](step Step[S]) error {
+	all, _ := subtreeData(step)
+	if len(all) == 0 {
+		return nil
+	}
+
+	produced := make(map[string]struct{})
+	for _, key := range all {
+		produced[key] = struct{}{}
+	}
+
+	return checkDataRecursive(step, produced, make(map[string]struct{}))
+}
+
+func checkDataRecursive[S any](step Step[S], all, produced map[string]struct{}) error {
+	d, isData := step.(DataStep)
+
+	if isData {
+		for _, key := range d.Consumes() {
+			_, producible := all[key]
+			if _, found := produced[key]; producible && !found {
+				return &ErrDependency{stepName: StepName(step), key: key}
+			}
+		}
+	}
+
+	switch s := step.(type) {
+	case *parallelStep[S]:
+		// Parallel Step(s) cannot consume data produced by their siblings.
+		before := copyKeys(produced)
+
+		for _, child := range children(step) {
+			childProduced := copyKeys(before)
+			if err := checkDataRecursive(child, all, childProduced); err != nil {
+				return err
+			}
+
+			for key := range childProduced {
+				produced[key] = struct{}{}
+			}
+		}
+	case branchStep:
+		// Only one branch executes, so the data is produced
+		// only if every branch produces it, and one always executes.
+		before := copyKeys(produced)
+
+		var common map[string]struct{}
+
+		for i, child := range children(step) {
+			childProduced := copyKeys(before)
+			if err := checkDataRecursive(child, all, childProduced); err != nil {
+				return err
+			}
+
+			if i == 0 {
+				common = childProduced
+				continue
+			}
+
+			for key := range common {
+				if _, found := childProduced[key]; !found {
+					delete(common, key)
+				}
+			}
+		}
+
+		if s.exhaustive() {
+			for key := range common {
+				produced[key] = struct{}{}
+			}
+		}
+	default:
+		for _, child := range children(step) {
+			if err := checkDataRecursive(child, all, produced); err != nil {
+				return err
+			}
+		}
+	}
+
+	if isData {
+		for _, key := range d.Produces() {
+			produced[key] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+func copyKeys(keys map[string]struct{}) map[string]struct{} {
+	c := make(map[string]struct{}, len(keys))
+	for key := range keys {
+		c[key] = struct{}{}
+	}
+
+	return c
+}
@@ -0,0 +1,159 @@
+package dagger
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func dataTestStep(name string, produces, consumes []string, order *[]string, mu *sync.Mutex) Step[testState] {
+	return WithData(Named(name, NewStep(func(_ context.Context, _ testState) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		*order = append(*order, name)
+		return nil
+	})), produces, consumes)
+}
+
+func TestPlan(t *testing.T) {
+	t.Run("Ordering", func(t *testing.T) {
+		var (
+			order []string
+			mu    sync.Mutex
+		)
+
+		publish := dataTestStep("publish", nil, []string{"vm", "dns"}, &order, &mu)
+		dns := dataTestStep("dns", []string{"dns"}, []string{"ip"}, &order, &mu)
+		vm := dataTestStep("vm", []string{"vm"}, []string{"request"}, &order, &mu)
+		ip := dataTestStep("ip", []string{"ip"}, nil, &order, &mu)
+
+		step, err := Plan(publish, dns, vm, ip)
+		assert.NoError(t, err)
+
+		series, ok := step.(*seriesStep[testState])
+		assert.True(t, ok)
+		assert.Len(t, series.steps, 3)
+		assert.Equal(t, []Step[testState]{vm, ip}, series.steps[0].(*parallelStep[testState]).steps)
+		assert.Same(t, dns, series.steps[1])
+		assert.Same(t, publish, series.steps[2])
+
+		_, err = New(step)
+		assert.NoError(t, err)
+
+		assert.NoError(t, step.Exec(context.TODO(), testState{}))
+		assert.ElementsMatch(t, []string{"vm", "ip"}, order[:2])
+		assert.Equal(t, []string{"dns", "publish"}, order[2:])
+	})
+
+	t.Run("NestedDeclarations", func(t *testing.T) {
+		var (
+			order []string
+			mu    sync.Mutex
+		)
+
+		consumer := dataTestStep("consumer", nil, []string{"b"}, &order, &mu)
+		group := Series(
+			dataTestStep("a", []string{"a"}, nil, &order, &mu),
+			dataTestStep("b", []string{"b"}, []string{"a"}, &order, &mu),
+		)
+
+		step, err := Plan(consumer, group)
+		assert.NoError(t, err)
+		assert.NoError(t, step.Exec(context.TODO(), testState{}))
+		assert.Equal(t, []string{"a", "b", "consumer"}, order)
+	})
+
+	t.Run("DuplicateProducer", func(t *testing.T) {
+		var (
+			order []string
+			mu    sync.Mutex
+		)
+
+		_, err := Plan(
+			dataTestStep("s1", []string{"vm"}, nil, &order, &mu),
+			dataTestStep("s2", []string{"vm"}, nil, &order, &mu),
+		)
+
+		errDuplicate := new(ErrDuplicateProducer)
+		assert.ErrorAs(t, err, &errDuplicate)
+		assert.EqualError(t, err, "dagger: 'vm' is produced by both step 's1' and 's2'")
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		var (
+			order []string
+			mu    sync.Mutex
+		)
+
+		_, err := Plan(
+			dataTestStep("s1", []string{"a"}, []string{"b"}, &order, &mu),
+			dataTestStep("s2", []string{"b"}, []string{"a"}, &order, &mu),
+		)
+
+		errCycle := new(ErrCycle)
+		assert.ErrorAs(t, err, &errCycle)
+	})
+}
+
+func Test_checkDataDependencies(t *testing.T) {
+	var (
+		order []string
+		mu    sync.Mutex
+	)
+
+	producer := func() Step[testState] { return dataTestStep("producer", []string{"vm"}, nil, &order, &mu) }
+	consumer := func() Step[testState] { return dataTestStep("consumer", nil, []string{"vm", "request"}, &order, &mu) }
+
+	t.Run("ProducerFirst", func(t *testing.T) {
+		_, err := New(Series(producer(), If(alwaysTrue, consumer())))
+		assert.NoError(t, err)
+	})
+
+	t.Run("ConsumerFirst", func(t *testing.T) {
+		_, err := New(Series(Series(consumer()), producer()))
+
+		errDependency := new(ErrDependency)
+		assert.ErrorAs(t, err, &errDependency)
+		assert.EqualError(t, err, "dagger: step 'consumer' consumes 'vm' before it is produced")
+	})
+
+	t.Run("ParallelSiblings", func(t *testing.T) {
+		_, err := New(Parallel(producer(), consumer()))
+
+		errDependency := new(ErrDependency)
+		assert.ErrorAs(t, err, &errDependency)
+	})
+
+	t.Run("AfterParallel", func(t *testing.T) {
+		_, err := New(Series(Parallel(producer(), NewStep(noopStep)), consumer()))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Branches", func(t *testing.T) {
+		errDependency := new(ErrDependency)
+
+		_, err := New(IfElse(alwaysTrue, producer(), consumer()))
+		assert.ErrorAs(t, err, &errDependency)
+
+		_, err = New(Series(If(alwaysTrue, producer()), consumer()))
+		assert.ErrorAs(t, err, &errDependency)
+
+		_, err = New(Series(IfElse(alwaysTrue, producer(), NewStep(noopStep)), consumer()))
+		assert.ErrorAs(t, err, &errDependency)
+
+		_, err = New(Series(Switch(func(testState) int { return 0 }, Case(0, producer())), consumer()))
+		assert.ErrorAs(t, err, &errDependency)
+
+		_, err = New(Series(Switch(func(testState) int { return 0 }, Case(0, producer()), Case(1, NewStep(noopStep)), Default[testState, int](producer())), consumer()))
+		assert.ErrorAs(t, err, &errDependency)
+
+		_, err = New(Series(IfElse(alwaysTrue, producer(), producer()), consumer()))
+		assert.NoError(t, err)
+
+		_, err = New(Series(Switch(func(testState) int { return 0 }, Case(0, producer()), Default[testState, int](producer())), consumer()))
+		assert.NoError(t, err)
+	})
+}
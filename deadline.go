@@ -0,0 +1,39 @@
+package dagger
+
+import (
+	"context"
+	"time"
+)
+
+type deadlineSkip struct {
+	margin time.Duration
+	pred   func(info Info) bool
+}
+
+// SkipNearDeadline skips the leaf Step(s) matched by pred, e.g. the ones
+// having HasLabel("critical", "false"), once the deadline of the context
+// is less than margin away, instead of starting them only to fail with a
+// timeout. The Step(s) return ErrSkip, so they are reported as skipped by
+// AuditLog, and the remaining Step(s) of the DAG keep executing.
+func SkipNearDeadline[S any](margin time.Duration, pred func(info Info) bool) ExecutorOption[S] {
+	return func(c *executorConfig[S]) { c.deadlineSkip = &deadlineSkip{margin: margin, pred: pred} }
+}
+
+// skipNearDeadline returns the middleware of SkipNearDeadline.
+func skipNearDeadline[S any](d *deadlineSkip) MiddlewareFunc[S] {
+	return func(next Step[S], info Info) Step[S] {
+		if info.CanSkip || !d.pred(info) {
+			return next
+		}
+
+		return StepFunc[S](func(ctx context.Context, state S) error {
+			// The deadline of ctx is a wall clock time, unlike the time of the
+			// Clock of the execution, which may be fake, see WithClock.
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d.margin {
+				return &ErrSkip{}
+			}
+
+			return next.Exec(ctx, state)
+		})
+	}
+}
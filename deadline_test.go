@@ -0,0 +1,68 @@
+package dagger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkipNearDeadline(t *testing.T) {
+	var executed []string
+
+	step := func(name string) Step[testState] {
+		return Named(name, NewStep(func(context.Context, testState) error {
+			executed = append(executed, name)
+			return nil
+		}))
+	}
+
+	dag, err := New(Series(
+		step("create"),
+		WithLabels(step("notify"), map[string]string{"critical": "false"}),
+		step("publish"),
+	), SkipNearDeadline[testState](time.Minute, HasLabel("critical", "false")))
+	assert.NoError(t, err)
+
+	var outcomes []string
+	assert.NoError(t, dag.Use(AuditLog[testState](func(record AuditRecord) {
+		outcomes = append(outcomes, record.Step+": "+record.Outcome)
+	})))
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
+	defer cancel()
+
+	assert.NoError(t, dag.Exec(ctx, testState{}))
+	assert.Equal(t, []string{"create", "publish"}, executed)
+	assert.Equal(t, []string{"create: success", "notify: skipped", "publish: success"}, outcomes)
+
+	executed = nil
+
+	ctx, cancel = context.WithTimeout(context.TODO(), time.Hour)
+	defer cancel()
+
+	assert.NoError(t, dag.Exec(ctx, testState{}))
+	assert.Equal(t, []string{"create", "notify", "publish"}, executed)
+
+	executed = nil
+
+	assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+	assert.Equal(t, []string{"create", "notify", "publish"}, executed)
+}
+
+func TestSkipNearDeadline_Clock(t *testing.T) {
+	executed := false
+
+	dag, err := New(Named("notify", NewStep(func(context.Context, testState) error {
+		executed = true
+		return nil
+	})), WithClock[testState](frozenClock{}), SkipNearDeadline[testState](time.Minute, LeafOnly))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 30*time.Second)
+	defer cancel()
+
+	assert.NoError(t, dag.Exec(ctx, testState{}))
+	assert.False(t, executed, "the deadline is compared to the wall clock, not to the Clock")
+}
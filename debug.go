@@ -0,0 +1,199 @@
+package dagger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRecentRuns is the number of Run(s) kept by an Executor for DebugHandler.
+const maxRecentRuns = 16
+
+// recentRuns keeps the most recent Run(s) started by ExecAsync.
+type recentRuns struct {
+	mu   sync.Mutex
+	runs []*Run
+}
+
+func (h *recentRuns) add(r *Run) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.runs) == maxRecentRuns {
+		h.runs = append(h.runs[:0], h.runs[1:]...)
+	}
+
+	h.runs = append(h.runs, r)
+}
+
+func (h *recentRuns) list() []*Run {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]*Run(nil), h.runs...)
+}
+
+// NamedExecutor is an Executor registered with DebugHandler, it is created
+// by NewNamedExecutor, or is an Executor itself, named by Executor.Name.
+type NamedExecutor interface {
+	name() string
+	writeStructure(w io.Writer)
+	recentRuns() []*Run
+	fingerprint() string
+}
+
+type namedExecutor[S any] struct {
+	n string
+	e *Executor[S]
+}
+
+// NewNamedExecutor names the Executor for DebugHandler.
+func NewNamedExecutor[S any](name string, e *Executor[S]) NamedExecutor {
+	return &namedExecutor[S]{n: name, e: e}
+}
+
+func (ne *namedExecutor[S]) name() string { return ne.n }
+
+func (ne *namedExecutor[S]) recentRuns() []*Run { return ne.e.recentRuns() }
+
+func (ne *namedExecutor[S]) fingerprint() string { return ne.e.fingerprint() }
+
+func (ne *namedExecutor[S]) writeStructure(w io.Writer) { ne.e.writeStructure(w) }
+
+var _ NamedExecutor = (*Executor[any])(nil)
+
+func (e *Executor[S]) name() string { return e.Name() }
+
+func (e *Executor[S]) recentRuns() []*Run { return e.recent.list() }
+
+func (e *Executor[S]) fingerprint() string { return e.Fingerprint() }
+
+func (e *Executor[S]) writeStructure(w io.Writer) {
+	e.walk(func(_ Step[S], info Info, depth int) bool {
+		selector := ""
+		if info.Selector != nil {
+			selector = fmt.Sprintf(" (%s)", info.Selector)
+		}
+
+		description := ""
+		if info.Description != "" {
+			description = ": " + info.Description
+		}
+
+		_, _ = fmt.Fprintf(w, "%s%s%s%s\n", strings.Repeat("\t", depth), info.Name, selector, description)
+
+		return true
+	})
+}
+
+// DebugHandler returns an http.Handler which renders the structure of the DAG
+// of each of the given Executor(s) as plain text, along with the status of the
+// Run(s) they recently started with ExecAsync. The handler is meant to be
+// mounted under /debug/dagger, next to expvar or pprof.
+//
+// The query parameter name limits the output to the Executor with that name.
+func DebugHandler(executors ...NamedExecutor) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		found := false
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+		for _, e := range executors {
+			if name != "" && e.name() != name {
+				continue
+			}
+
+			found = true
+
+			_, _ = fmt.Fprintf(w, "== %s ==\n", e.name())
+			e.writeStructure(w)
+
+			_, _ = fmt.Fprintln(w, "\nrecent runs:")
+
+			for _, run := range e.recentRuns() {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", run.ID(), run.started.Format(time.RFC3339), runStatus(run))
+			}
+
+			_, _ = fmt.Fprintln(w)
+		}
+
+		if !found && name != "" {
+			http.Error(w, fmt.Sprintf("dagger: unknown executor %q", name), http.StatusNotFound)
+		}
+	})
+}
+
+func runStatus(r *Run) string {
+	select {
+	case <-r.Done():
+		if err := r.Wait(); err != nil {
+			return "failed: " + err.Error()
+		}
+
+		if reason, ok := r.Aborted(); ok {
+			return "aborted: " + reason
+		}
+
+		return "succeeded"
+	default:
+	}
+
+	status := "running"
+	if r.Paused() {
+		status = "paused"
+	}
+
+	if info := r.CurrentStep(); info.Name != nil {
+		status += fmt.Sprintf(" step %s", info.Name)
+	}
+
+	return status
+}
+
+// WithDebugWriter makes the Executor print the tree of Step(s) to w as they
+// execute, indented by their depth in the DAG, along with the outcome and
+// duration of each of them, e.g. to follow an execution in tests or while
+// developing a DAG. Writes are serialized, and their errors are ignored.
+//
+// The lines of Step(s) executing concurrently, e.g. within Parallel, interleave.
+func WithDebugWriter[S any](w io.Writer) ExecutorOption[S] {
+	return func(c *executorConfig[S]) { c.debug = w }
+}
+
+// debugTree returns the middleware of WithDebugWriter.
+func debugTree[S any](w io.Writer) MiddlewareFunc[S] {
+	var mu sync.Mutex
+
+	printf := func(format string, args ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		_, _ = fmt.Fprintf(w, format, args...)
+	}
+
+	return func(next Step[S], info Info) Step[S] {
+		indent := strings.Repeat("  ", strings.Count(info.Path, "/"))
+
+		return StepFunc[S](func(ctx context.Context, state S) error {
+			printf("%s%s\n", indent, info.Name)
+
+			clock := ClockFromContext(ctx)
+			start := clock.Now()
+			err := next.Exec(ctx, state)
+			d := clock.Now().Sub(start)
+
+			if outcome(err) == "failure" {
+				printf("%s%s: failure in %s: %v\n", indent, info.Name, d, err)
+			} else {
+				printf("%s%s: %s in %s\n", indent, info.Name, outcome(err), d)
+			}
+
+			return err
+		})
+	}
+}
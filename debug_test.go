@@ -0,0 +1,100 @@
+package dagger
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugHandler(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	dag, err := New(Series(
+		Named("validate", NewStep(noopStep)),
+		If(NewSelector("quota-available", alwaysTrue), Named("create", NewStep(func(ctx context.Context, state testState) error {
+			close(started)
+			<-release
+			return nil
+		}))),
+	))
+	assert.NoError(t, err)
+
+	other, err := New[testState](Named("other", NewStep(noopStep)))
+	assert.NoError(t, err)
+
+	failed := other.ExecAsync(WithRunID(context.TODO(), "run-1"), testState{})
+	assert.NoError(t, failed.Wait())
+
+	run := dag.ExecAsync(WithRunID(context.TODO(), "run-2"), testState{})
+	<-started
+
+	h := DebugHandler(NewNamedExecutor("provision", dag), NewNamedExecutor("other", other))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/dagger?name=provision", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `== provision ==
+dagger:seriesStep[testState]
+	validate
+	dagger:ifStep[testState] (quota-available)
+		create
+
+recent runs:
+run-2	`)
+	assert.Contains(t, rec.Body.String(), "running step create\n")
+	assert.NotContains(t, rec.Body.String(), "other")
+
+	close(release)
+	assert.NoError(t, run.Wait())
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/dagger", nil))
+	assert.Contains(t, rec.Body.String(), "== other ==\nother\n")
+	assert.Contains(t, rec.Body.String(), "run-1\t")
+	assert.Contains(t, rec.Body.String(), "succeeded\n")
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/dagger?name=unknown", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func Test_recentRuns(t *testing.T) {
+	var h recentRuns
+
+	for i := 0; i < maxRecentRuns+2; i++ {
+		h.add(&Run{id: string(rune('a' + i))})
+	}
+
+	runs := h.list()
+	assert.Len(t, runs, maxRecentRuns)
+	assert.Equal(t, "c", runs[0].ID())
+}
+
+func TestWithDebugWriter(t *testing.T) {
+	var buf bytes.Buffer
+
+	dag, err := New(Series(
+		Named("validate", NewStep(noopStep)),
+		If(alwaysTrue, Named("create", NewStep(func(ctx context.Context, state testState) error { return testErrStep }))),
+	), WithDebugWriter[testState](&buf))
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, dag.Exec(context.TODO(), testState{}), testErrStep)
+
+	out := regexp.MustCompile(` in [0-9.]+[µnm]?s`).ReplaceAllString(buf.String(), " in 1ms")
+	assert.Equal(t, "dagger:seriesStep[testState]\n"+
+		"  validate\n"+
+		"  validate: success in 1ms\n"+
+		"  dagger:ifStep[testState]\n"+
+		"    create\n"+
+		"    create: failure in 1ms: "+testErrStep.Error()+"\n"+
+		"  dagger:ifStep[testState]: failure in 1ms: "+testErrStep.Error()+"\n"+
+		"dagger:seriesStep[testState]: failure in 1ms: "+testErrStep.Error()+"\n", out)
+}
@@ -0,0 +1,61 @@
+package dagger
+
+import (
+	"context"
+	"sync"
+)
+
+// Dedup returns a DAGMiddleware which coalesces concurrent executions whose
+// states map to the same key, like golang.org/x/sync/singleflight: while an
+// execution for a key is in flight, the executions for the same key wait for
+// it and return its error, instead of executing the DAG themselves. It prevents
+// reconciling the same resource many times over when triggers pile up.
+//
+// Only the state of the execution in flight is modified by the Step(s),
+// the states of the coalesced executions are left as is.
+func Dedup[S any](key func(state S) string) DAGMiddleware[S] {
+	var (
+		mu       sync.Mutex
+		inFlight = make(map[string]*dedupCall)
+	)
+
+	return func(next Step[S]) Step[S] {
+		return StepFunc[S](func(ctx context.Context, state S) error {
+			k := key(state)
+
+			mu.Lock()
+			if c, ok := inFlight[k]; ok {
+				mu.Unlock()
+
+				select {
+				case <-c.done:
+					return c.err
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+
+			c := &dedupCall{done: make(chan struct{})}
+			inFlight[k] = c
+			mu.Unlock()
+
+			defer func() {
+				mu.Lock()
+				delete(inFlight, k)
+				mu.Unlock()
+
+				close(c.done)
+			}()
+
+			c.err = next.Exec(ctx, state)
+
+			return c.err
+		})
+	}
+}
+
+// dedupCall is an execution in flight for a key of Dedup.
+type dedupCall struct {
+	done chan struct{}
+	err  error
+}
@@ -0,0 +1,64 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type dedupState struct{ vmID string }
+
+func TestDedup(t *testing.T) {
+	var executions atomic.Int32
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	dag, err := New(NewStep(func(ctx context.Context, state *dedupState) error {
+		if state.vmID != "vm-1" {
+			return nil
+		}
+
+		if executions.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+
+		return errors.New("vm not found")
+	}))
+	assert.NoError(t, err)
+	assert.NoError(t, dag.UseDAG(Dedup(func(state *dedupState) string { return state.vmID })))
+
+	errs := make(chan error, 3)
+
+	go func() { errs <- dag.Exec(context.TODO(), &dedupState{vmID: "vm-1"}) }()
+
+	<-started
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			errs <- dag.Exec(context.TODO(), &dedupState{vmID: "vm-1"})
+		}()
+	}
+
+	assert.NoError(t, dag.Exec(context.TODO(), &dedupState{vmID: "vm-2"}), "other keys are not coalesced")
+
+	time.Sleep(20 * time.Millisecond) // let the executions for vm-1 wait for the one in flight
+	close(release)
+	wg.Wait()
+
+	for i := 0; i < 3; i++ {
+		assert.EqualError(t, <-errs, "vm not found")
+	}
+
+	assert.Equal(t, int32(1), executions.Load())
+}
@@ -0,0 +1,67 @@
+package dagger
+
+import (
+	"context"
+	"fmt"
+)
+
+// Describer can be implemented by a Step to provide a human-readable
+// description of what it does, which is available in Info.Description.
+type Describer interface {
+	Description() string
+}
+
+type describedStep[S any] struct {
+	step        Step[S]
+	description string
+}
+
+var (
+	_ Describer         = (*describedStep[any])(nil)
+	_ StepNamer         = (*describedStep[any])(nil)
+	_ middlewareSkipper = (*describedStep[any])(nil)
+	_ rebuilder[any]    = (*describedStep[any])(nil)
+	_ wrapperStep[any]  = (*describedStep[any])(nil)
+)
+
+func (s *describedStep[S]) Description() string { return s.description }
+
+func (s *describedStep[S]) StepName() fmt.Stringer { return StepName(s.step) }
+
+func (s *describedStep[S]) canSkip() bool { return canSkip(s.step) }
+
+func (s *describedStep[S]) Exec(ctx context.Context, state S) error { return s.step.Exec(ctx, state) }
+
+func (s *describedStep[S]) Unwrap() Step[S] { return s.step }
+
+func (s *describedStep[S]) wrapped() Step[S] { return s.step }
+
+func (s *describedStep[S]) rebuild(wrap wrapFunc[S]) Step[S] {
+	return &describedStep[S]{step: rebuild(s.step, wrap), description: s.description}
+}
+
+// WithDescription attaches a human-readable description to the Step, which
+// is kept apart from its name. It is available to middlewares in
+// Info.Description, and shows up in Document and DebugHandler.
+//
+// The outermost description wins for nested calls on the same Step.
+func WithDescription[S any](step Step[S], description string) Step[S] {
+	return &describedStep[S]{step: step, description: description}
+}
+
+// stepDescription returns the description of the Step, looking through
+// the wrapperStep(s) decorating it, the outermost description wins.
+func stepDescription[S any](step Step[S]) string {
+	for {
+		if d, ok := step.(Describer); ok {
+			return d.Description()
+		}
+
+		w, ok := step.(wrapperStep[S])
+		if !ok {
+			return ""
+		}
+
+		step = w.wrapped()
+	}
+}
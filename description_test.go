@@ -0,0 +1,48 @@
+package dagger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDescription(t *testing.T) {
+	t.Run("Info", func(t *testing.T) {
+		step := WithDescription(WithLabels(Named("create", WithDescription[testState](NewStep(noopStep), "inner")),
+			map[string]string{"team": "infra"}), "creates the VM in the target zone")
+
+		info := stepInfo(step)
+		assert.Equal(t, "create", info.Name.String())
+		assert.Equal(t, "creates the VM in the target zone", info.Description)
+		assert.Equal(t, map[string]string{"team": "infra"}, info.Labels)
+		assert.False(t, info.CanSkip)
+
+		assert.Empty(t, stepInfo[testState](NewStep(noopStep)).Description)
+		assert.Equal(t, "creates the VM in the target zone", stepInfo[testState](vmStep{}).Description)
+	})
+
+	t.Run("Middleware", func(t *testing.T) {
+		var descriptions []string
+
+		dag, err := New(Series(
+			WithDescription[testState](NewStep(noopStep), "creates the VM"),
+			NewStep(noopStep),
+		))
+		assert.NoError(t, err)
+
+		assert.NoError(t, dag.Use(When(LeafOnly, func(next Step[testState], info Info) Step[testState] {
+			descriptions = append(descriptions, info.Description)
+			return next
+		})))
+
+		assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+		assert.Equal(t, []string{"creates the VM", ""}, descriptions)
+	})
+
+	t.Run("Document", func(t *testing.T) {
+		doc, err := Document(Named("create", WithDescription[testState](NewStep(noopStep), "creates the VM")))
+		assert.NoError(t, err)
+		assert.Equal(t, "# create\n\n- **create**: creates the VM\n", string(doc))
+	})
+}
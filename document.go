@@ -0,0 +1,181 @@
+package dagger
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DocumentOption configures Document.
+type DocumentOption func(*documentConfig)
+
+type documentConfig struct {
+	title   string
+	diagram bool
+}
+
+// WithTitle sets the title of the document, by default the name of the Step.
+func WithTitle(title string) DocumentOption {
+	return func(c *documentConfig) { c.title = title }
+}
+
+// WithDiagram adds a Mermaid flowchart of the DAG to the document,
+// which is rendered by GitHub, GitLab, and most markdown viewers.
+func WithDiagram() DocumentOption {
+	return func(c *documentConfig) { c.diagram = true }
+}
+
+// Document renders the DAG of the Step as markdown, e.g. to keep runbooks in
+// sync with the code. Every Step is listed in a tree, along with the package
+// it is defined in, its labels, its Selector, and its description, see Describer.
+// The failure handler of a Result is listed by name, since the Step it returns
+// is only known at runtime, unless it is HandleMultiFailure, whose
+// FailureBranches are listed instead.
+//
+// It returns ErrInvalid if the Step contains a cycle.
+func Document[S any](step Step[S], opts ...DocumentOption) ([]byte, error) {
+	if err := checkDAGCycles(step); err != nil {
+		return nil, &ErrInvalid{err: err}
+	}
+
+	cfg := documentConfig{title: StepName(step).String()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	var buf bytes.Buffer
+
+	_, _ = fmt.Fprintf(&buf, "# %s\n\n", cfg.title)
+
+	Walk(step, func(step Step[S], info Info, depth int) bool {
+		_, _ = fmt.Fprintf(&buf, "%s- **%s**", strings.Repeat("  ", depth), info.Name)
+
+		if pkg := packagePath(info.Name); pkg != "" {
+			_, _ = fmt.Fprintf(&buf, " (`%s`)", pkg)
+		}
+
+		if info.Selector != nil {
+			_, _ = fmt.Fprintf(&buf, " if `%s`", info.Selector)
+		}
+
+		if branches, ok := FailureBranches(step); ok {
+			_, _ = fmt.Fprintf(&buf, " on failure %s", formatBranches(branches))
+		} else if handler := failureHandlerName(step); handler != nil {
+			_, _ = fmt.Fprintf(&buf, " on failure `%s`", handler)
+		}
+
+		if info.Description != "" {
+			_, _ = fmt.Fprintf(&buf, ": %s", info.Description)
+		}
+
+		if info.Owner != "" {
+			_, _ = fmt.Fprintf(&buf, " owned by `%s`", info.Owner)
+		}
+
+		if len(info.Labels) > 0 {
+			_, _ = fmt.Fprintf(&buf, " %s", formatLabels(info.Labels))
+		}
+
+		buf.WriteByte('\n')
+
+		return true
+	})
+
+	if cfg.diagram {
+		writeDiagram(&buf, step)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeDiagram writes a Mermaid flowchart of the DAG of the Step,
+// the nodes are identified by the path of their Step.
+func writeDiagram[S any](buf *bytes.Buffer, step Step[S]) {
+	ids := make(map[string]string)
+	id := func(path string) string {
+		if _, ok := ids[path]; !ok {
+			ids[path] = fmt.Sprintf("n%d", len(ids))
+		}
+
+		return ids[path]
+	}
+
+	buf.WriteString("\n```mermaid\nflowchart TD\n")
+
+	Walk(step, func(step Step[S], info Info, _ int) bool {
+		_, _ = fmt.Fprintf(buf, "  %s[%q]\n", id(info.Path), info.Name.String())
+
+		if i := strings.LastIndex(info.Path, "/"); i >= 0 {
+			_, _ = fmt.Fprintf(buf, "  %s -->|%s| %s\n", id(info.Path[:i]), info.Path[i+1:], id(info.Path))
+		}
+
+		if branches, ok := FailureBranches(step); ok {
+			for i, b := range branches {
+				failure := childPath(childPath(info.Path, failureEdge), strconv.Itoa(i))
+				_, _ = fmt.Fprintf(buf, "  %s{{%q}}\n", id(failure), branchTarget(b).String())
+				_, _ = fmt.Fprintf(buf, "  %s -.->|%q| %s\n", id(info.Path), b.Match.String(), id(failure))
+			}
+		} else if handler := failureHandlerName(step); handler != nil {
+			failure := childPath(info.Path, failureEdge)
+			_, _ = fmt.Fprintf(buf, "  %s{{%q}}\n", id(failure), handler.String())
+			_, _ = fmt.Fprintf(buf, "  %s -.->|%s| %s\n", id(info.Path), failureEdge, id(failure))
+		}
+
+		return true
+	})
+
+	buf.WriteString("```\n")
+}
+
+// failureHandlerName returns the name of the failure handler of a Result, if any.
+func failureHandlerName[S any](step Step[S]) fmt.Stringer {
+	if r, ok := unwrapNode(step).(interface{ failureHandlerName() fmt.Stringer }); ok {
+		return r.failureHandlerName()
+	}
+
+	return nil
+}
+
+// branchTarget returns the name of the Step selected by the FailureBranch,
+// or a placeholder if it is only known at runtime.
+func branchTarget[S any](b BranchInfo[S]) fmt.Stringer {
+	if b.Step == nil {
+		return fmtStr("?")
+	}
+
+	return StepName(b.Step)
+}
+
+func formatBranches[S any](branches []BranchInfo[S]) string {
+	formatted := make([]string, len(branches))
+	for i, b := range branches {
+		formatted[i] = fmt.Sprintf("`%s` → `%s`", b.Match, branchTarget(b))
+	}
+
+	return strings.Join(formatted, ", ")
+}
+
+// packagePath returns the package path of a Step name, if it has one.
+func packagePath(name fmt.Stringer) string {
+	switch n := name.(type) {
+	case ScopedName:
+		return n.PackagePath()
+	case GenericScopedName:
+		return n.StepScopedName().PackagePath()
+	}
+
+	return ""
+}
+
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, fmt.Sprintf("`%s=%s`", k, v))
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, " ")
+}
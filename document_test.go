@@ -0,0 +1,86 @@
+package dagger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type vmStep struct{}
+
+func (vmStep) Exec(context.Context, testState) error { return nil }
+
+func (vmStep) Description() string { return "creates the VM in the target zone" }
+
+func TestDocument(t *testing.T) {
+	step := Series[testState](
+		vmStep{},
+		If(alwaysTrue, WithLabels(Named("publish", NewStep(noopStep)), map[string]string{"team": "infra", "tier": "1"})),
+	)
+
+	doc, err := Document(step, WithTitle("Provision VM"), WithDiagram())
+	assert.NoError(t, err)
+
+	assert.Equal(t, "# Provision VM\n\n"+
+		"- **dagger:seriesStep[testState]** (`github.com/ajatprabha/dagger`)\n"+
+		"  - **dagger:vmStep** (`github.com/ajatprabha/dagger`): creates the VM in the target zone\n"+
+		"  - **dagger:ifStep[testState]** (`github.com/ajatprabha/dagger`) if `dagger:alwaysTrue`\n"+
+		"    - **publish** `team=infra` `tier=1`\n"+
+		"\n```mermaid\nflowchart TD\n"+
+		"  n0[\"dagger:seriesStep[testState]\"]\n"+
+		"  n1[\"dagger:vmStep\"]\n"+
+		"  n0 -->|0| n1\n"+
+		"  n2[\"dagger:ifStep[testState]\"]\n"+
+		"  n0 -->|1| n2\n"+
+		"  n3[\"publish\"]\n"+
+		"  n2 -->|then| n3\n"+
+		"```\n", string(doc))
+
+	doc, err = Document[testState](vmStep{})
+	assert.NoError(t, err)
+	assert.Equal(t, "# dagger:vmStep\n\n- **dagger:vmStep** (`github.com/ajatprabha/dagger`): creates the VM in the target zone\n", string(doc))
+}
+
+func rollback(context.Context, testState, error) Step[testState] { return NewStep(noopStep) }
+
+func TestDocument_FailureBranches(t *testing.T) {
+	step := Named("provision", OnFailure(NewStep(noopStep), HandleMultiFailure(
+		BranchAs[testState, *quotaError](Named("request-quota", NewStep(noopStep))),
+		BranchIf(isTimeout, Named("wait", NewStep(noopStep))),
+	)))
+
+	doc, err := Document(step, WithDiagram())
+	assert.NoError(t, err)
+
+	assert.Equal(t, "# provision\n\n"+
+		"- **provision** on failure `as(*dagger.quotaError)` → `request-quota`, `dagger:isTimeout` → `wait`\n"+
+		"  - **dagger:noopStep** (`github.com/ajatprabha/dagger`)\n"+
+		"\n```mermaid\nflowchart TD\n"+
+		"  n0[\"provision\"]\n"+
+		"  n1{{\"request-quota\"}}\n"+
+		"  n0 -.->|\"as(*dagger.quotaError)\"| n1\n"+
+		"  n2{{\"wait\"}}\n"+
+		"  n0 -.->|\"dagger:isTimeout\"| n2\n"+
+		"  n3[\"dagger:noopStep\"]\n"+
+		"  n0 -->|main| n3\n"+
+		"```\n", string(doc))
+}
+
+func TestDocument_FailureHandler(t *testing.T) {
+	step := Named("provision", OnFailure[testState](NewStep(noopStep), rollback))
+
+	doc, err := Document(step, WithDiagram())
+	assert.NoError(t, err)
+
+	assert.Equal(t, "# provision\n\n"+
+		"- **provision** on failure `dagger:rollback`\n"+
+		"  - **dagger:noopStep** (`github.com/ajatprabha/dagger`)\n"+
+		"\n```mermaid\nflowchart TD\n"+
+		"  n0[\"provision\"]\n"+
+		"  n1{{\"dagger:rollback\"}}\n"+
+		"  n0 -.->|failure| n1\n"+
+		"  n2[\"dagger:noopStep\"]\n"+
+		"  n0 -->|main| n2\n"+
+		"```\n", string(doc))
+}
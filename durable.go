@@ -0,0 +1,108 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// Checkpoint is the progress of a durable execution, see ExecDurable.
+type Checkpoint[S any] struct {
+	// State is the state after the last completed Step.
+	State S `json:"state"`
+	// Completed holds the paths of the completed leaf Step(s).
+	Completed []string `json:"completed"`
+}
+
+// StateStore persists the Checkpoint(s) of durable executions by run ID.
+// Implementations must be safe for concurrent use.
+type StateStore[S any] interface {
+	// Load returns the Checkpoint saved for the run ID,
+	// or ErrNoCheckpoint if there is none.
+	Load(ctx context.Context, runID string) (Checkpoint[S], error)
+	// Save saves the Checkpoint for the run ID, replacing the previous one.
+	Save(ctx context.Context, runID string, cp Checkpoint[S]) error
+	// Delete deletes the Checkpoint saved for the run ID, if any.
+	Delete(ctx context.Context, runID string) error
+}
+
+// ExecDurable executes the DAG like Exec, saving a Checkpoint to the store
+// after every leaf Step which succeeds, so that the execution survives
+// process restarts.
+//
+// If the store has a Checkpoint for the run ID, the execution resumes from it:
+// its State is used instead of the given state, and the completed leaf Step(s)
+// are not executed again. The Checkpoint is deleted once the execution succeeds.
+//
+// Completed leaf Step(s) return ErrSkip to the middlewares wrapping them.
+// Conditional Step(s) are evaluated again on resume, using the saved state,
+// while Results are not saved. The DAG must not change between an execution
+// and its resumption.
+func (e *Executor[S]) ExecDurable(ctx context.Context, runID string, state S, store StateStore[S]) error {
+	cp, err := store.Load(ctx, runID)
+
+	var errNoCheckpoint *ErrNoCheckpoint
+
+	switch {
+	case errors.As(err, &errNoCheckpoint):
+		cp = Checkpoint[S]{State: state}
+	case err != nil:
+		return err
+	}
+
+	c := &checkpointer[S]{runID: runID, store: store, state: cp.State, completed: make(map[string]struct{})}
+	for _, path := range cp.Completed {
+		c.completed[path] = struct{}{}
+	}
+
+	ctx = context.WithValue(WithRunID(e.nest(ctx), runID), checkpointKey, c)
+
+	if err := e.Exec(ctx, cp.State); err != nil {
+		return err
+	}
+
+	return store.Delete(ctx, runID)
+}
+
+// checkpointer tracks the leaf Step(s) completed by a durable execution.
+type checkpointer[S any] struct {
+	runID string
+	store StateStore[S]
+	state S
+
+	mu        sync.Mutex
+	completed map[string]struct{}
+}
+
+func (c *checkpointer[S]) isCompleted(path string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.completed[path]
+
+	return ok
+}
+
+// complete records the leaf Step at path as completed, and saves a Checkpoint.
+func (c *checkpointer[S]) complete(ctx context.Context, path string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.completed[path] = struct{}{}
+
+	completed := make([]string, 0, len(c.completed))
+	for p := range c.completed {
+		completed = append(completed, p)
+	}
+
+	sort.Strings(completed)
+
+	return c.store.Save(ctx, c.runID, Checkpoint[S]{State: c.state, Completed: completed})
+}
+
+// checkpointFromContext returns the checkpointer of the durable execution of ctx, if any.
+func checkpointFromContext[S any](ctx context.Context) *checkpointer[S] {
+	c, _ := ctx.Value(checkpointKey).(*checkpointer[S])
+	return c
+}
@@ -0,0 +1,91 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type durableState struct {
+	Done []string `json:"done"`
+}
+
+func TestExecutor_ExecDurable(t *testing.T) {
+	fileStore, err := NewFileStore[*durableState](t.TempDir())
+	assert.NoError(t, err)
+
+	testcases := []struct {
+		name  string
+		store StateStore[*durableState]
+	}{
+		{name: "MemoryStore", store: NewMemoryStore[*durableState]()},
+		{name: "FileStore", store: fileStore},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			crash := true
+			var executed []string
+
+			step := func(name string) Step[*durableState] {
+				return NewStep(func(ctx context.Context, state *durableState) error {
+					if name == "publish" && crash {
+						return errors.New("process crashed")
+					}
+
+					executed = append(executed, name)
+					state.Done = append(state.Done, name)
+
+					return nil
+				})
+			}
+
+			newDAG := func() *Executor[*durableState] {
+				dag, err := New(Series(
+					step("create"),
+					step("build"),
+					step("publish"),
+				))
+				assert.NoError(t, err)
+
+				return dag
+			}
+
+			err := newDAG().ExecDurable(context.TODO(), "run-1", &durableState{}, tc.store)
+			assert.EqualError(t, err, "process crashed")
+
+			cp, err := tc.store.Load(context.TODO(), "run-1")
+			assert.NoError(t, err)
+			assert.Len(t, cp.Completed, 2)
+			assert.Equal(t, []string{"create", "build"}, cp.State.Done)
+
+			crash = false
+			executed = nil
+
+			assert.NoError(t, newDAG().ExecDurable(context.TODO(), "run-1", &durableState{}, tc.store))
+			assert.Equal(t, []string{"publish"}, executed)
+
+			_, err = tc.store.Load(context.TODO(), "run-1")
+			errNoCheckpoint := new(ErrNoCheckpoint)
+			assert.ErrorAs(t, err, &errNoCheckpoint)
+		})
+	}
+}
+
+func TestFileStore_escapedRunIDs(t *testing.T) {
+	store, err := NewFileStore[*durableState](t.TempDir())
+	assert.NoError(t, err)
+
+	for _, runID := range []string{"a/x", "b/x"} {
+		cp := Checkpoint[*durableState]{State: &durableState{Done: []string{runID}}}
+		assert.NoError(t, store.Save(context.TODO(), runID, cp))
+	}
+
+	for _, runID := range []string{"a/x", "b/x"} {
+		cp, err := store.Load(context.TODO(), runID)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{runID}, cp.State.Done)
+	}
+}
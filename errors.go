@@ -1,6 +1,12 @@
 package dagger
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
 
 // ErrCycle indicates that a cycle was detected in the DAG.
 type ErrCycle struct{ stepName fmt.Stringer }
@@ -15,3 +21,352 @@ type ErrInvalid struct{ err error }
 func (e *ErrInvalid) Error() string { return e.err.Error() }
 
 func (e *ErrInvalid) Unwrap() error { return e.err }
+
+// ErrFrozen indicates that the Executor can no longer be modified,
+// since it has already executed.
+type ErrFrozen struct{}
+
+func (e *ErrFrozen) Error() string { return "dagger: executor is frozen after first Exec" }
+
+// ErrNoResults indicates that the context does not carry any Results.
+type ErrNoResults struct{}
+
+func (e *ErrNoResults) Error() string { return "dagger: no results in context" }
+
+// ErrDependency indicates that a Step consumes data before it is produced.
+type ErrDependency struct {
+	stepName fmt.Stringer
+	key      string
+}
+
+func (e *ErrDependency) Error() string {
+	return fmt.Sprintf("dagger: step '%s' consumes '%s' before it is produced", e.stepName, e.key)
+}
+
+// ErrDuplicateProducer indicates that the same data is produced by multiple Step(s).
+type ErrDuplicateProducer struct {
+	key       string
+	stepNames [2]fmt.Stringer
+}
+
+func (e *ErrDuplicateProducer) Error() string {
+	return fmt.Sprintf("dagger: '%s' is produced by both step '%s' and '%s'", e.key, e.stepNames[0], e.stepNames[1])
+}
+
+// ErrShutdown indicates that the Executor is shut down.
+type ErrShutdown struct{}
+
+func (e *ErrShutdown) Error() string { return "dagger: executor is shut down" }
+
+// ErrAbort is returned by a Step to stop executing the remaining DAG
+// on purpose, it is created with Abort. Exec returns nil when the DAG
+// is aborted, the reason is available to Run(s) via Run.Aborted.
+type ErrAbort struct{ reason string }
+
+// Abort returns an ErrAbort with the given reason.
+func Abort(reason string) error { return &ErrAbort{reason: reason} }
+
+func (e *ErrAbort) Error() string { return fmt.Sprintf("dagger: aborted: %s", e.reason) }
+
+// Reason returns the reason the DAG was aborted.
+func (e *ErrAbort) Reason() string { return e.reason }
+
+// asAbort returns the ErrAbort in err's tree, if any.
+func asAbort(err error) (*ErrAbort, bool) {
+	if err == nil {
+		return nil, false
+	}
+
+	var abort *ErrAbort
+	ok := errors.As(err, &abort)
+
+	return abort, ok
+}
+
+// ErrSkip is returned by a leaf Step to indicate that it was skipped
+// on purpose. Meta Step(s) and Exec treat it as success, while the
+// middlewares wrapping the Step can tell it apart using Skipped.
+type ErrSkip struct{}
+
+func (e *ErrSkip) Error() string { return "dagger: step skipped" }
+
+// Skipped reports whether err indicates that a Step was skipped, see ErrSkip.
+func Skipped(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var skip *ErrSkip
+
+	return errors.As(err, &skip)
+}
+
+// ignoreSkip returns nil if err is an ErrSkip, and err otherwise.
+func ignoreSkip(err error) error {
+	if Skipped(err) {
+		return nil
+	}
+
+	return err
+}
+
+// ErrStalled indicates that a Step wrapped by Watchdog did not
+// report any Progress for too long.
+type ErrStalled struct {
+	stepName fmt.Stringer
+	since    time.Duration
+}
+
+func (e *ErrStalled) Error() string {
+	return fmt.Sprintf("dagger: step '%s' stalled, no progress for %s", e.stepName, e.since)
+}
+
+// StepName returns the name of the stalled Step.
+func (e *ErrStalled) StepName() fmt.Stringer { return e.stepName }
+
+// Since returns the time elapsed since the last Progress of the Step.
+func (e *ErrStalled) Since() time.Duration { return e.since }
+
+// ErrBudgetExceeded indicates that an execution took longer than its run budget,
+// see WithRunBudget. It wraps the error returned by the execution.
+type ErrBudgetExceeded struct {
+	budget   time.Duration
+	stepName fmt.Stringer
+	err      error
+}
+
+func (e *ErrBudgetExceeded) Error() string {
+	if e.stepName == nil {
+		return fmt.Sprintf("dagger: run budget of %s exceeded: %s", e.budget, e.err)
+	}
+
+	return fmt.Sprintf("dagger: run budget of %s exceeded while executing step '%s': %s", e.budget, e.stepName, e.err)
+}
+
+func (e *ErrBudgetExceeded) Unwrap() error { return e.err }
+
+// StepName returns the name of the Step which was executing when the
+// budget was exceeded, it is nil if no leaf Step had started yet.
+func (e *ErrBudgetExceeded) StepName() fmt.Stringer { return e.stepName }
+
+// ErrNoCheckpoint is returned by a StateStore when
+// there is no Checkpoint saved for a run ID.
+type ErrNoCheckpoint struct{ runID string }
+
+func (e *ErrNoCheckpoint) Error() string {
+	return fmt.Sprintf("dagger: no checkpoint for run '%s'", e.runID)
+}
+
+// ErrInterval indicates that the interval given to Schedule is not positive.
+type ErrInterval struct{ every time.Duration }
+
+func (e *ErrInterval) Error() string {
+	return fmt.Sprintf("dagger: schedule interval must be positive, got %s", e.every)
+}
+
+// ErrUnknownTask indicates that a Backend has no Task with the given ID.
+type ErrUnknownTask struct{ taskID string }
+
+func (e *ErrUnknownTask) Error() string { return fmt.Sprintf("dagger: unknown task '%s'", e.taskID) }
+
+// ErrUnknownStep indicates that a Dispatcher has no Step at the given path,
+// e.g. because the Task was scheduled by an Executor with a different DAG.
+type ErrUnknownStep struct{ path string }
+
+func (e *ErrUnknownStep) Error() string {
+	return fmt.Sprintf("dagger: unknown step at path '%s'", e.path)
+}
+
+// ErrRemote indicates that a RemoteStep failed on the StepServer,
+// it holds the HTTP status code and the error message of the response.
+type ErrRemote struct {
+	stepName string
+	status   int
+	msg      string
+}
+
+func (e *ErrRemote) Error() string {
+	return fmt.Sprintf("dagger: remote step '%s' failed with status %d: %s", e.stepName, e.status, e.msg)
+}
+
+// StatusCode returns the HTTP status code of the response.
+func (e *ErrRemote) StatusCode() int { return e.status }
+
+// ErrPoolClosed indicates that a Pool no longer accepts states.
+type ErrPoolClosed struct{}
+
+func (e *ErrPoolClosed) Error() string { return "dagger: pool is closed" }
+
+// ErrPanic indicates that a Step panicked, it holds the recovered value.
+type ErrPanic struct{ value any }
+
+func (e *ErrPanic) Error() string { return fmt.Sprintf("dagger: step panicked: %v", e.value) }
+
+// Value returns the value the Step panicked with.
+func (e *ErrPanic) Value() any { return e.value }
+
+// ErrHTTPStatus indicates that an HTTPStep got a response with a status code
+// other than 2xx, it holds the status code, and the start of the body.
+type ErrHTTPStatus struct {
+	status int
+	body   string
+}
+
+func (e *ErrHTTPStatus) Error() string {
+	return fmt.Sprintf("dagger: unexpected status %d: %s", e.status, e.body)
+}
+
+// StatusCode returns the status code of the response.
+func (e *ErrHTTPStatus) StatusCode() int { return e.status }
+
+// Temporary reports whether the request can be retried,
+// i.e. the status code is 429, 502, 503 or 504.
+func (e *ErrHTTPStatus) Temporary() bool {
+	switch e.status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// ErrNoStep indicates that the DAG has no Step with the given name.
+type ErrNoStep struct{ name string }
+
+func (e *ErrNoStep) Error() string { return fmt.Sprintf("dagger: no step named '%s'", e.name) }
+
+// ErrRegistered indicates that an Executor is already
+// registered with a Catalog under the same name and version.
+type ErrRegistered struct{ name, version string }
+
+func (e *ErrRegistered) Error() string {
+	return fmt.Sprintf("dagger: executor '%s' version '%s' is already registered", e.name, e.version)
+}
+
+// ErrSelector indicates that the SelectorCtx of a conditional Step failed,
+// it wraps the error returned by the SelectorCtx, or an ErrPanic if the
+// Selector panicked, see RecoverSelectorPanics.
+type ErrSelector struct {
+	selector fmt.Stringer
+	err      error
+}
+
+func (e *ErrSelector) Error() string {
+	return fmt.Sprintf("dagger: selector '%s' failed: %s", e.selector, e.err)
+}
+
+func (e *ErrSelector) Unwrap() error { return e.err }
+
+// ErrAssertion indicates that the predicate of a Require or Ensure Step
+// returned false. It holds the name of the predicate and the path of the
+// Step, which is only known when the execution is instrumented, e.g. when
+// it collects Results, or is part of a Run.
+type ErrAssertion struct {
+	kind     string
+	selector fmt.Stringer
+	path     string
+	msg      string
+}
+
+func (e *ErrAssertion) Error() string {
+	if e.path == "" {
+		return fmt.Sprintf("dagger: %s '%s' failed: %s", e.kind, e.selector, e.msg)
+	}
+
+	return fmt.Sprintf("dagger: %s '%s' failed at '%s': %s", e.kind, e.selector, e.path, e.msg)
+}
+
+// Selector returns the name of the predicate which returned false.
+func (e *ErrAssertion) Selector() fmt.Stringer { return e.selector }
+
+// Path returns the path of the Step which failed, see Info.Path.
+func (e *ErrAssertion) Path() string { return e.path }
+
+// ErrValidation indicates that a state is invalid, see Validate.
+// It wraps the errors of the validators.
+type ErrValidation struct {
+	fields []FieldError
+	err    error
+}
+
+func (e *ErrValidation) Error() string {
+	return "dagger: invalid state: " + strings.ReplaceAll(e.err.Error(), "\n", "; ")
+}
+
+func (e *ErrValidation) Unwrap() error { return e.err }
+
+// Fields returns the FieldError(s) reported by the validators, if any.
+func (e *ErrValidation) Fields() []FieldError { return e.fields }
+
+// ErrSetup indicates that the Setup of a Step failed, see SetupStep.
+type ErrSetup struct {
+	stepName fmt.Stringer
+	err      error
+}
+
+func (e *ErrSetup) Error() string {
+	return fmt.Sprintf("dagger: setting up step '%s': %v", e.stepName, e.err)
+}
+
+func (e *ErrSetup) Unwrap() error { return e.err }
+
+// ErrTeardown indicates that the Teardown of a Step failed, see TeardownStep.
+type ErrTeardown struct {
+	stepName fmt.Stringer
+	err      error
+}
+
+func (e *ErrTeardown) Error() string {
+	return fmt.Sprintf("dagger: tearing down step '%s': %v", e.stepName, e.err)
+}
+
+func (e *ErrTeardown) Unwrap() error { return e.err }
+
+// ErrOwned indicates that a Step owned by a team failed, see WithOwner.
+type ErrOwned struct {
+	stepName fmt.Stringer
+	owner    string
+	err      error
+}
+
+func (e *ErrOwned) Error() string {
+	return fmt.Sprintf("dagger: step '%s' (owner %s) failed: %v", e.stepName, e.owner, e.err)
+}
+
+func (e *ErrOwned) Unwrap() error { return e.err }
+
+// Owner returns the owner of the Step, see WithOwner.
+func (e *ErrOwned) Owner() string { return e.owner }
+
+// StepName returns the name of the owned Step.
+func (e *ErrOwned) StepName() fmt.Stringer { return e.stepName }
+
+// ErrNotExecuted indicates that a state of ExecAll or ExecStream was not
+// executed, since the batch was canceled first, e.g. by WithFailFast.
+// It wraps the cause of the cancellation.
+type ErrNotExecuted struct{ cause error }
+
+func (e *ErrNotExecuted) Error() string {
+	return fmt.Sprintf("dagger: state not executed: %v", e.cause)
+}
+
+func (e *ErrNotExecuted) Unwrap() error { return e.cause }
+
+// ErrNilBranch indicates that a FailureBranch of HandleMultiFailure
+// is nil, or selects a nil Step, see New.
+type ErrNilBranch struct {
+	stepName fmt.Stringer
+	index    int
+}
+
+func (e *ErrNilBranch) Error() string {
+	return fmt.Sprintf("dagger: failure branch %d of step '%s' is nil", e.index, e.stepName)
+}
+
+// ErrNoPreviousStep indicates that a method of a Builder applying
+// to the last added Step was called before any Step was added.
+type ErrNoPreviousStep struct{ method string }
+
+func (e *ErrNoPreviousStep) Error() string {
+	return fmt.Sprintf("dagger: builder %s called before any step", e.method)
+}
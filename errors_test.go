@@ -1,7 +1,11 @@
 package dagger
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -15,3 +19,166 @@ func TestErrInvalid_Error(t *testing.T) {
 	e := &ErrInvalid{err: assert.AnError}
 	assert.Equalf(t, assert.AnError.Error(), e.Error(), "Error()")
 }
+
+func TestErrFrozen_Error(t *testing.T) {
+	e := &ErrFrozen{}
+	assert.Equalf(t, "dagger: executor is frozen after first Exec", e.Error(), "Error()")
+}
+
+func TestErrNoResults_Error(t *testing.T) {
+	e := &ErrNoResults{}
+	assert.Equalf(t, "dagger: no results in context", e.Error(), "Error()")
+}
+
+func TestErrDependency_Error(t *testing.T) {
+	e := &ErrDependency{stepName: fmtStr("publish"), key: "vm-id"}
+	assert.Equalf(t, "dagger: step 'publish' consumes 'vm-id' before it is produced", e.Error(), "Error()")
+}
+
+func TestErrDuplicateProducer_Error(t *testing.T) {
+	e := &ErrDuplicateProducer{key: "vm-id", stepNames: [2]fmt.Stringer{fmtStr("s1"), fmtStr("s2")}}
+	assert.Equalf(t, "dagger: 'vm-id' is produced by both step 's1' and 's2'", e.Error(), "Error()")
+}
+
+func TestErrShutdown_Error(t *testing.T) {
+	e := &ErrShutdown{}
+	assert.Equalf(t, "dagger: executor is shut down", e.Error(), "Error()")
+}
+
+func TestErrAbort_Error(t *testing.T) {
+	e := &ErrAbort{reason: "already provisioned"}
+	assert.Equalf(t, "dagger: aborted: already provisioned", e.Error(), "Error()")
+	assert.Equal(t, "already provisioned", e.Reason())
+}
+
+func TestErrSkip_Error(t *testing.T) {
+	e := &ErrSkip{}
+	assert.Equalf(t, "dagger: step skipped", e.Error(), "Error()")
+}
+
+func TestSkipped(t *testing.T) {
+	assert.True(t, Skipped(&ErrSkip{}))
+	assert.True(t, Skipped(fmt.Errorf("quota: %w", &ErrSkip{})))
+	assert.False(t, Skipped(assert.AnError))
+	assert.False(t, Skipped(nil))
+}
+
+func TestErrStalled_Error(t *testing.T) {
+	e := &ErrStalled{stepName: fmtStr("create-vm"), since: time.Minute}
+	assert.Equalf(t, "dagger: step 'create-vm' stalled, no progress for 1m0s", e.Error(), "Error()")
+}
+
+func TestErrBudgetExceeded_Error(t *testing.T) {
+	e := &ErrBudgetExceeded{budget: time.Second, stepName: fmtStr("create-vm"), err: context.DeadlineExceeded}
+	assert.Equalf(t, "dagger: run budget of 1s exceeded while executing step 'create-vm': context deadline exceeded", e.Error(), "Error()")
+	assert.ErrorIs(t, e, context.DeadlineExceeded)
+
+	e = &ErrBudgetExceeded{budget: time.Second, err: context.DeadlineExceeded}
+	assert.Equalf(t, "dagger: run budget of 1s exceeded: context deadline exceeded", e.Error(), "Error()")
+}
+
+func TestErrNoCheckpoint_Error(t *testing.T) {
+	e := &ErrNoCheckpoint{runID: "run-1"}
+	assert.Equalf(t, "dagger: no checkpoint for run 'run-1'", e.Error(), "Error()")
+}
+
+func TestErrInterval_Error(t *testing.T) {
+	e := &ErrInterval{every: -time.Second}
+	assert.Equalf(t, "dagger: schedule interval must be positive, got -1s", e.Error(), "Error()")
+}
+
+func TestErrUnknownTask_Error(t *testing.T) {
+	e := &ErrUnknownTask{taskID: "task-1"}
+	assert.Equalf(t, "dagger: unknown task 'task-1'", e.Error(), "Error()")
+}
+
+func TestErrUnknownStep_Error(t *testing.T) {
+	e := &ErrUnknownStep{path: "0/1"}
+	assert.Equalf(t, "dagger: unknown step at path '0/1'", e.Error(), "Error()")
+}
+
+func TestErrRemote_Error(t *testing.T) {
+	e := &ErrRemote{stepName: "resize-disk", status: 422, msg: "disk is busy"}
+	assert.Equalf(t, "dagger: remote step 'resize-disk' failed with status 422: disk is busy", e.Error(), "Error()")
+	assert.Equal(t, 422, e.StatusCode())
+}
+
+func TestErrPoolClosed_Error(t *testing.T) {
+	e := &ErrPoolClosed{}
+	assert.Equalf(t, "dagger: pool is closed", e.Error(), "Error()")
+}
+
+func TestErrPanic_Error(t *testing.T) {
+	e := &ErrPanic{value: "boom"}
+	assert.Equalf(t, "dagger: step panicked: boom", e.Error(), "Error()")
+	assert.Equal(t, "boom", e.Value())
+}
+
+func TestErrHTTPStatus_Error(t *testing.T) {
+	e := &ErrHTTPStatus{status: 503, body: "try later"}
+	assert.Equalf(t, "dagger: unexpected status 503: try later", e.Error(), "Error()")
+	assert.Equal(t, 503, e.StatusCode())
+	assert.True(t, e.Temporary())
+
+	e = &ErrHTTPStatus{status: 404}
+	assert.False(t, e.Temporary())
+}
+
+func TestErrNoStep_Error(t *testing.T) {
+	e := &ErrNoStep{name: "publish"}
+	assert.Equalf(t, "dagger: no step named 'publish'", e.Error(), "Error()")
+}
+
+func TestErrRegistered_Error(t *testing.T) {
+	e := &ErrRegistered{name: "provision-vm", version: "v2"}
+	assert.Equalf(t, "dagger: executor 'provision-vm' version 'v2' is already registered", e.Error(), "Error()")
+}
+
+func TestErrSelector_Error(t *testing.T) {
+	e := &ErrSelector{selector: fmtStr("flag-enabled"), err: testErrStep}
+	assert.Equalf(t, "dagger: selector 'flag-enabled' failed: step error", e.Error(), "Error()")
+	assert.ErrorIs(t, e, testErrStep)
+}
+
+func TestErrAssertion_Error(t *testing.T) {
+	e := &ErrAssertion{kind: "precondition", selector: fmtStr("has-quota"), msg: "quota available"}
+	assert.Equalf(t, "dagger: precondition 'has-quota' failed: quota available", e.Error(), "Error()")
+
+	e.path = "root/1"
+	assert.Equalf(t, "dagger: precondition 'has-quota' failed at 'root/1': quota available", e.Error(), "Error()")
+}
+
+func TestErrValidation_Error(t *testing.T) {
+	e := &ErrValidation{err: errors.Join(&FieldError{Field: "Name", Msg: "is required"}, errors.New("too many replicas"))}
+	assert.Equalf(t, "dagger: invalid state: Name: is required; too many replicas", e.Error(), "Error()")
+}
+
+func TestErrSetup_Error(t *testing.T) {
+	e := &ErrSetup{stepName: fmtStr("db"), err: testErrStep}
+	assert.Equalf(t, "dagger: setting up step 'db': step error", e.Error(), "Error()")
+}
+
+func TestErrTeardown_Error(t *testing.T) {
+	e := &ErrTeardown{stepName: fmtStr("db"), err: testErrStep}
+	assert.Equalf(t, "dagger: tearing down step 'db': step error", e.Error(), "Error()")
+}
+
+func TestErrOwned_Error(t *testing.T) {
+	e := &ErrOwned{stepName: fmtStr("create"), owner: "team-compute", err: testErrStep}
+	assert.Equalf(t, "dagger: step 'create' (owner team-compute) failed: step error", e.Error(), "Error()")
+}
+
+func TestErrNotExecuted_Error(t *testing.T) {
+	e := &ErrNotExecuted{cause: testErrStep}
+	assert.Equalf(t, "dagger: state not executed: step error", e.Error(), "Error()")
+}
+
+func TestErrNilBranch_Error(t *testing.T) {
+	e := &ErrNilBranch{stepName: fmtStr("provision"), index: 1}
+	assert.Equalf(t, "dagger: failure branch 1 of step 'provision' is nil", e.Error(), "Error()")
+}
+
+func TestErrNoPreviousStep_Error(t *testing.T) {
+	e := &ErrNoPreviousStep{method: "OnFailure"}
+	assert.Equalf(t, "dagger: builder OnFailure called before any step", e.Error(), "Error()")
+}
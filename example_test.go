@@ -172,6 +172,57 @@ func ExampleContinue() {
 	}
 }
 
+func ExampleNamed() {
+	dag, err := dagger.New(
+		dagger.Series(
+			// Named overrides the runtime generated name of the anonymous function.
+			dagger.Named("validate-quota", dagger.NewStep(func(ctx context.Context, state exampleState) error {
+				return nil
+			})),
+		),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	dag.Use(func(next dagger.Step[exampleState], info dagger.Info) dagger.Step[exampleState] {
+		if !info.CanSkip {
+			fmt.Println(info.Name)
+		}
+
+		return next
+	})
+
+	if err := dag.Exec(context.Background(), exampleState{id: "example"}); err != nil {
+		panic(err)
+	}
+
+	// Output:
+	// validate-quota
+}
+
+func ExampleTSeries() {
+	type counter struct{ count int }
+
+	increment := func(ctx context.Context, state counter) (counter, error) {
+		return counter{count: state.count + 1}, nil
+	}
+
+	// TSeries passes the state returned by each TStep to the next one.
+	state, err := dagger.TSeries(
+		dagger.NewTStep(increment),
+		dagger.NewTStep(increment),
+	).Exec(context.Background(), counter{})
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(state.count)
+
+	// Output:
+	// 2
+}
+
 type exampleStepStruct struct{}
 
 func (s exampleStepStruct) Exec(_ context.Context, _ exampleState) error { return nil }
@@ -268,3 +319,16 @@ func ExampleGenericScopedName_TypeScopedName() {
 	// Output:
 	// int
 }
+
+type examplePairStep[A, B any] struct{}
+
+func (s examplePairStep[A, B]) Exec(ctx context.Context, state exampleState) error { return nil }
+
+func ExampleGenericScopedName_TypeScopedNames() {
+	stepName := dagger.StepName(&examplePairStep[int, map[string]exampleState]{})
+	gsn, _ := stepName.(dagger.GenericScopedName)
+	fmt.Println(gsn.TypeScopedNames())
+
+	// Output:
+	// [int map[string]exampleState]
+}
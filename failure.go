@@ -0,0 +1,180 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// FailureBranch selects the Step to execute for the error of a failed Step,
+// it is used with HandleMultiFailure. Besides the FailureBranch(s) returned
+// by BranchIf, BranchIs and BranchAs, it can be implemented to route errors
+// based on the state as well.
+type FailureBranch[S any] interface {
+	// SelectStep returns the Step to execute for err, it reports
+	// false if the FailureBranch does not handle err.
+	SelectStep(ctx context.Context, state S, err error) (Step[S], bool)
+}
+
+type failureBranch[S any] struct {
+	name  fmt.Stringer
+	match func(err error) bool
+	step  Step[S]
+}
+
+func (b *failureBranch[S]) SelectStep(_ context.Context, _ S, err error) (Step[S], bool) {
+	if b.match(err) {
+		return b.step, true
+	}
+
+	return nil, false
+}
+
+// BranchIf returns a FailureBranch which selects the step
+// for errors for which match returns true.
+func BranchIf[S any](match func(err error) bool, step Step[S]) FailureBranch[S] {
+	return &failureBranch[S]{name: funcName(match), match: match, step: step}
+}
+
+// BranchIs returns a FailureBranch which selects the step
+// for errors matching target, as reported by errors.Is.
+func BranchIs[S any](target error, step Step[S]) FailureBranch[S] {
+	return &failureBranch[S]{
+		name:  fmtStr(fmt.Sprintf("is(%v)", target)),
+		match: func(err error) bool { return errors.Is(err, target) },
+		step:  step,
+	}
+}
+
+// BranchAs returns a FailureBranch which selects the step for
+// errors having an error of type E in their tree, as reported by errors.As.
+func BranchAs[S any, E error](step Step[S]) FailureBranch[S] {
+	return &failureBranch[S]{
+		name: fmtStr(fmt.Sprintf("as(%s)", reflect.TypeFor[E]())),
+		match: func(err error) bool {
+			var target E
+			return errors.As(err, &target)
+		},
+		step: step,
+	}
+}
+
+// HandleMultiFailure returns a StepErrorHandler, for use with Result,
+// which executes the Step of the first FailureBranch matching the error.
+// If no FailureBranch matches, or the matching one selects a nil Step,
+// the error is returned as is.
+//
+// New rejects the DAG with ErrNilBranch if a FailureBranch is nil,
+// or selects a nil Step, like BranchIs(target, nil).
+func HandleMultiFailure[S any](branches ...FailureBranch[S]) StepErrorHandler[S] {
+	h := &multiFailure[S]{branches: branches}
+	handler := StepErrorHandler[S](func(ctx context.Context, state S, err error) Step[S] {
+		for _, b := range h.branches {
+			if b == nil {
+				continue
+			}
+
+			if step, ok := b.SelectStep(ctx, state, err); ok {
+				if step == nil {
+					break
+				}
+
+				return step
+			}
+		}
+
+		return StepFunc[S](func(context.Context, S) error { return err })
+	})
+
+	multiFailureHandlers.register(funcValuePtr(handler), h, branches)
+
+	return handler
+}
+
+// multiFailureHandlers maps the closure of a StepErrorHandler returned
+// by HandleMultiFailure to its FailureBranch(s), like selectors does.
+var multiFailureHandlers funcRegistry
+
+// multiFailure is captured by the closure of HandleMultiFailure,
+// see funcRegistry.register.
+type multiFailure[S any] struct{ branches []FailureBranch[S] }
+
+// BranchInfo describes a FailureBranch of HandleMultiFailure, see FailureBranches.
+type BranchInfo[S any] struct {
+	// Match names the errors handled by the FailureBranch, e.g. is(not found)
+	// for BranchIs, as(*dagger.ErrHTTPStatus) for BranchAs, and the name of
+	// the match func for BranchIf. It is the type of custom FailureBranch(s).
+	Match fmt.Stringer
+	// Step is the Step selected by the FailureBranch, it is nil
+	// for custom FailureBranch(s), which select it at runtime.
+	Step Step[S]
+}
+
+// FailureBranches returns the FailureBranch(s) of the failure handler of the
+// Result step, in order, e.g. for exports, documentation, and lint. It reports
+// false if step is not a Result, or its failure handler was not returned by
+// HandleMultiFailure, since the Step(s) other handlers return are only known
+// at runtime.
+func FailureBranches[S any](step Step[S]) ([]BranchInfo[S], bool) {
+	branches, ok := multiFailureBranches(step)
+	if !ok {
+		return nil, false
+	}
+
+	infos := make([]BranchInfo[S], len(branches))
+
+	for i, b := range branches {
+		switch fb := b.(type) {
+		case nil:
+			infos[i] = BranchInfo[S]{Match: fmtStr("nil")}
+		case *failureBranch[S]:
+			infos[i] = BranchInfo[S]{Match: fb.name, Step: fb.step}
+		default:
+			infos[i] = BranchInfo[S]{Match: fmtStr(reflect.TypeOf(b).String())}
+		}
+	}
+
+	return infos, true
+}
+
+// multiFailureBranches returns the FailureBranch(s) of the
+// failure handler of the Result step, see FailureBranches.
+func multiFailureBranches[S any](step Step[S]) ([]FailureBranch[S], bool) {
+	r, ok := unwrapNode(step).(*resultStep[S])
+	if !ok || r.failureHandler == nil {
+		return nil, false
+	}
+
+	v, ok := multiFailureHandlers.load(funcValuePtr(r.failureHandler))
+	if !ok {
+		return nil, false
+	}
+
+	return v.([]FailureBranch[S]), true
+}
+
+// checkFailureBranches makes sure that no FailureBranch of the
+// Result(s) using HandleMultiFailure is nil, or selects a nil Step.
+func checkFailureBranches[S any](step Step[S]) error {
+	var err error
+
+	Walk(step, func(step Step[S], info Info, _ int) bool {
+		if err != nil {
+			return false
+		}
+
+		branches, _ := multiFailureBranches(step)
+
+		for i, b := range branches {
+			if fb, ok := b.(*failureBranch[S]); b == nil || ok && fb.step == nil {
+				err = &ErrNilBranch{stepName: info.Name, index: i}
+				return false
+			}
+		}
+
+		return true
+	})
+
+	return err
+}
@@ -0,0 +1,139 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type quotaError struct{ resource string }
+
+func (e *quotaError) Error() string { return "quota exceeded: " + e.resource }
+
+type retryBranch struct{ step Step[testState] }
+
+func (b retryBranch) SelectStep(_ context.Context, _ testState, err error) (Step[testState], bool) {
+	return b.step, err.Error() == "retry"
+}
+
+func TestHandleMultiFailure(t *testing.T) {
+	errNotFound := errors.New("not found")
+
+	var handled []string
+
+	handle := func(name string) Step[testState] {
+		return NewStep(func(ctx context.Context, state testState) error {
+			handled = append(handled, name)
+			return nil
+		})
+	}
+
+	handler := HandleMultiFailure(
+		BranchIs(errNotFound, handle("not-found")),
+		BranchAs[testState, *quotaError](handle("quota")),
+		BranchIf(func(err error) bool { return err.Error() == "timeout" }, handle("timeout")),
+		retryBranch{step: handle("retry")},
+	)
+
+	failWith := func(err error) Step[testState] {
+		return Result(
+			NewStep(func(ctx context.Context, state testState) error { return err }),
+			handle("success"),
+			handler,
+		)
+	}
+
+	testcases := []struct {
+		name    string
+		err     error
+		want    string
+		wantErr error
+	}{
+		{name: "BranchIs", err: fmt.Errorf("vm: %w", errNotFound), want: "not-found"},
+		{name: "BranchAs", err: fmt.Errorf("vm: %w", &quotaError{resource: "cpu"}), want: "quota"},
+		{name: "BranchIf", err: errors.New("timeout"), want: "timeout"},
+		{name: "Custom", err: errors.New("retry"), want: "retry"},
+		{name: "NoMatch", err: testErrStep, wantErr: testErrStep},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			handled = nil
+
+			err := failWith(tc.err).Exec(context.TODO(), testState{})
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Empty(t, handled)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, []string{tc.want}, handled)
+		})
+	}
+}
+
+func TestHandleMultiFailure_NilBranch(t *testing.T) {
+	errNilBranch := new(ErrNilBranch)
+
+	_, err := New(Named("provision", OnFailure(NewStep(noopStep), HandleMultiFailure(
+		BranchIs(testErrStep, NewStep(noopStep)),
+		nil,
+	))))
+	assert.ErrorAs(t, err, &errNilBranch)
+	assert.EqualError(t, err, "dagger: failure branch 1 of step 'provision' is nil")
+
+	_, err = New(Series(OnFailure(NewStep(noopStep), HandleMultiFailure(BranchIs[testState](testErrStep, nil)))))
+	assert.ErrorAs(t, err, &errNilBranch)
+
+	branches, ok := FailureBranches(OnFailure(NewStep(noopStep), HandleMultiFailure[testState](nil)))
+	assert.True(t, ok)
+	assert.Equal(t, "nil", branches[0].Match.String())
+
+	t.Run("Exec", func(t *testing.T) {
+		errRetry := errors.New("retry")
+		failing := NewStep(func(ctx context.Context, state testState) error { return errRetry })
+
+		// the nil branch is skipped, and retryBranch selects a nil Step
+		err := OnFailure(failing, HandleMultiFailure(nil, retryBranch{})).Exec(context.TODO(), testState{})
+		assert.Equal(t, errRetry, err)
+	})
+}
+
+func isTimeout(err error) bool { return err.Error() == "timeout" }
+
+func TestFailureBranches(t *testing.T) {
+	notFound := Named("create", NewStep(noopStep))
+	quota := Named("request-quota", NewStep(noopStep))
+	timeout := Named("wait", NewStep(noopStep))
+
+	step := Result(NewStep(noopStep), nil, HandleMultiFailure(
+		BranchIs(errors.New("not found"), notFound),
+		BranchAs[testState, *quotaError](quota),
+		BranchIf(isTimeout, timeout),
+		retryBranch{},
+	))
+
+	branches, ok := FailureBranches(Named("provision", step))
+	assert.True(t, ok)
+
+	matches := make([]string, len(branches))
+	for i, b := range branches {
+		matches[i] = b.Match.String()
+	}
+
+	assert.Equal(t, []string{"is(not found)", "as(*dagger.quotaError)", "dagger:isTimeout", "dagger.retryBranch"}, matches)
+	assert.Equal(t, []Step[testState]{notFound, quota, timeout, nil}, []Step[testState]{
+		branches[0].Step, branches[1].Step, branches[2].Step, branches[3].Step,
+	})
+
+	_, ok = FailureBranches(OnFailure[testState](NewStep(noopStep), rollback))
+	assert.False(t, ok)
+
+	_, ok = FailureBranches(NewStep(noopStep))
+	assert.False(t, ok)
+}
@@ -0,0 +1,29 @@
+package dagger
+
+import "context"
+
+// FlagProvider decides whether a feature flag is enabled, see Gate.
+type FlagProvider[S any] interface {
+	Enabled(ctx context.Context, name string, state S) bool
+}
+
+// FlagProviderFunc helps implement FlagProvider in place.
+type FlagProviderFunc[S any] func(ctx context.Context, name string, state S) bool
+
+func (f FlagProviderFunc[S]) Enabled(ctx context.Context, name string, state S) bool {
+	return f(ctx, name, state)
+}
+
+// Gate executes the Step only if the feature flag with the given name is
+// enabled by the provider, e.g. to progressively roll out a new branch of
+// a workflow. The flag is surfaced as the Selector of the Step, named
+// flag:<name>, so it shows up in Info, Coverage and Document.
+func Gate[S any](name string, provider FlagProvider[S], step Step[S]) Step[S] {
+	return &ifStep[S]{
+		conditionCtx: func(ctx context.Context, state S) (bool, error) {
+			return provider.Enabled(ctx, name, state), nil
+		},
+		name:     fmtStr("flag:" + name),
+		thenStep: step,
+	}
+}
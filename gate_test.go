@@ -0,0 +1,32 @@
+package dagger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGate(t *testing.T) {
+	flags := FlagProviderFunc[testState](func(_ context.Context, name string, _ testState) bool {
+		return name == "new-checkout"
+	})
+
+	executed := 0
+	step := NewStep(func(context.Context, testState) error {
+		executed++
+		return nil
+	})
+
+	assert.NoError(t, Gate[testState]("new-checkout", flags, step).Exec(context.TODO(), testState{}))
+	assert.NoError(t, Gate[testState]("legacy-checkout", flags, step).Exec(context.TODO(), testState{}))
+	assert.Equal(t, 1, executed)
+
+	info := stepInfo(Gate[testState]("new-checkout", flags, step))
+	assert.True(t, info.CanSkip)
+	assert.Equal(t, "flag:new-checkout", info.Selector.String())
+
+	doc, err := Document(Gate[testState]("new-checkout", flags, Named("checkout", NewStep(noopStep))))
+	assert.NoError(t, err)
+	assert.Contains(t, string(doc), "if `flag:new-checkout`")
+}
@@ -0,0 +1,68 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Handler returns an http.Handler executing the DAG once per request, with the
+// state returned by decode, and the request context. The response is written by
+// encode, with the state and the error returned by Exec, HTTPStatus maps the error
+// to a status code consistently across handlers.
+//
+// The execution carries the run ID of the Dagger-Run-Id request header, or a
+// new one, which is set on the response as well to correlate it with the logs.
+// If decode fails, the response is a 400 Bad Request, and the DAG is not executed.
+func Handler[S any](
+	e *Executor[S],
+	decode func(r *http.Request) (S, error),
+	encode func(w http.ResponseWriter, state S, err error),
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state, err := decode(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		id := r.Header.Get(runIDHeader)
+		if id == "" {
+			id = NewRunID()
+		}
+
+		w.Header().Set(runIDHeader, id)
+
+		encode(w, state, e.Exec(WithRunID(r.Context(), id), state))
+	})
+}
+
+// HTTPStatus returns the HTTP status code for an error returned by Exec:
+//   - 200 OK for nil
+//   - 503 Service Unavailable for ErrShutdown
+//   - 504 Gateway Timeout for context.DeadlineExceeded and ErrBudgetExceeded
+//   - 499 Client Closed Request for context.Canceled
+//   - the status code of ErrRemote, for a failed RemoteStep
+//   - 500 Internal Server Error otherwise
+func HTTPStatus(err error) int {
+	var (
+		errShutdown *ErrShutdown
+		errBudget   *ErrBudgetExceeded
+		errRemote   *ErrRemote
+	)
+
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.As(err, &errShutdown):
+		return http.StatusServiceUnavailable
+	case errors.As(err, &errBudget), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return 499
+	case errors.As(err, &errRemote):
+		return errRemote.StatusCode()
+	default:
+		return http.StatusInternalServerError
+	}
+}
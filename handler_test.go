@@ -0,0 +1,99 @@
+package dagger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	dag, err := New(NewStep(func(ctx context.Context, vm *remoteVM) error {
+		if vm.Disk == 0 {
+			return errors.New("disk is required")
+		}
+
+		vm.Disk *= 2
+		vm.RunID, _ = RunIDFromContext(ctx)
+
+		return nil
+	}))
+	assert.NoError(t, err)
+
+	handler := Handler(dag,
+		func(r *http.Request) (*remoteVM, error) {
+			vm := new(remoteVM)
+			return vm, json.NewDecoder(r.Body).Decode(vm)
+		},
+		func(w http.ResponseWriter, vm *remoteVM, err error) {
+			if err != nil {
+				http.Error(w, err.Error(), HTTPStatus(err))
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(vm)
+		},
+	)
+
+	testcases := []struct {
+		name       string
+		body       string
+		runID      string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "Success", body: `{"disk": 10}`, runID: "run-1", wantStatus: http.StatusOK, wantBody: `{"disk":20,"run_id":"run-1"}`},
+		{name: "Failure", body: `{"disk": 0}`, runID: "run-2", wantStatus: http.StatusInternalServerError, wantBody: "disk is required"},
+		{name: "BadRequest", body: `{`, wantStatus: http.StatusBadRequest, wantBody: "unexpected EOF"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/vms", strings.NewReader(tc.body))
+			if tc.runID != "" {
+				req.Header.Set("Dagger-Run-Id", tc.runID)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+			assert.Equal(t, tc.wantBody, strings.TrimSpace(rec.Body.String()))
+			assert.Equal(t, tc.runID, rec.Header().Get("Dagger-Run-Id"))
+		})
+	}
+
+	t.Run("NewRunID", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/vms", strings.NewReader(`{"disk": 1}`)))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, rec.Header().Get("Dagger-Run-Id"), 32)
+	})
+}
+
+func TestHTTPStatus(t *testing.T) {
+	testcases := []struct {
+		err  error
+		want int
+	}{
+		{err: nil, want: http.StatusOK},
+		{err: fmt.Errorf("exec: %w", &ErrShutdown{}), want: http.StatusServiceUnavailable},
+		{err: &ErrBudgetExceeded{budget: time.Second, err: errors.New("slow")}, want: http.StatusGatewayTimeout},
+		{err: context.DeadlineExceeded, want: http.StatusGatewayTimeout},
+		{err: context.Canceled, want: 499},
+		{err: &ErrRemote{status: http.StatusConflict}, want: http.StatusConflict},
+		{err: testErrStep, want: http.StatusInternalServerError},
+	}
+
+	for _, tc := range testcases {
+		assert.Equal(t, tc.want, HTTPStatus(tc.err), "HTTPStatus(%v)", tc.err)
+	}
+}
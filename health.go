@@ -0,0 +1,72 @@
+package dagger
+
+import (
+	"context"
+	"sync"
+)
+
+// HealthChecker can be implemented by Step(s) depending on other services,
+// Healthy returns an error if they are unreachable, see Executor.HealthCheck.
+type HealthChecker interface {
+	Healthy(ctx context.Context) error
+}
+
+// HealthCheck probes the Step(s) of the DAG implementing HealthChecker,
+// concurrently, e.g. for the readiness probe of a service executing the DAG.
+// It returns the result of each probe keyed by the name of the Step, a nil
+// error for healthy ones. Each Step is probed once, even if it is part of
+// the DAG many times, Step(s) sharing a name share an entry, see
+// WithUniqueNames. The map is empty if no Step implements HealthChecker.
+func (e *Executor[S]) HealthCheck(ctx context.Context) map[string]error {
+	seen := make(map[any]struct{})
+
+	var probes []healthProbe
+
+	e.walk(func(step Step[S], info Info, _ int) bool {
+		node := unwrapNode(step)
+
+		checker, ok := node.(HealthChecker)
+		if !ok {
+			return true
+		}
+
+		if _, ok := seen[stepIdentity(node)]; !ok {
+			seen[stepIdentity(node)] = struct{}{}
+			probes = append(probes, healthProbe{name: info.Name.String(), checker: checker})
+		}
+
+		return true
+	})
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]error, len(probes))
+	)
+
+	for _, p := range probes {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			err := p.checker.Healthy(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if results[p.name] == nil {
+				results[p.name] = err
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+type healthProbe struct {
+	name    string
+	checker HealthChecker
+}
@@ -0,0 +1,40 @@
+package dagger
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// dependencyStep is a Step depending on a service, which is healthy if err is nil.
+type dependencyStep struct {
+	name   string
+	err    error
+	probes int
+}
+
+func (s *dependencyStep) StepName() fmt.Stringer { return fmtStr(s.name) }
+
+func (s *dependencyStep) Healthy(context.Context) error {
+	s.probes++
+	return s.err
+}
+
+func (s *dependencyStep) Exec(context.Context, testState) error { return nil }
+
+func TestExecutor_HealthCheck(t *testing.T) {
+	db := &dependencyStep{name: "db"}
+	queue := &dependencyStep{name: "queue", err: testErrStep}
+
+	dag, err := New(Series[testState](db, NewStep(noopStep), Named("publish", Step[testState](queue)), db))
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]error{"db": nil, "publish": testErrStep}, dag.HealthCheck(context.TODO()))
+	assert.Equal(t, 1, db.probes)
+
+	dag, err = New(NewStep(noopStep))
+	assert.NoError(t, err)
+	assert.Empty(t, dag.HealthCheck(context.TODO()))
+}
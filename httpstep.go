@@ -0,0 +1,211 @@
+package dagger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimiter blocks until an event is allowed, it is
+// implemented by golang.org/x/time/rate.Limiter.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// HTTPStepOption configures HTTPStep.
+type HTTPStepOption func(*httpStepConfig)
+
+type httpStepConfig struct {
+	client     *http.Client
+	retries    int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+	limiter    RateLimiter
+	propagator TracePropagator
+}
+
+// WithStepClient sets the client used by HTTPStep, by default http.DefaultClient.
+func WithStepClient(client *http.Client) HTTPStepOption {
+	return func(c *httpStepConfig) { c.client = client }
+}
+
+// WithStepRetries retries the requests of HTTPStep up to n times, when they
+// fail with a temporary ErrHTTPStatus, or with a transport error if they are
+// idempotent, i.e. their method is, or they have an Idempotency-Key header,
+// as the request may have reached the server. Retries wait for the Retry-After
+// response header, if any, or for baseDelay doubled after each attempt, see
+// WithStepMaxDelay. A request is not retried if the delay would end after
+// the deadline of the context.
+func WithStepRetries(n int, baseDelay time.Duration) HTTPStepOption {
+	return func(c *httpStepConfig) {
+		c.retries = n
+		c.baseDelay = baseDelay
+	}
+}
+
+// WithStepMaxDelay caps the delay between the retries of HTTPStep,
+// including the one requested by the Retry-After response header.
+func WithStepMaxDelay(max time.Duration) HTTPStepOption {
+	return func(c *httpStepConfig) { c.maxDelay = max }
+}
+
+// WithStepPropagator sets the TracePropagator injecting the trace context
+// of the execution into the requests of HTTPStep, by default W3CTraceContext.
+func WithStepPropagator(p TracePropagator) HTTPStepOption {
+	return func(c *httpStepConfig) { c.propagator = p }
+}
+
+// WithRateLimiter waits for the RateLimiter before each request of HTTPStep,
+// the same RateLimiter can be shared by the HTTPStep(s) calling the same API.
+func WithRateLimiter(l RateLimiter) HTTPStepOption {
+	return func(c *httpStepConfig) { c.limiter = l }
+}
+
+type httpStep[S any] struct {
+	build  func(ctx context.Context, state S) (*http.Request, error)
+	handle func(resp *http.Response, state S) error
+	cfg    httpStepConfig
+}
+
+func (s *httpStep[S]) StepName() fmt.Stringer { return funcName(s.build) }
+
+func (s *httpStep[S]) Exec(ctx context.Context, state S) error {
+	backoff := ExponentialBackoff(s.cfg.baseDelay, 2)
+
+	var delay time.Duration
+
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := s.do(ctx, state)
+		if err == nil || attempt == s.cfg.retries || ctx.Err() != nil || !retryable(err) {
+			return err
+		}
+
+		if retryAfter == 0 {
+			delay = backoff(attempt+1, delay)
+			retryAfter = delay
+		}
+
+		if s.cfg.maxDelay > 0 {
+			retryAfter = min(retryAfter, s.cfg.maxDelay)
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < retryAfter {
+			return err
+		}
+
+		if !sleep(ctx, retryAfter) {
+			return err
+		}
+	}
+}
+
+// do sends a single request, it returns the delay
+// requested by the Retry-After header, if any.
+func (s *httpStep[S]) do(ctx context.Context, state S) (time.Duration, error) {
+	if s.cfg.limiter != nil {
+		if err := s.cfg.limiter.Wait(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	req, err := s.build(ctx, state)
+	if err != nil {
+		return 0, err
+	}
+
+	req = req.WithContext(ctx)
+	s.cfg.propagator.Inject(ctx, req.Header)
+
+	resp, err := s.cfg.client.Do(req)
+	if err != nil {
+		if !idempotent(req) {
+			return 0, err
+		}
+
+		return 0, &errTransport{err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+
+		return retryAfter, &ErrHTTPStatus{status: resp.StatusCode, body: strings.TrimSpace(string(body))}
+	}
+
+	return 0, s.handle(resp, state)
+}
+
+// parseRetryAfter parses the Retry-After header, which is either
+// a number of seconds, or an HTTP date, it returns 0 if invalid.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+
+	if t, err := http.ParseTime(header); err == nil {
+		return max(t.Sub(now), 0)
+	}
+
+	return 0
+}
+
+// idempotent reports whether the request can be sent again after
+// it failed, the same way the retries of http.Transport do.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	_, ok := req.Header["Idempotency-Key"]
+	if !ok {
+		_, ok = req.Header["X-Idempotency-Key"]
+	}
+
+	return ok
+}
+
+// errTransport marks an error returned by the http.Client
+// for an idempotent request, to retry it.
+type errTransport struct{ err error }
+
+func (e *errTransport) Error() string { return e.err.Error() }
+
+func (e *errTransport) Unwrap() error { return e.err }
+
+func retryable(err error) bool {
+	switch err := err.(type) {
+	case *ErrHTTPStatus:
+		return err.Temporary()
+	case *errTransport:
+		return true
+	}
+
+	return false
+}
+
+// HTTPStep returns a Step calling an HTTP API, the request is built by build,
+// and the response is passed to handle, if its status code is 2xx. Other status
+// codes fail the Step with ErrHTTPStatus, without calling handle. The body of
+// the response is closed once handle returns.
+//
+// The Step is named after build, and the request is sent with the context of
+// the Step, and its trace context, see WithStepPropagator. See WithStepRetries
+// and WithRateLimiter.
+func HTTPStep[S any](
+	build func(ctx context.Context, state S) (*http.Request, error),
+	handle func(resp *http.Response, state S) error,
+	opts ...HTTPStepOption,
+) Step[S] {
+	cfg := httpStepConfig{client: http.DefaultClient, propagator: W3CTraceContext{}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return &httpStep[S]{build: build, handle: handle, cfg: cfg}
+}
@@ -0,0 +1,200 @@
+package dagger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingLimiter struct{ waits atomic.Int32 }
+
+func (l *countingLimiter) Wait(context.Context) error {
+	l.waits.Add(1)
+	return nil
+}
+
+func TestHTTPStep(t *testing.T) {
+	var calls atomic.Int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+
+		switch {
+		case r.URL.Path == "/missing":
+			http.Error(w, "no such vm", http.StatusNotFound)
+		case n == 1:
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "try later", http.StatusServiceUnavailable)
+		default:
+			_ = json.NewEncoder(w).Encode(remoteVM{Disk: 20})
+		}
+	}))
+	defer srv.Close()
+
+	newStep := func(path string, opts ...HTTPStepOption) Step[*remoteVM] {
+		return HTTPStep(
+			func(ctx context.Context, vm *remoteVM) (*http.Request, error) {
+				return http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+path, nil)
+			},
+			func(resp *http.Response, vm *remoteVM) error { return json.NewDecoder(resp.Body).Decode(vm) },
+			opts...,
+		)
+	}
+
+	t.Run("Retry", func(t *testing.T) {
+		calls.Store(0)
+
+		limiter := &countingLimiter{}
+		vm := &remoteVM{}
+
+		err := newStep("/vm", WithStepRetries(2, time.Millisecond), WithRateLimiter(limiter)).Exec(context.TODO(), vm)
+		assert.NoError(t, err)
+		assert.Equal(t, 20, vm.Disk)
+		assert.Equal(t, int32(2), calls.Load())
+		assert.Equal(t, int32(2), limiter.waits.Load())
+	})
+
+	t.Run("NoRetries", func(t *testing.T) {
+		calls.Store(0)
+
+		err := newStep("/vm").Exec(context.TODO(), &remoteVM{})
+
+		errStatus := new(ErrHTTPStatus)
+		assert.ErrorAs(t, err, &errStatus)
+		assert.Equal(t, http.StatusServiceUnavailable, errStatus.StatusCode())
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("NotTemporary", func(t *testing.T) {
+		calls.Store(1)
+
+		err := newStep("/missing", WithStepRetries(3, time.Millisecond)).Exec(context.TODO(), &remoteVM{})
+		assert.EqualError(t, err, "dagger: unexpected status 404: no such vm")
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("MaxDelay", func(t *testing.T) {
+		calls.Store(0)
+
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				w.Header().Set("Retry-After", "3600")
+				http.Error(w, "try later", http.StatusServiceUnavailable)
+
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(remoteVM{Disk: 20})
+		}))
+		defer slow.Close()
+
+		step := HTTPStep(
+			func(ctx context.Context, vm *remoteVM) (*http.Request, error) {
+				return http.NewRequestWithContext(ctx, http.MethodGet, slow.URL, nil)
+			},
+			func(resp *http.Response, vm *remoteVM) error { return json.NewDecoder(resp.Body).Decode(vm) },
+			WithStepRetries(1, time.Millisecond), WithStepMaxDelay(time.Millisecond),
+		)
+
+		assert.NoError(t, step.Exec(context.TODO(), &remoteVM{}))
+		assert.Equal(t, int32(2), calls.Load())
+
+		t.Run("Deadline", func(t *testing.T) {
+			calls.Store(0)
+
+			ctx, cancel := context.WithTimeout(context.TODO(), time.Minute)
+			defer cancel()
+
+			step := HTTPStep(
+				func(ctx context.Context, vm *remoteVM) (*http.Request, error) {
+					return http.NewRequestWithContext(ctx, http.MethodGet, slow.URL, nil)
+				},
+				func(resp *http.Response, vm *remoteVM) error { return nil },
+				WithStepRetries(1, time.Millisecond),
+			)
+
+			errStatus := new(ErrHTTPStatus)
+			assert.ErrorAs(t, step.Exec(ctx, &remoteVM{}), &errStatus, "does not wait past the deadline")
+			assert.Equal(t, int32(1), calls.Load())
+		})
+	})
+
+	t.Run("TransportError", func(t *testing.T) {
+		errRefused := errors.New("connection refused")
+
+		testcases := []struct {
+			name   string
+			method string
+			header string
+			want   int32
+		}{
+			{name: "Idempotent", method: http.MethodGet, want: 3},
+			{name: "NotIdempotent", method: http.MethodPost, want: 1},
+			{name: "IdempotencyKey", method: http.MethodPost, header: "Idempotency-Key", want: 3},
+		}
+
+		for _, tc := range testcases {
+			t.Run(tc.name, func(t *testing.T) {
+				var sent atomic.Int32
+
+				client := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+					sent.Add(1)
+					return nil, errRefused
+				})}
+
+				step := HTTPStep(
+					func(ctx context.Context, _ *remoteVM) (*http.Request, error) {
+						req, err := http.NewRequestWithContext(ctx, tc.method, "http://vms.local", nil)
+						if tc.header != "" {
+							req.Header.Set(tc.header, "vm-1")
+						}
+
+						return req, err
+					},
+					func(resp *http.Response, vm *remoteVM) error { return nil },
+					WithStepClient(client), WithStepRetries(2, time.Millisecond),
+				)
+
+				assert.ErrorIs(t, step.Exec(context.TODO(), &remoteVM{}), errRefused)
+				assert.Equal(t, tc.want, sent.Load())
+			})
+		}
+	})
+
+	t.Run("Name", func(t *testing.T) {
+		assert.Equal(t, "dagger:TestHTTPStep.func2.1", StepName(newStep("/vm")).String())
+	})
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testcases := []struct {
+		header string
+		want   time.Duration
+	}{
+		{header: "", want: 0},
+		{header: "120", want: 2 * time.Minute},
+		{header: "-1", want: 0},
+		{header: now.Add(time.Minute).Format(http.TimeFormat), want: time.Minute},
+		{header: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+		{header: "soon", want: 0},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.header, func(t *testing.T) {
+			assert.Equal(t, tc.want, parseRetryAfter(tc.header, now))
+		})
+	}
+}
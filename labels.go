@@ -0,0 +1,68 @@
+package dagger
+
+import (
+	"context"
+	"fmt"
+)
+
+type labeledStep[S any] struct {
+	step   Step[S]
+	labels map[string]string
+}
+
+var (
+	_ StepNamer         = (*labeledStep[any])(nil)
+	_ middlewareSkipper = (*labeledStep[any])(nil)
+	_ rebuilder[any]    = (*labeledStep[any])(nil)
+	_ wrapperStep[any]  = (*labeledStep[any])(nil)
+)
+
+func (s *labeledStep[S]) StepName() fmt.Stringer { return StepName(s.step) }
+
+func (s *labeledStep[S]) canSkip() bool { return canSkip(s.step) }
+
+func (s *labeledStep[S]) Exec(ctx context.Context, state S) error { return s.step.Exec(ctx, state) }
+
+func (s *labeledStep[S]) Unwrap() Step[S] { return s.step }
+
+func (s *labeledStep[S]) wrapped() Step[S] { return s.step }
+
+func (s *labeledStep[S]) rebuild(wrap wrapFunc[S]) Step[S] {
+	return &labeledStep[S]{step: rebuild(s.step, wrap), labels: s.labels}
+}
+
+// WithLabels attaches the given labels to the Step, they are available
+// to middlewares in Info.Labels, e.g. to only wrap Step(s) with HasLabel.
+//
+// Labels of the same Step added by nested calls are merged,
+// the outermost call wins for duplicate keys.
+func WithLabels[S any](step Step[S], labels map[string]string) Step[S] {
+	return &labeledStep[S]{step: step, labels: labels}
+}
+
+// stepLabels returns the merged labels of the
+// wrapperStep(s) decorating the Step, if any.
+func stepLabels[S any](step Step[S]) map[string]string {
+	var labels map[string]string
+
+	for {
+		if l, ok := step.(*labeledStep[S]); ok {
+			if labels == nil {
+				labels = make(map[string]string, len(l.labels))
+			}
+
+			for k, v := range l.labels {
+				if _, found := labels[k]; !found {
+					labels[k] = v
+				}
+			}
+		}
+
+		w, ok := step.(wrapperStep[S])
+		if !ok {
+			return labels
+		}
+
+		step = w.wrapped()
+	}
+}
@@ -0,0 +1,42 @@
+package dagger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLabels(t *testing.T) {
+	t.Run("Info", func(t *testing.T) {
+		step := WithLabels(Named("notify", WithLabels[testState](NewStep(noopStep), map[string]string{
+			"team": "growth",
+			"tier": "best-effort",
+		})), map[string]string{"team": "platform"})
+
+		info := stepInfo(step)
+		assert.Equal(t, "notify", info.Name.String())
+		assert.Equal(t, map[string]string{"team": "platform", "tier": "best-effort"}, info.Labels)
+		assert.False(t, info.CanSkip)
+
+		assert.Nil(t, stepInfo[testState](NewStep(noopStep)).Labels)
+	})
+
+	t.Run("Middleware", func(t *testing.T) {
+		var labels []map[string]string
+
+		dag, err := New(Series(
+			WithLabels[testState](NewStep(noopStep), map[string]string{"tier": "critical"}),
+			NewStep(noopStep),
+		))
+		assert.NoError(t, err)
+
+		assert.NoError(t, dag.Use(When(LeafOnly, func(next Step[testState], info Info) Step[testState] {
+			labels = append(labels, info.Labels)
+			return next
+		})))
+
+		assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+		assert.Equal(t, []map[string]string{{"tier": "critical"}, nil}, labels)
+	})
+}
@@ -0,0 +1,135 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// SetupStep can be implemented by Step(s) which need to be initialized once,
+// e.g. to compile templates or open clients, rather than on every Exec.
+type SetupStep interface {
+	Setup(ctx context.Context) error
+}
+
+// TeardownStep can be implemented by Step(s) holding resources,
+// which are released by Executor.Close.
+type TeardownStep interface {
+	Teardown(ctx context.Context) error
+}
+
+// lifecycle tracks the Step(s) of an Executor which are set up,
+// it is shared by the Executor and its Clone(s).
+type lifecycle[S any] struct {
+	mu    sync.Mutex
+	steps []lifecycleStep[S]
+	ids   map[any]struct{}
+}
+
+type lifecycleStep[S any] struct {
+	step Step[S]
+	name fmt.Stringer
+}
+
+// setup calls Setup on the SetupStep(s) of the DAG which are not set up
+// yet, each Step is set up once, even if it is part of the DAG many times.
+// If one of them fails, the Step(s) set up by this call are torn down.
+func (l *lifecycle[S]) setup(ctx context.Context, start Step[S]) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var added []lifecycleStep[S]
+
+	var err error
+
+	Walk(start, func(step Step[S], _ Info, _ int) bool {
+		if err != nil {
+			return false
+		}
+
+		node := unwrapNode(step)
+		if !hasLifecycle(node) {
+			return true
+		}
+
+		id := stepIdentity(node)
+		if _, ok := l.ids[id]; ok {
+			return true
+		}
+
+		if s, ok := node.(SetupStep); ok {
+			if setupErr := s.Setup(ctx); setupErr != nil {
+				err = &ErrSetup{stepName: StepName(step), err: setupErr}
+				return false
+			}
+		}
+
+		if l.ids == nil {
+			l.ids = make(map[any]struct{})
+		}
+
+		l.ids[id] = struct{}{}
+		added = append(added, lifecycleStep[S]{step: node, name: StepName(step)})
+
+		return true
+	})
+
+	if err != nil {
+		for _, s := range added {
+			delete(l.ids, stepIdentity(s.step))
+		}
+
+		return errors.Join(err, teardown(ctx, added))
+	}
+
+	l.steps = append(l.steps, added...)
+
+	return nil
+}
+
+// reset forgets the Step(s) which are set up, and returns them.
+func (l *lifecycle[S]) reset() []lifecycleStep[S] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	steps := l.steps
+	l.steps, l.ids = nil, nil
+
+	return steps
+}
+
+func hasLifecycle(step any) bool {
+	_, setup := step.(SetupStep)
+	_, teardown := step.(TeardownStep)
+
+	return setup || teardown
+}
+
+// teardown calls Teardown on the TeardownStep(s), in reverse order.
+func teardown[S any](ctx context.Context, steps []lifecycleStep[S]) error {
+	var errs []error
+
+	for i := len(steps) - 1; i >= 0; i-- {
+		if s, ok := steps[i].step.(TeardownStep); ok {
+			if err := s.Teardown(ctx); err != nil {
+				errs = append(errs, &ErrTeardown{stepName: steps[i].name, err: err})
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close tears down the Step(s) of the DAG implementing TeardownStep, in the
+// reverse order of their Setup, and returns their errors joined. It should be
+// called once executions are over, see Shutdown. Step(s) returned by the
+// failure handler of a Result are not part of the DAG, and are neither set up
+// nor torn down.
+//
+// Clone(s) share the Step(s) set up by the Executor they were made from, the
+// Step(s) added to any of them are set up once, and closing any of them tears
+// down the Step(s) of all of them.
+func (e *Executor[S]) Close() error {
+	return teardown(context.Background(), e.lifecycle.reset())
+}
@@ -0,0 +1,88 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// clientStep is a Step holding a client, which records its lifecycle in events.
+type clientStep struct {
+	name        string
+	events      *[]string
+	setupErr    error
+	teardownErr error
+}
+
+func (s *clientStep) StepName() fmt.Stringer { return fmtStr(s.name) }
+
+func (s *clientStep) Setup(context.Context) error {
+	*s.events = append(*s.events, "setup "+s.name)
+	return s.setupErr
+}
+
+func (s *clientStep) Teardown(context.Context) error {
+	*s.events = append(*s.events, "teardown "+s.name)
+	return s.teardownErr
+}
+
+func (s *clientStep) Exec(context.Context, testState) error { return nil }
+
+func TestExecutor_Close(t *testing.T) {
+	var events []string
+
+	db := &clientStep{name: "db", events: &events}
+	queue := &clientStep{name: "queue", events: &events, teardownErr: testErrStep}
+
+	dag, err := New(Series[testState](db, Named("publish", Step[testState](queue)), db))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"setup db", "setup queue"}, events)
+
+	assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+
+	err = dag.Close()
+	assert.ErrorIs(t, err, testErrStep)
+	assert.EqualError(t, err, "dagger: tearing down step 'publish': step error")
+	assert.Equal(t, []string{"setup db", "setup queue", "teardown queue", "teardown db"}, events)
+
+	assert.NoError(t, dag.Close())
+
+	t.Run("ErrSetup", func(t *testing.T) {
+		events = nil
+		errConnect := errors.New("connection refused")
+
+		_, err := New(Series[testState](db, &clientStep{name: "queue", events: &events, setupErr: errConnect}))
+
+		errSetup := new(ErrSetup)
+		assert.ErrorAs(t, err, &errSetup)
+		assert.ErrorIs(t, err, errConnect)
+		assert.Equal(t, []string{"setup db", "setup queue", "teardown db"}, events)
+	})
+
+	t.Run("Append", func(t *testing.T) {
+		events = nil
+
+		dag, err := New[testState](db)
+		assert.NoError(t, err)
+		assert.NoError(t, dag.Append(queue, db))
+		assert.Equal(t, []string{"setup db", "setup queue"}, events)
+	})
+
+	t.Run("Clone", func(t *testing.T) {
+		events = nil
+
+		dag, err := New[testState](db)
+		assert.NoError(t, err)
+
+		clone := dag.Clone()
+		assert.NoError(t, clone.Append(db, queue))
+		assert.Equal(t, []string{"setup db", "setup queue"}, events, "db is set up once")
+
+		_ = clone.Close()
+		assert.NoError(t, dag.Close())
+		assert.Equal(t, []string{"setup db", "setup queue", "teardown queue", "teardown db"}, events)
+	})
+}
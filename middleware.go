@@ -2,6 +2,8 @@ package dagger
 
 import (
 	"fmt"
+	"path"
+	"strconv"
 )
 
 type middleware[S any] interface {
@@ -10,12 +12,66 @@ type middleware[S any] interface {
 
 type middlewareSkipper interface{ canSkip() bool }
 
+// MetaStep can be implemented by user defined Step(s) which only compose
+// other Step(s), like Series or If do. Middlewares see the value returned
+// by CanSkipMiddleware as Info.CanSkip, which lets them skip such Step(s)
+// and only wrap the leaf Step(s).
+//
+// It is only honoured if the Step implements Rebuilder as well, otherwise
+// the middlewares could not reach the Step(s) it composes, and Info.CanSkip
+// is false.
+type MetaStep interface {
+	CanSkipMiddleware() bool
+}
+
+// Rebuilder is implemented by user defined meta Step(s), see MetaStep. Rebuild
+// returns a copy of the Step, with each of its child Step(s) replaced by the
+// Step returned by wrap, which applies the middlewares to it. The edge
+// identifies the child within the Step, e.g. its index, in Info.Path.
+type Rebuilder[S any] interface {
+	Rebuild(wrap func(edge string, child Step[S]) Step[S]) Step[S]
+}
+
+type selectorNamer interface{ selectorName() fmt.Stringer }
+
+// wrapFunc wraps a child Step, edge identifies the child
+// within its parent, e.g. "then" or the index of the child.
+type wrapFunc[S any] func(edge string, child Step[S]) Step[S]
+
+// rebuilder is implemented by meta Step(s), rebuild returns a copy
+// of the Step with all its child Step(s) passed through wrap.
+type rebuilder[S any] interface {
+	rebuild(wrap wrapFunc[S]) Step[S]
+}
+
 // Info contains information about the Step.
 type Info struct {
 	// Name is the name of the Step.
 	Name fmt.Stringer
 	// CanSkip indicates if the Step can be skipped by the middleware.
 	CanSkip bool
+	// Selector is the name of the Selector used by conditional Step(s),
+	// it is nil for all other Step(s).
+	Selector fmt.Stringer
+	// Labels are the labels attached to the Step with WithLabels, if any.
+	Labels map[string]string
+	// Description is the human-readable description of the Step,
+	// see WithDescription and Describer, it is empty by default.
+	Description string
+	// Path identifies the Step by its position in the DAG, e.g. root/1/then
+	// for the Step executed by the If which is the second Step of the root
+	// Series. Unlike names derived from functions, it only changes when the
+	// structure of the DAG does. It is used by reports like AuditLog, and by
+	// ExecDurable to track completed Step(s). It is empty for Step(s) wrapped
+	// by MiddlewareChain.Wrap, which are not part of a DAG.
+	Path string
+	// FailureBranch indicates that the Step was returned by the failure handler
+	// of a Result, or is nested within such a Step. It is executed after the
+	// mainStep of the Result failed, with the error available via Rethrow.
+	FailureBranch bool
+	// Owner is the owner of the Step set with WithOwner, or of the closest
+	// owned Step it is nested within, it is empty if there is none.
+	Owner string
 }
 
 // MiddlewareFunc allows you wrap a Step with another Step.
@@ -41,6 +97,8 @@ func (mwc MiddlewareChain[S]) apply(next Step[S], info Info) Step[S] {
 	return next
 }
 
+// NewChain creates a MiddlewareChain of the given MiddlewareFunc(s),
+// see NewOrderedChain to order them.
 func NewChain[S any](mws ...MiddlewareFunc[S]) MiddlewareChain[S] {
 	mwc := make(MiddlewareChain[S], len(mws))
 
@@ -51,20 +109,142 @@ func NewChain[S any](mws ...MiddlewareFunc[S]) MiddlewareChain[S] {
 	return mwc
 }
 
+// When returns a MiddlewareFunc which applies mw to the Step(s)
+// for which pred returns true, and leaves other Step(s) as is.
+func When[S any](pred func(info Info) bool, mw MiddlewareFunc[S]) MiddlewareFunc[S] {
+	return func(next Step[S], info Info) Step[S] {
+		if !pred(info) {
+			return next
+		}
+
+		return mw(next, info)
+	}
+}
+
+// LeafOnly is a predicate for When, which matches Step(s) that can not be skipped.
+func LeafOnly(info Info) bool { return !info.CanSkip }
+
+// NameMatches returns a predicate for When, which matches Step(s) with names
+// matching the given glob, using the syntax of path.Match. An invalid
+// glob does not match any Step.
+func NameMatches(glob string) func(info Info) bool {
+	return func(info Info) bool {
+		matched, err := path.Match(glob, info.Name.String())
+		return err == nil && matched
+	}
+}
+
+// HasLabel returns a predicate for When, which matches
+// Step(s) having the label key set to value.
+func HasLabel(key, value string) func(info Info) bool {
+	return func(info Info) bool {
+		v, ok := info.Labels[key]
+		return ok && v == value
+	}
+}
+
 // Wrap applies the middleware chain to the provided Step.
 func (mwc MiddlewareChain[S]) Wrap(s Step[S]) Step[S] { return mwc.apply(s, stepInfo(s)) }
 
+// compile applies the middleware chain to the provided Step
+// and to every Step nested within it.
+func (mwc MiddlewareChain[S]) compile(s Step[S]) Step[S] { return mwc.compileNamed(s, nil) }
+
+// compileNamed is compile, with Step(s) named by namer, if any.
+func (mwc MiddlewareChain[S]) compileNamed(s Step[S], namer func(Step[S]) fmt.Stringer) Step[S] {
+	if len(mwc) == 0 && namer == nil {
+		return s
+	}
+
+	return mwc.wrapAt(rootPath, "", false, s, namer)
+}
+
+// wrapAt wraps s at path, and the Step(s) nested within it, the owner and
+// failureBranch inherited from the Step(s) s is nested within are reported
+// in the Info unless s sets them.
+func (mwc MiddlewareChain[S]) wrapAt(path, owner string, failureBranch bool, s Step[S], namer func(Step[S]) fmt.Stringer) Step[S] {
+	info := stepInfo(s)
+	info.Path = path
+	info.FailureBranch = failureBranch
+
+	if info.Owner == "" {
+		info.Owner = owner
+	}
+
+	if namer != nil {
+		info.Name = namer(s)
+	}
+
+	_, isResult := unwrapNode(s).(*resultStep[S])
+
+	rebuilt := rebuild(s, func(edge string, child Step[S]) Step[S] {
+		failureBranch := info.FailureBranch || isResult && edge == failureEdge
+		return mwc.wrapAt(childPath(path, edge), info.Owner, failureBranch, child, namer)
+	})
+
+	// The wrapped Step keeps the name of s, so that
+	// meta Step(s) can keep referring to it by name.
+	return &renamedStep[S]{name: info.Name, step: mwc.apply(rebuilt, info)}
+}
+
+const (
+	rootPath = "root"
+	// failureEdge is the edge of the Step returned by the failure handler of a Result.
+	failureEdge = "failure"
+)
+
+func childPath(path, edge string) string { return path + "/" + edge }
+
+// rebuild passes the child Step(s) of s through wrap,
+// Step(s) which are not meta Step(s) are returned as is.
+func rebuild[S any](s Step[S], wrap wrapFunc[S]) Step[S] {
+	switch r := s.(type) {
+	case rebuilder[S]:
+		return r.rebuild(wrap)
+	case Rebuilder[S]:
+		return r.Rebuild(wrap)
+	}
+
+	return s
+}
+
+func wrapEach[S any](steps []Step[S], wrap wrapFunc[S]) []Step[S] {
+	wrapped := make([]Step[S], len(steps))
+
+	for i, step := range steps {
+		wrapped[i] = wrap(strconv.Itoa(i), step)
+	}
+
+	return wrapped
+}
+
 func stepInfo[S any](s Step[S]) Info {
 	return Info{
-		Name:    StepName(s),
-		CanSkip: canSkip(s),
+		Name:     StepName(s),
+		CanSkip:  canSkip(s),
+		Selector: selectorName(s),
+		Labels:   stepLabels(s),
+		Owner:    stepOwner(s),
+
+		Description: stepDescription(s),
+	}
+}
+
+func selectorName[S any](s Step[S]) fmt.Stringer {
+	if namer, ok := s.(selectorNamer); ok {
+		return namer.selectorName()
 	}
+
+	return nil
 }
 
 func canSkip[S any](s Step[S]) bool {
-	skipper, ok := s.(middlewareSkipper)
-	if ok {
+	switch skipper := s.(type) {
+	case middlewareSkipper:
 		return skipper.canSkip()
+	case MetaStep:
+		_, ok := s.(Rebuilder[S])
+		return ok && skipper.CanSkipMiddleware()
 	}
 
 	return false
@@ -57,3 +57,192 @@ L1: seriesStep[testState] done
 `, buf.String())
 	})
 }
+
+func TestMiddlewareChain_compile(t *testing.T) {
+	t.Run("FailureBranch", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+
+		chain := NewChain(testLogMiddleware[testState](buf, "L1"))
+
+		step := chain.compile(Result(
+			Named("main", NewStep(func(ctx context.Context, state testState) error { return testErrStep })),
+			Named("success", NewStep(func(ctx context.Context, state testState) error { return nil })),
+			func(ctx context.Context, state testState, err error) Step[testState] {
+				return Named("failure", NewStep(func(ctx context.Context, state testState) error { return nil }))
+			},
+		))
+
+		err := step.Exec(context.TODO(), testState{})
+		assert.NoError(t, err)
+		assert.Equal(t, `L1: Starting step resultStep[testState]
+L1: Starting step main
+L1: main done
+L1: Starting step failure
+L1: failure done
+L1: resultStep[testState] done
+`, buf.String())
+	})
+
+	t.Run("FailureBranchOfResultOnly", func(t *testing.T) {
+		var branches []string
+
+		chain := NewChain(func(next Step[testState], info Info) Step[testState] {
+			if info.FailureBranch {
+				branches = append(branches, info.Path)
+			}
+
+			return next
+		})
+
+		step := chain.compile(Series(
+			&failoverStep{step: NewStep(noopStep)},
+			OnFailure(NewStep(func(ctx context.Context, state testState) error { return testErrStep }),
+				func(ctx context.Context, state testState, err error) Step[testState] {
+					return Series(NewStep(noopStep))
+				},
+			),
+		))
+
+		assert.NoError(t, step.Exec(context.TODO(), testState{}))
+		assert.Equal(t, []string{"root/1/failure/0", "root/1/failure"}, branches)
+	})
+
+	t.Run("KeepsStepNames", func(t *testing.T) {
+		chain := NewChain(testLogMiddleware[testState](io.Discard, "L1"))
+
+		step := chain.compile(Continue(
+			Named("failing", NewStep(func(ctx context.Context, state testState) error { return testErrStep })),
+		))
+
+		assert.Equal(t, "dagger:continueStep[testState]", StepName(step).String())
+
+		err := step.Exec(context.TODO(), testState{})
+		assert.ErrorIs(t, err, testErrStep)
+		assert.EqualError(t, err, "error executing step failing: step error")
+	})
+
+	t.Run("EmptyChain", func(t *testing.T) {
+		step := Series(NewStep(func(ctx context.Context, state testState) error { return nil }))
+		assert.Same(t, step, NewChain[testState]().compile(step))
+	})
+}
+
+type retryTwiceStep struct{ step Step[testState] }
+
+func (s *retryTwiceStep) CanSkipMiddleware() bool { return true }
+
+func (s *retryTwiceStep) Exec(ctx context.Context, state testState) error {
+	if err := s.step.Exec(ctx, state); err != nil {
+		return s.step.Exec(ctx, state)
+	}
+
+	return nil
+}
+
+func (s *retryTwiceStep) Unwrap() Step[testState] { return s.step }
+
+func (s *retryTwiceStep) Rebuild(wrap func(edge string, child Step[testState]) Step[testState]) Step[testState] {
+	return &retryTwiceStep{step: wrap("retry", s.step)}
+}
+
+// failoverStep is a MetaStep whose child is at the edge failure, like the
+// failure branch of a Result.
+type failoverStep struct{ step Step[testState] }
+
+func (s *failoverStep) CanSkipMiddleware() bool { return true }
+
+func (s *failoverStep) Exec(ctx context.Context, state testState) error {
+	return s.step.Exec(ctx, state)
+}
+
+func (s *failoverStep) Unwrap() Step[testState] { return s.step }
+
+func (s *failoverStep) Rebuild(wrap func(edge string, child Step[testState]) Step[testState]) Step[testState] {
+	return &failoverStep{step: wrap("failure", s.step)}
+}
+
+// opaqueMetaStep implements MetaStep, but not Rebuilder.
+type opaqueMetaStep struct{ step Step[testState] }
+
+func (s *opaqueMetaStep) CanSkipMiddleware() bool { return true }
+
+func (s *opaqueMetaStep) Exec(ctx context.Context, state testState) error {
+	return s.step.Exec(ctx, state)
+}
+
+func TestMetaStep(t *testing.T) {
+	noop := NewStep(func(ctx context.Context, state testState) error { return nil })
+
+	assert.True(t, stepInfo[testState](&retryTwiceStep{step: noop}).CanSkip)
+	assert.True(t, stepInfo(Named("retry", Step[testState](&retryTwiceStep{step: noop}))).CanSkip)
+	assert.False(t, stepInfo[testState](&opaqueMetaStep{step: noop}).CanSkip)
+	assert.False(t, stepInfo[testState](noop).CanSkip)
+
+	t.Run("Middlewares", func(t *testing.T) {
+		calls := 0
+		flaky := Named("flaky", NewStep(func(ctx context.Context, state testState) error {
+			if calls++; calls == 1 {
+				return testErrStep
+			}
+
+			return nil
+		}))
+
+		var wrapped, executed []string
+
+		dag, err := New(Series[testState](Named("retry", &retryTwiceStep{step: flaky}), &opaqueMetaStep{step: noop}))
+		assert.NoError(t, err)
+		assert.NoError(t, dag.Use(func(next Step[testState], info Info) Step[testState] {
+			wrapped = append(wrapped, info.Path)
+
+			if info.CanSkip {
+				return next
+			}
+
+			return StepFunc[testState](func(ctx context.Context, state testState) error {
+				executed = append(executed, info.Name.String())
+				return next.Exec(ctx, state)
+			})
+		}))
+
+		assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+		assert.Equal(t, []string{"root/0/retry", "root/0", "root/1", "root"}, wrapped)
+		assert.Equal(t, []string{"flaky", "flaky", "dagger:opaqueMetaStep"}, executed)
+	})
+}
+
+func TestWhen(t *testing.T) {
+	noop := NewStep(func(ctx context.Context, state testState) error { return nil })
+
+	step := Series(
+		Named("validate-quota", noop),
+		WithLabels(Named("notify", noop), map[string]string{"tier": "best-effort"}),
+		Named("validate-vm", noop),
+	)
+
+	testcases := []struct {
+		name string
+		pred func(info Info) bool
+		want []string
+	}{
+		{name: "LeafOnly", pred: LeafOnly, want: []string{"validate-quota", "notify", "validate-vm"}},
+		{name: "NameMatches", pred: NameMatches("validate-*"), want: []string{"validate-quota", "validate-vm"}},
+		{name: "InvalidGlob", pred: NameMatches("[validate"), want: nil},
+		{name: "HasLabel", pred: HasLabel("tier", "best-effort"), want: []string{"notify"}},
+		{name: "HasLabelOtherValue", pred: HasLabel("tier", "critical"), want: nil},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var wrapped []string
+
+			chain := NewChain(When(tc.pred, func(next Step[testState], info Info) Step[testState] {
+				wrapped = append(wrapped, info.Name.String())
+				return next
+			}))
+
+			assert.NoError(t, chain.compile(step).Exec(context.TODO(), testState{}))
+			assert.Equal(t, tc.want, wrapped)
+		})
+	}
+}
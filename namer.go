@@ -1,11 +1,18 @@
 package dagger
 
 import (
+	"context"
+	"encoding"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"regexp"
 	"runtime"
+	"runtime/debug"
+	"sort"
 	"strings"
+	"sync"
+	"unsafe"
 )
 
 // StepNamer is the authoritative provider of a step's name.
@@ -18,25 +25,39 @@ type StepNamer interface {
 // ScopedName holds the package name and function name of the StepFunc.
 type ScopedName [2]string
 
-// GenericScopedName holds the info about Step[S] and S.
+// GenericScopedName holds the info about Step[S] and its type arguments: the
+// ScopedName of the Step, and the one of its type argument S. If the Step has
+// several type arguments, or a type argument which is not a named type, the
+// second ScopedName has no package path, and its name holds the type arguments
+// as reported by reflect, e.g. github.com/org/pkg.A,map[string]*bytes.Buffer,
+// see TypeScopedNames.
 type GenericScopedName [2]ScopedName
 
-func (s ScopedName) Module() string {
-	pp := s.PackagePath()
-	lastIndex := strings.LastIndex(pp, "/")
-	if lastIndex == -1 {
-		return ""
-	}
-	return pp[:lastIndex]
-}
+// Module returns the path of the module the package belongs to, it is
+// empty for the standard library. It is looked up in the build info of
+// the binary, and otherwise derived from the package path, see modulePath.
+func (s ScopedName) Module() string { return modulePath(s.PackagePath()) }
+
+// Package returns the name of the package, i.e. the last element
+// of its path, not counting major version suffixes like /v2.
 func (s ScopedName) Package() string {
 	pp := s.PackagePath()
-	lastIndex := strings.LastIndex(pp, "/")
-	if lastIndex == -1 {
-		return pp
+
+	pkg := pp[strings.LastIndex(pp, "/")+1:]
+	if isMajorVersion(pkg) && len(pp) > len(pkg) {
+		if i := strings.LastIndex(pp[:len(pp)-len(pkg)-1], "/"); i != -1 {
+			pkg = pp[i+1 : len(pp)-len(pkg)-1]
+		}
 	}
-	return pp[lastIndex+1:]
+
+	// gopkg.in style, e.g. gopkg.in/yaml.v3
+	if i := strings.LastIndex(pkg, ".v"); i != -1 && isMajorVersion(pkg[i+1:]) {
+		pkg = pkg[:i]
+	}
+
+	return pkg
 }
+
 func (s ScopedName) PackagePath() string { return s[0] }
 func (s ScopedName) Name() string        { return s[1] }
 func (s ScopedName) String() string {
@@ -52,9 +73,105 @@ func (s ScopedName) String() string {
 }
 
 func (s GenericScopedName) StepScopedName() ScopedName { return s[0] }
-func (s GenericScopedName) TypeScopedName() ScopedName { return s[1] }
+
+// TypeScopedName returns the ScopedName of the first type argument.
+func (s GenericScopedName) TypeScopedName() ScopedName { return s.TypeScopedNames()[0] }
+
+// TypeScopedNames returns the ScopedName of each type argument, in order.
+//
+// Named types, optionally behind pointers, are scoped to their package,
+// e.g. *bytes.Buffer has the package path bytes and the name *Buffer,
+// while the type arguments of nested generic types, and composite types
+// like map[string]*bytes.Buffer, are named without package qualifiers.
+func (s GenericScopedName) TypeScopedNames() []ScopedName {
+	if s[1].PackagePath() != "" {
+		return []ScopedName{s[1]}
+	}
+
+	args := splitTypeArgs(s[1].Name())
+	names := make([]ScopedName, 0, len(args))
+
+	for _, arg := range args {
+		names = append(names, typeArgScopedName(arg))
+	}
+
+	return names
+}
+
 func (s GenericScopedName) String() string {
-	return fmt.Sprintf("%s[%s]", s[0].String(), s[1].Name())
+	types := s.TypeScopedNames()
+	names := make([]string, 0, len(types))
+
+	for _, t := range types {
+		names = append(names, t.Name())
+	}
+
+	return fmt.Sprintf("%s[%s]", s[0].String(), strings.Join(names, ","))
+}
+
+// Sanitized returns the name as an identifier safe to use in metrics,
+// e.g. pkg_stepName, see sanitize.
+func (s ScopedName) Sanitized() string { return sanitize(s.String()) }
+
+// Sanitized returns the name as an identifier safe to use in
+// metrics, e.g. pkg_step_State for pkg:step[State], see sanitize.
+func (s GenericScopedName) Sanitized() string { return sanitize(s.String()) }
+
+var (
+	_ json.Marshaler         = ScopedName{}
+	_ encoding.TextMarshaler = ScopedName{}
+	_ json.Marshaler         = GenericScopedName{}
+	_ encoding.TextMarshaler = GenericScopedName{}
+)
+
+// scopedNameJSON is the JSON representation of ScopedName and GenericScopedName.
+type scopedNameJSON struct {
+	Module     string            `json:"module,omitempty"`
+	Package    string            `json:"package,omitempty"`
+	Name       string            `json:"name"`
+	TypeParams []*scopedNameJSON `json:"type_params,omitempty"`
+}
+
+func (s ScopedName) toJSON() *scopedNameJSON {
+	return &scopedNameJSON{Module: s.Module(), Package: s.Package(), Name: s.Name()}
+}
+
+// MarshalJSON encodes the ScopedName as an object with its module, package and name.
+func (s ScopedName) MarshalJSON() ([]byte, error) { return json.Marshal(s.toJSON()) }
+
+// MarshalText encodes the ScopedName as its fully qualified
+// name, e.g. github.com/ajatprabha/dagger.noopStep.
+func (s ScopedName) MarshalText() ([]byte, error) {
+	if s.PackagePath() == "" {
+		return []byte(s.Name()), nil
+	}
+
+	name, ptr := strings.CutPrefix(s.Name(), "*")
+	if ptr {
+		return []byte("*" + s.PackagePath() + "." + name), nil
+	}
+
+	return []byte(s.PackagePath() + "." + name), nil
+}
+
+// MarshalJSON encodes the GenericScopedName like ScopedName, along
+// with the ScopedName of each of its type parameters in type_params.
+func (s GenericScopedName) MarshalJSON() ([]byte, error) {
+	v := s.StepScopedName().toJSON()
+	for _, t := range s.TypeScopedNames() {
+		v.TypeParams = append(v.TypeParams, t.toJSON())
+	}
+
+	return json.Marshal(v)
+}
+
+// MarshalText encodes the GenericScopedName as the fully qualified names of
+// the Step and its type parameters, e.g. github.com/org/pkg.step[github.com/org/pkg.State].
+func (s GenericScopedName) MarshalText() ([]byte, error) {
+	step, _ := s.StepScopedName().MarshalText()
+	typ, _ := s[1].MarshalText()
+
+	return []byte(fmt.Sprintf("%s[%s]", step, typ)), nil
 }
 
 // StepName returns the name of a step.
@@ -69,23 +186,95 @@ func StepName[S any](s Step[S]) fmt.Stringer {
 	case interface{ StepName() string }:
 		return fmtStr(s.StepName())
 	case StepFunc[S]:
-		pkgName, fnName := stepFuncName(s)
-
-		return ScopedName{pkgName, fnName}
+		return funcName(s)
 	}
 
 	return stepTypeName(s)
 }
 
+type renamedStep[S any] struct {
+	name fmt.Stringer
+	step Step[S]
+}
+
+var (
+	_ StepNamer         = (*renamedStep[any])(nil)
+	_ middlewareSkipper = (*renamedStep[any])(nil)
+	_ rebuilder[any]    = (*renamedStep[any])(nil)
+	_ wrapperStep[any]  = (*renamedStep[any])(nil)
+)
+
+func (s *renamedStep[S]) canSkip() bool { return canSkip(s.step) }
+
+func (s *renamedStep[S]) StepName() fmt.Stringer { return s.name }
+
+func (s *renamedStep[S]) Exec(ctx context.Context, state S) error { return s.step.Exec(ctx, state) }
+
+func (s *renamedStep[S]) Unwrap() Step[S] { return s.step }
+
+func (s *renamedStep[S]) wrapped() Step[S] { return s.step }
+
+// rebuild does not wrap the renamed Step itself, since middlewares
+// already see it through renamedStep.
+func (s *renamedStep[S]) rebuild(wrap wrapFunc[S]) Step[S] {
+	return &renamedStep[S]{name: s.name, step: rebuild(s.step, wrap)}
+}
+
+// Named overrides the name of the given Step. The name is used
+// everywhere StepName is, i.e. in middleware Info and errors.
+//
+// It is helpful to name anonymous functions, which otherwise show up
+// with runtime generated names like `TestExecutor_Use.func6.3`.
+func Named[S any](name string, step Step[S]) Step[S] {
+	return &renamedStep[S]{name: fmtStr(name), step: step}
+}
+
+// NewSelector creates a Selector with the given name. The name is
+// surfaced in Info.Selector of conditional Step(s) using it.
+func NewSelector[S any](name string, fn func(state S) bool) Selector[S] {
+	return newSelector(fn, selectorMeta{name: fmtStr(name)})
+}
+
+// NewSelectorCtx creates a SelectorCtx with the given name, like NewSelector.
+func NewSelectorCtx[S any](name string, fn func(ctx context.Context, state S) (bool, error)) SelectorCtx[S] {
+	h := &selectorCtxHolder[S]{fn: fn}
+	sel := SelectorCtx[S](func(ctx context.Context, state S) (bool, error) { return h.fn(ctx, state) })
+	selectors.register(funcValuePtr(sel), h, selectorMeta{name: fmtStr(name)})
+
+	return sel
+}
+
+// selectorCtxName returns the name of a SelectorCtx, like SelectorName.
+func selectorCtxName[S any](sel SelectorCtx[S]) fmt.Stringer {
+	if meta, ok := selectors.load(funcValuePtr(sel)); ok && meta.(selectorMeta).name != nil {
+		return meta.(selectorMeta).name
+	}
+
+	return funcName(sel)
+}
+
+// SelectorName returns the name of a Selector.
+//
+// It is the name given to NewSelector, otherwise the name of
+// the function is used as a ScopedName.
+func SelectorName[S any](sel Selector[S]) fmt.Stringer {
+	if meta, ok := selectors.load(funcValuePtr(sel)); ok && meta.(selectorMeta).name != nil {
+		return meta.(selectorMeta).name
+	}
+
+	return funcName(sel)
+}
+
 // private API
 
 const (
-	moduleNamedGroup  = "module"
-	pkgNamedGroup     = "pkg"
-	typeNamedGroup    = "type"
-	structNamedGroup  = "struct"
-	pointerNamedGroup = "pointer"
-	methodNamedGroup  = "method"
+	moduleNamedGroup   = "module"
+	pkgNamedGroup      = "pkg"
+	typeNamedGroup     = "type"
+	structNamedGroup   = "struct"
+	pointerNamedGroup  = "pointer"
+	methodNamedGroup   = "method"
+	typeArgsNamedGroup = "args"
 )
 
 var (
@@ -107,9 +296,9 @@ var (
 
 	runtimeStepNameExtractor        = regexp.MustCompile(fmt.Sprintf(`^%s$`, typeNameRegex))
 	runtimeGenericTypeNameExtractor = regexp.MustCompile(fmt.Sprintf(
-		`^(?P<%s>\w+)\[%s]$`,
+		`^(?P<%s>\w+)\[(?P<%s>.+)]$`,
 		structNamedGroup,
-		typeNameRegex,
+		typeArgsNamedGroup,
 	))
 	structMethodExtractor = regexp.MustCompile(fmt.Sprintf(
 		`^((\(\*(?P<%s>\w+)\))|(?P<%s>\w+))\.(?P<%s>\w+)-fm$`,
@@ -122,25 +311,223 @@ var (
 	stepPkgIndex    = runtimeStepNameExtractor.SubexpIndex(pkgNamedGroup)
 	stepFnIndex     = runtimeStepNameExtractor.SubexpIndex(typeNamedGroup)
 
-	structIndex        = runtimeGenericTypeNameExtractor.SubexpIndex(structNamedGroup)
-	genericModuleIndex = runtimeGenericTypeNameExtractor.SubexpIndex(moduleNamedGroup)
-	genericPkgIndex    = runtimeGenericTypeNameExtractor.SubexpIndex(pkgNamedGroup)
-	genericTypeIndex   = runtimeGenericTypeNameExtractor.SubexpIndex(typeNamedGroup)
+	structIndex   = runtimeGenericTypeNameExtractor.SubexpIndex(structNamedGroup)
+	typeArgsIndex = runtimeGenericTypeNameExtractor.SubexpIndex(typeArgsNamedGroup)
 
 	pointerIndex    = structMethodExtractor.SubexpIndex(pointerNamedGroup)
 	structNameIndex = structMethodExtractor.SubexpIndex(structNamedGroup)
 	methodNameIndex = structMethodExtractor.SubexpIndex(methodNamedGroup)
 )
 
+// uniqueNames returns the names of the distinct Step(s) nested within start,
+// named by namer, if any, keyed by stepIdentity, see WithUniqueNames.
+func uniqueNames[S any](start Step[S], namer func(Step[S]) fmt.Stringer) map[any]fmt.Stringer {
+	names := make(map[any]fmt.Stringer)
+	counts := make(map[string]int)
+
+	Walk(start, func(step Step[S], info Info, _ int) bool {
+		id := stepIdentity(step)
+		if _, found := names[id]; found {
+			return true
+		}
+
+		name := info.Name
+		if namer != nil {
+			name = namer(step)
+		}
+
+		counts[name.String()]++
+		if n := counts[name.String()]; n > 1 {
+			name = fmtStr(fmt.Sprintf("%s#%d", name, n))
+		}
+
+		names[id] = name
+
+		return true
+	})
+
+	return names
+}
+
+// stepIdentity returns a comparable value identifying the Step.
+func stepIdentity[S any](step Step[S]) any {
+	if fn, ok := step.(StepFunc[S]); ok {
+		return funcValuePtr(fn)
+	}
+
+	v := reflect.ValueOf(step)
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return [2]any{v.Type(), v.Pointer()}
+	}
+
+	if v.Comparable() {
+		return step
+	}
+
+	return new(byte) // can not be told apart from other Step(s) by identity
+}
+
+// selectors maps the closure of the Selector(s) created by NewSelector,
+// Always, Never and IfNot to their selectorMeta.
+var selectors funcRegistry
+
+// selectorMeta describes a Selector, see selectors.
+type selectorMeta struct {
+	// name is the name of the Selector, if any.
+	name fmt.Stringer
+	// constant reports whether the Selector always returns value.
+	constant, value bool
+}
+
+// selectorHolder is captured by the closure of a Selector
+// registered in selectors, see funcRegistry.register.
+type selectorHolder[S any] struct{ fn func(state S) bool }
+
+// selectorCtxHolder is the same as selectorHolder for a SelectorCtx.
+type selectorCtxHolder[S any] struct {
+	fn func(ctx context.Context, state S) (bool, error)
+}
+
+// newSelector returns a Selector calling fn, registered in selectors with meta.
+func newSelector[S any](fn func(state S) bool, meta selectorMeta) Selector[S] {
+	h := &selectorHolder[S]{fn: fn}
+	sel := Selector[S](func(state S) bool { return h.fn(state) })
+	selectors.register(funcValuePtr(sel), h, meta)
+
+	return sel
+}
+
+// funcRegistry maps closures to values, e.g. Selector(s) to their names.
+// Func values are not comparable, but each closure is a distinct allocation,
+// which makes its address a stable identity while it is reachable.
+//
+// The registry does not keep the closures reachable: each closure captures
+// a holder, which is only referenced by the closure, and the entry of the
+// closure is removed once its holder is garbage collected.
+type funcRegistry struct {
+	entries sync.Map // map[uintptr]*funcEntry
+}
+
+type funcEntry struct {
+	// code is the code pointer of the closure, it tells the closure apart
+	// from one allocated at the same address after it was collected,
+	// before the finalizer of its holder removed the entry.
+	code  uintptr
+	value any
+}
+
+// register maps the closure fn, as returned by funcValuePtr, to value.
+// The entry is removed when holder, which fn must capture, is collected.
+func (r *funcRegistry) register(fn unsafe.Pointer, holder, value any) {
+	key := uintptr(fn)
+	entry := &funcEntry{code: *(*uintptr)(fn), value: value}
+	r.entries.Store(key, entry)
+
+	runtime.SetFinalizer(holder, func(any) { r.entries.CompareAndDelete(key, entry) })
+}
+
+// load returns the value registered for the closure fn, if any.
+func (r *funcRegistry) load(fn unsafe.Pointer) (any, bool) {
+	if fn == nil {
+		return nil, false
+	}
+
+	v, ok := r.entries.Load(uintptr(fn))
+	if !ok {
+		return nil, false
+	}
+
+	entry := v.(*funcEntry)
+	if entry.code != *(*uintptr)(fn) {
+		return nil, false
+	}
+
+	return entry.value, true
+}
+
+// funcValuePtr returns the pointer to the closure of fn, which must be a func.
+func funcValuePtr[F any](fn F) unsafe.Pointer {
+	return *(*unsafe.Pointer)(unsafe.Pointer(&fn))
+}
+
 type fmtStr string
 
 func (f fmtStr) String() string { return string(f) }
 
-func stepFuncName[S any](s Step[S]) (string, string) {
+func (f fmtStr) Sanitized() string { return sanitize(string(f)) }
+
+// sanitizedName returns the Sanitized form of a Step name.
+func sanitizedName(name fmt.Stringer) string {
+	if s, ok := name.(interface{ Sanitized() string }); ok {
+		return s.Sanitized()
+	}
+
+	return sanitize(name.String())
+}
+
+// sanitize replaces every run of characters other than ASCII letters, digits
+// and underscores with a single underscore, or drops it at either end of the
+// name, e.g. dagger:*step.method becomes dagger_step_method. The result is
+// prefixed with an underscore if it starts with a digit, so that it is valid
+// as a Prometheus metric name.
+func sanitize(name string) string {
+	b := make([]byte, 0, len(name))
+	sep := false
+
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_':
+			if sep && len(b) > 0 {
+				b = append(b, '_')
+			}
+
+			b = append(b, c)
+			sep = false
+		default:
+			sep = true
+		}
+	}
+
+	if len(b) > 0 && b[0] >= '0' && b[0] <= '9' {
+		b = append([]byte{'_'}, b...)
+	}
+
+	return string(b)
+}
+
+// Names derived via reflection only depend on the code pointer of a func,
+// or the type of a Step, both are cached to keep regex matching out of
+// the hot path of Exec.
+var (
+	funcNameCache sync.Map // map[uintptr]fmt.Stringer
+	typeNameCache sync.Map // map[reflect.Type]fmt.Stringer
+)
+
+// funcName returns the ScopedName of the given func.
+func funcName(fn any) fmt.Stringer {
+	pc := reflect.ValueOf(fn).Pointer()
+
+	if name, ok := funcNameCache.Load(pc); ok {
+		return name.(fmt.Stringer)
+	}
+
+	pkgPath, fnName := parseFuncName(pc)
+
+	var name fmt.Stringer = ScopedName{pkgPath, fnName}
+	funcNameCache.Store(pc, name)
+
+	return name
+}
+
+func parseFuncName(pc uintptr) (string, string) {
 	pkgPath := "UnknownPackagePath"
 	fnName := "UnknownFunc"
 
-	if fnPtr := runtime.FuncForPC(reflect.ValueOf(s).Pointer()); fnPtr != nil {
+	if fnPtr := runtime.FuncForPC(pc); fnPtr != nil {
 		fullName := fnPtr.Name()
 
 		if matches := runtimeStepNameExtractor.FindStringSubmatch(fullName); len(matches) > 0 {
@@ -163,39 +550,99 @@ func stepFuncName[S any](s Step[S]) (string, string) {
 func stepTypeName[S any](s Step[S]) fmt.Stringer {
 	t := reflect.TypeOf(s)
 
+	if name, ok := typeNameCache.Load(t); ok {
+		return name.(fmt.Stringer)
+	}
+
+	name := parseTypeName(t)
+	typeNameCache.Store(t, name)
+
+	return name
+}
+
+func parseTypeName(t reflect.Type) fmt.Stringer {
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
 
 	if matches := runtimeGenericTypeNameExtractor.FindStringSubmatch(t.Name()); len(matches) > 0 {
-		isPtr := false
-		genModule := matches[genericModuleIndex]
-		if strings.HasPrefix(genModule, "*") {
-			genModule = genModule[1:]
-			isPtr = true
+		return GenericScopedName{
+			ScopedName{t.PkgPath(), matches[structIndex]}, // ScopedName for Step[S]
+			genericTypeArgs(matches[typeArgsIndex]),       // ScopedName for S
+		}
+	}
+
+	return ScopedName{t.PkgPath(), t.Name()}
+}
+
+// buildModules returns the paths of the modules in the build info
+// of the binary, longest first, so that nested modules match first.
+var buildModules = sync.OnceValue(func() []string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+
+	mods := []string{info.Main.Path}
+	for _, dep := range info.Deps {
+		mods = append(mods, dep.Path)
+	}
+
+	sort.Slice(mods, func(i, j int) bool { return len(mods[i]) > len(mods[j]) })
+
+	return mods
+})
+
+// modulePath returns the path of the module the package with the given path
+// belongs to. Packages of modules which are not in the build info are assumed
+// to belong to the module up to their major version suffix, or up to their
+// internal directory, and otherwise to the parent of the package.
+func modulePath(pkgPath string) string {
+	first, _, _ := strings.Cut(pkgPath, "/")
+	if !strings.Contains(first, ".") {
+		return "" // standard library
+	}
+
+	for _, mod := range buildModules() {
+		if mod != "" && (pkgPath == mod || strings.HasPrefix(pkgPath, mod+"/")) {
+			return mod
 		}
+	}
 
-		genPkg := matches[genericPkgIndex]
-		if strings.HasPrefix(genPkg, "*") {
-			genPkg = genPkg[1:]
-			isPtr = true
+	elems := strings.Split(pkgPath, "/")
+
+	for i := len(elems) - 1; i > 0; i-- {
+		if isMajorVersion(elems[i]) {
+			return strings.Join(elems[:i+1], "/")
 		}
+	}
 
-		genType := matches[genericTypeIndex]
-		if isPtr {
-			genType = "*" + genType
+	for i := 1; i < len(elems); i++ {
+		if elems[i] == "internal" {
+			return strings.Join(elems[:i], "/")
 		}
+	}
 
-		return GenericScopedName{
-			ScopedName{t.PkgPath(), matches[structIndex]}, // ScopedName for Step[S]
-			ScopedName{
-				fmtPkgPath(genModule, genPkg),
-				genType,
-			}, // ScopedName for S
+	if len(elems) == 1 {
+		return pkgPath
+	}
+
+	return strings.Join(elems[:len(elems)-1], "/")
+}
+
+// isMajorVersion reports whether elem is a major version suffix, e.g. v2.
+func isMajorVersion(elem string) bool {
+	if len(elem) < 2 || elem[0] != 'v' || elem[1] == '0' {
+		return false
+	}
+
+	for _, r := range elem[1:] {
+		if r < '0' || r > '9' {
+			return false
 		}
 	}
 
-	return ScopedName{t.PkgPath(), t.Name()}
+	return elem != "v1"
 }
 
 func fmtPkgPath(mod, pkg string) string {
@@ -209,3 +656,95 @@ func fmtPkgPath(mod, pkg string) string {
 
 	return fmt.Sprintf("%s/%s", mod, pkg)
 }
+
+// splitTypeArgs splits the type arguments reported by reflect, e.g.
+// "int,map[string]github.com/org/pkg.A", on the top level commas.
+func splitTypeArgs(s string) []string {
+	var (
+		args  []string
+		depth int
+		start int
+	)
+
+	for i, r := range s {
+		switch r {
+		case '[', '(', '{':
+			depth++
+		case ']', ')', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(args, s[start:])
+}
+
+// genericTypeArgs returns the second ScopedName of a GenericScopedName for
+// the type arguments reported by reflect. A single named type argument is
+// scoped to its package, if its ScopedName encodes it as is.
+func genericTypeArgs(typeArgs string) ScopedName {
+	if args := splitTypeArgs(typeArgs); len(args) == 1 {
+		name := typeArgScopedName(args[0])
+		if text, _ := name.MarshalText(); name.PackagePath() != "" && string(text) == args[0] {
+			return name
+		}
+	}
+
+	return ScopedName{"", typeArgs}
+}
+
+// typeArgScopedName returns the ScopedName of a single type argument, see
+// GenericScopedName.TypeScopedNames.
+func typeArgScopedName(arg string) ScopedName {
+	rest := strings.TrimLeft(arg, "*")
+	ptr := arg[:len(arg)-len(rest)]
+
+	head := rest
+	if i := strings.IndexAny(rest, "[({ "); i != -1 {
+		head = rest[:i]
+	}
+
+	switch head {
+	case "", "map", "func", "chan", "struct", "interface":
+		return ScopedName{"", ptr + unqualified(rest)}
+	}
+
+	pkgPath, name := "", head
+	if i := strings.LastIndex(head, "."); i != -1 {
+		pkgPath, name = head[:i], head[i+1:]
+	}
+
+	return ScopedName{pkgPath, ptr + name + unqualified(rest[len(head):])}
+}
+
+// unqualified strips the package qualifiers of the types in a type
+// reported by reflect, e.g. []github.com/org/pkg.A becomes []A.
+func unqualified(typ string) string {
+	var b strings.Builder
+
+	start := 0
+	flush := func(end int) {
+		ident := typ[start:end]
+		if i := strings.LastIndex(ident, "."); i != -1 {
+			ident = ident[i+1:]
+		}
+
+		b.WriteString(ident)
+	}
+
+	for i, r := range typ {
+		if strings.ContainsRune("[]*(){},; ", r) {
+			flush(i)
+			b.WriteRune(r)
+			start = i + 1
+		}
+	}
+
+	flush(len(typ))
+
+	return b.String()
+}
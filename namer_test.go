@@ -3,9 +3,13 @@ package dagger
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -63,6 +67,13 @@ func TestStepName(t *testing.T) {
 			step: func() Step[testState] { return &testStep{} },
 			want: "testStep",
 		},
+		{
+			name: "Named",
+			step: func() Step[testState] {
+				return Named("validate-quota", NewStep(func(_ context.Context, _ testState) error { return nil }))
+			},
+			want: "validate-quota",
+		},
 	}
 
 	for _, tc := range testcases {
@@ -76,7 +87,7 @@ type typedStep[S any] struct{}
 func (s *typedStep[S]) Exec(_ context.Context, _ S) error { return nil }
 
 func Test_stepTypeName(t *testing.T) {
-	thisModule := "github.com/ajatprabha"
+	thisModule := "github.com/ajatprabha/dagger"
 
 	t.Run("StdLibTypedStep", func(t *testing.T) {
 		s := StepName(&typedStep[int]{})
@@ -98,6 +109,7 @@ func Test_stepTypeName(t *testing.T) {
 		assert.Equal(t, thisModule, gsn.TypeScopedName().Module())
 		assert.Equal(t, "dagger", gsn.TypeScopedName().Package())
 		assert.Equal(t, "dagger:typedStep[testState]", s.String())
+		assert.Equal(t, GenericScopedName{{thisModule, "typedStep"}, {thisModule, "testState"}}, gsn)
 	})
 
 	t.Run("SamePackageTypedPointerStep", func(t *testing.T) {
@@ -134,6 +146,59 @@ func Test_stepTypeName(t *testing.T) {
 	})
 }
 
+type pairStep[A, B any] struct{}
+
+func (s *pairStep[A, B]) Exec(_ context.Context, _ testState) error { return nil }
+
+func Test_stepTypeName_TypeArgs(t *testing.T) {
+	testcases := []struct {
+		name  string
+		step  Step[testState]
+		want  string
+		types []ScopedName
+	}{
+		{
+			name:  "Multiple",
+			step:  &pairStep[int, *bytes.Buffer]{},
+			want:  "dagger:pairStep[int,*Buffer]",
+			types: []ScopedName{{"", "int"}, {"bytes", "*Buffer"}},
+		},
+		{
+			name:  "Map",
+			step:  &pairStep[map[string]*bytes.Buffer, testState]{},
+			want:  "dagger:pairStep[map[string]*Buffer,testState]",
+			types: []ScopedName{{"", "map[string]*Buffer"}, {"github.com/ajatprabha/dagger", "testState"}},
+		},
+		{
+			name: "Nested",
+			step: &pairStep[typedStep[*testState], []typedStep[int]]{},
+			want: "dagger:pairStep[typedStep[*testState],[]typedStep[int]]",
+			types: []ScopedName{
+				{"github.com/ajatprabha/dagger", "typedStep[*testState]"},
+				{"", "[]typedStep[int]"},
+			},
+		},
+		{
+			name:  "Func",
+			step:  &pairStep[func(context.Context) error, struct{ A, B int }]{},
+			want:  "dagger:pairStep[func(Context) error,struct { A int; B int }]",
+			types: []ScopedName{{"", "func(Context) error"}, {"", "struct { A int; B int }"}},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			gsn, ok := StepName(tc.step).(GenericScopedName)
+
+			assert.True(t, ok)
+			assert.Equal(t, tc.want, gsn.String())
+			assert.Equal(t, tc.types, gsn.TypeScopedNames())
+			assert.Equal(t, tc.types[0], gsn.TypeScopedName())
+			assert.Empty(t, gsn[1].PackagePath(), "several type arguments are kept as is")
+		})
+	}
+}
+
 type namedTypedStep[S any] struct{}
 
 func (s *namedTypedStep[S]) StepName() fmt.Stringer {
@@ -146,3 +211,329 @@ func TestStepNamer(t *testing.T) {
 	step := &namedTypedStep[int]{}
 	assert.Equal(t, "namedTypedStep[int]", StepName(step).String())
 }
+
+func TestNamed(t *testing.T) {
+	t.Run("MiddlewareInfo", func(t *testing.T) {
+		var infos []Info
+
+		chain := NewChain(func(next Step[testState], info Info) Step[testState] {
+			return NewStep(func(ctx context.Context, state testState) error {
+				infos = append(infos, info)
+				return next.Exec(ctx, state)
+			})
+		})
+
+		step := Series(
+			Named("validate", NewStep(func(_ context.Context, _ testState) error { return nil })),
+			Named("group", Series(NewStep(namedStep))),
+		)
+
+		err := chain.compile(step).Exec(context.TODO(), testState{})
+		assert.NoError(t, err)
+
+		names := make([]string, 0, len(infos))
+		for _, info := range infos {
+			names = append(names, info.Name.String())
+		}
+
+		assert.Equal(t, []string{"dagger:seriesStep[testState]", "validate", "group", "dagger:namedStep"}, names)
+		assert.False(t, infos[1].CanSkip)
+		assert.True(t, infos[2].CanSkip)
+	})
+
+	t.Run("CycleError", func(t *testing.T) {
+		s := &seriesStep[testState]{}
+		s.steps = []Step[testState]{Named("loop", Step[testState](s))}
+
+		errCycle := new(ErrCycle)
+		_, err := New(Named("root", Step[testState](s)))
+		assert.ErrorAs(t, err, &errCycle)
+		assert.Equal(t, "dagger:seriesStep[testState]", errCycle.stepName.String())
+	})
+}
+
+// shortNamer names Step(s) without their package, keeping the names given by Named.
+func shortNamer(step Step[testState]) fmt.Stringer {
+	switch name := StepName(step).(type) {
+	case ScopedName:
+		return fmtStr(name.Name())
+	case GenericScopedName:
+		return fmtStr(name.StepScopedName().Name())
+	default:
+		return name
+	}
+}
+
+func TestWithNamer(t *testing.T) {
+	t.Run("MiddlewareInfo", func(t *testing.T) {
+		var names []string
+
+		dag, err := New(Series(NewStep(namedStep), Named("publish", NewStep(noopStep))), WithNamer(shortNamer))
+		assert.NoError(t, err)
+
+		assert.NoError(t, dag.Use(func(next Step[testState], info Info) Step[testState] {
+			names = append(names, info.Name.String())
+			return next
+		}))
+
+		assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+		assert.Equal(t, []string{"namedStep", "publish", "seriesStep"}, names)
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		executed := false
+
+		dag, err := New[testState](Series(NewStep(namedStep)), WithNamer(shortNamer))
+		assert.NoError(t, err)
+
+		assert.NoError(t, dag.Replace("namedStep", NewStep(func(context.Context, testState) error {
+			executed = true
+			return nil
+		})))
+		assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+		assert.True(t, executed)
+	})
+
+	t.Run("Clone", func(t *testing.T) {
+		dag, err := New[testState](NewStep(namedStep), WithNamer(shortNamer))
+		assert.NoError(t, err)
+
+		var name fmt.Stringer
+
+		c := dag.WithAdditionalMiddleware(func(next Step[testState], info Info) Step[testState] {
+			name = info.Name
+			return next
+		})
+		assert.NoError(t, c.Exec(context.TODO(), testState{}))
+		assert.Equal(t, "namedStep", name.String())
+	})
+}
+
+func TestSelectorName(t *testing.T) {
+	t.Run("NewSelector", func(t *testing.T) {
+		quotaAvailable := NewSelector("quota-available", func(_ testState) bool { return true })
+		assert.Equal(t, "quota-available", SelectorName(quotaAvailable).String())
+		assert.True(t, quotaAvailable(testState{}))
+	})
+
+	t.Run("SameFuncDifferentNames", func(t *testing.T) {
+		s1 := NewSelector("s1", alwaysTrue)
+		s2 := NewSelector("s2", alwaysTrue)
+		assert.Equal(t, "s1", SelectorName(s1).String())
+		assert.Equal(t, "s2", SelectorName(s2).String())
+	})
+
+	t.Run("NamedFunction", func(t *testing.T) {
+		assert.Equal(t, "dagger:alwaysTrue", SelectorName[testState](alwaysTrue).String())
+	})
+
+	t.Run("Info", func(t *testing.T) {
+		quotaAvailable := NewSelector("quota-available", alwaysTrue)
+		noop := NewStep(func(_ context.Context, _ testState) error { return nil })
+
+		assert.Equal(t, "quota-available", stepInfo(If(quotaAvailable, noop)).Selector.String())
+		assert.Equal(t, "!quota-available", stepInfo(IfNot(quotaAvailable, noop)).Selector.String())
+		assert.Equal(t, "quota-available", stepInfo(IfElse(quotaAvailable, noop, noop)).Selector.String())
+		assert.Nil(t, stepInfo(Series(noop)).Selector)
+	})
+}
+
+func Test_funcRegistry(t *testing.T) {
+	countSelectors := func() (n int) {
+		selectors.entries.Range(func(_, _ any) bool {
+			n++
+			return true
+		})
+
+		return n
+	}
+
+	before := countSelectors()
+
+	func() {
+		for i := 0; i < 100; i++ {
+			sel := NewSelector(fmt.Sprintf("s%d", i), alwaysTrue)
+			assert.Equal(t, fmt.Sprintf("s%d", i), SelectorName(sel).String())
+		}
+	}()
+
+	assert.Eventually(t, func() bool {
+		runtime.GC()
+		return countSelectors() <= before
+	}, time.Second, 10*time.Millisecond)
+}
+
+func Test_nameCache(t *testing.T) {
+	t.Run("StepFunc", func(t *testing.T) {
+		step := NewStep(namedStep)
+		assert.Equal(t, "dagger:namedStep", StepName[testState](step).String())
+
+		cached, ok := funcNameCache.Load(reflect.ValueOf(step).Pointer())
+		assert.True(t, ok)
+		assert.Equal(t, ScopedName{"github.com/ajatprabha/dagger", "namedStep"}, cached)
+		assert.Equal(t, "dagger:namedStep", StepName[testState](step).String())
+	})
+
+	t.Run("Type", func(t *testing.T) {
+		step := &typedStep[int]{}
+		assert.Equal(t, "dagger:typedStep[int]", StepName[int](step).String())
+
+		cached, ok := typeNameCache.Load(reflect.TypeOf(step))
+		assert.True(t, ok)
+		assert.Equal(t, "dagger:typedStep[int]", cached.(fmt.Stringer).String())
+		assert.Equal(t, "dagger:typedStep[int]", StepName[int](step).String())
+	})
+}
+
+func TestScopedName_Marshal(t *testing.T) {
+	t.Run("ScopedName", func(t *testing.T) {
+		name := StepName[testState](NewStep(namedStep))
+
+		b, err := json.Marshal(name)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"module":"github.com/ajatprabha/dagger","package":"dagger","name":"namedStep"}`, string(b))
+
+		b, err = name.(ScopedName).MarshalText()
+		assert.NoError(t, err)
+		assert.Equal(t, "github.com/ajatprabha/dagger.namedStep", string(b))
+
+		b, err = ScopedName{"", "int"}.MarshalText()
+		assert.NoError(t, err)
+		assert.Equal(t, "int", string(b))
+	})
+
+	t.Run("GenericScopedName", func(t *testing.T) {
+		name := StepName(&typedStep[*bytes.Buffer]{})
+
+		b, err := json.Marshal(name)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{
+			"module": "github.com/ajatprabha/dagger",
+			"package": "dagger",
+			"name": "typedStep",
+			"type_params": [{"package": "bytes", "name": "*Buffer"}]
+		}`, string(b))
+
+		b, err = name.(GenericScopedName).MarshalText()
+		assert.NoError(t, err)
+		assert.Equal(t, "github.com/ajatprabha/dagger.typedStep[*bytes.Buffer]", string(b))
+	})
+
+	t.Run("MapKey", func(t *testing.T) {
+		b, err := json.Marshal(map[ScopedName]int{{"github.com/ajatprabha/dagger", "namedStep"}: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, `{"github.com/ajatprabha/dagger.namedStep":1}`, string(b))
+	})
+}
+
+func TestScopedName_Module(t *testing.T) {
+	testcases := []struct {
+		pkgPath string
+		module  string
+		pkg     string
+	}{
+		{pkgPath: "", module: "", pkg: ""},
+		{pkgPath: "strings", module: "", pkg: "strings"},
+		{pkgPath: "net/http", module: "", pkg: "http"},
+		{pkgPath: "github.com/ajatprabha/dagger", module: "github.com/ajatprabha/dagger", pkg: "dagger"},
+		{pkgPath: "github.com/ajatprabha/dagger/daggersql", module: "github.com/ajatprabha/dagger", pkg: "daggersql"},
+		{pkgPath: "github.com/stretchr/testify/assert", module: "github.com/stretchr/testify", pkg: "assert"},
+		{pkgPath: "gopkg.in/yaml.v3", module: "gopkg.in/yaml.v3", pkg: "yaml"},
+		{pkgPath: "github.com/org/lib/v2/steps", module: "github.com/org/lib/v2", pkg: "steps"},
+		{pkgPath: "github.com/org/lib/v2", module: "github.com/org/lib/v2", pkg: "lib"},
+		{pkgPath: "github.com/org/lib/v12/internal/steps", module: "github.com/org/lib/v12", pkg: "steps"},
+		{pkgPath: "github.com/org/lib/internal/steps", module: "github.com/org/lib", pkg: "steps"},
+		{pkgPath: "github.com/org/lib/steps", module: "github.com/org/lib", pkg: "steps"},
+		{pkgPath: "v2", module: "", pkg: "v2"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.pkgPath, func(t *testing.T) {
+			name := ScopedName{tc.pkgPath, "Step"}
+			assert.Equal(t, tc.module, name.Module())
+			assert.Equal(t, tc.pkg, name.Package())
+		})
+	}
+}
+
+func TestScopedName_Sanitized(t *testing.T) {
+	assert.Equal(t, "dagger_namedStep", StepName[testState](NewStep(namedStep)).(ScopedName).Sanitized())
+	assert.Equal(t, "dagger_unknownStep_internalStep1",
+		StepName[testState](NewStep((&unknownStep{}).internalStep1)).(ScopedName).Sanitized())
+	assert.Equal(t, "dagger_pairStep_map_string_Buffer_testState",
+		StepName[testState](&pairStep[map[string]*bytes.Buffer, testState]{}).(GenericScopedName).Sanitized())
+
+	testcases := map[string]string{
+		"":                  "",
+		"validate-quota":    "validate_quota",
+		"  create vm ":      "create_vm",
+		"_private__name_":   "_private__name_",
+		"2fa/verify":        "_2fa_verify",
+		"dagger:step.func1": "dagger_step_func1",
+	}
+
+	for name, want := range testcases {
+		assert.Equal(t, want, sanitizedName(fmtStr(name)), name)
+	}
+}
+
+func newCountingStep(counts map[string]int, key string) Step[testState] {
+	return NewStep(func(context.Context, testState) error {
+		counts[key]++
+		return nil
+	})
+}
+
+func TestWithUniqueNames(t *testing.T) {
+	counts := make(map[string]int)
+	shared := newCountingStep(counts, "shared")
+
+	step := Series(
+		newCountingStep(counts, "a"),
+		newCountingStep(counts, "b"),
+		shared,
+		Named("publish", NewStep(noopStep)),
+		shared,
+		Named("publish", NewStep(noopStep)),
+	)
+
+	var names []string
+
+	dag, err := New(step, WithUniqueNames[testState]())
+	assert.NoError(t, err)
+	assert.NoError(t, dag.Use(When(LeafOnly, func(next Step[testState], info Info) Step[testState] {
+		names = append(names, info.Name.String())
+		return next
+	})))
+
+	assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+	assert.Equal(t, []string{
+		"dagger:newCountingStep.func1",
+		"dagger:newCountingStep.func1#2",
+		"dagger:newCountingStep.func1#3",
+		"publish",
+		"dagger:newCountingStep.func1#3",
+		"publish#2",
+	}, names)
+	assert.Equal(t, map[string]int{"a": 1, "b": 1, "shared": 2}, counts)
+
+	t.Run("Replace", func(t *testing.T) {
+		dag, err := New(step, WithUniqueNames[testState]())
+		assert.NoError(t, err)
+		assert.NoError(t, dag.Replace("publish#2", NewStep(noopStep)))
+
+		names = nil
+
+		assert.NoError(t, dag.Use(When(LeafOnly, func(next Step[testState], info Info) Step[testState] {
+			names = append(names, info.Name.String())
+			return next
+		})))
+		assert.Equal(t, "dagger:noopStep", names[len(names)-1])
+	})
+
+	t.Run("WithoutUniqueNames", func(t *testing.T) {
+		dag, err := New(step)
+		assert.NoError(t, err)
+		assert.ErrorAs(t, dag.Replace("publish#2", NewStep(noopStep)), new(*ErrNoStep))
+	})
+}
@@ -0,0 +1,63 @@
+package dagger
+
+import "context"
+
+// NestingPolicy decides what an Executor executed by a Step of another
+// Executor, i.e. nested in it, sees of the context of the outer execution,
+// see WithNesting. It only applies to nested executions which are
+// instrumented, e.g. because they collect Results, or are part of a Run
+// or of a durable execution.
+type NestingPolicy int
+
+const (
+	// NestInherit shares the context of the outer execution as is, the
+	// Step(s) of the nested Executor are reported to the Results, the Run and
+	// the checkpoints of the outer execution, with their own Info.Path. In a
+	// durable execution, their Info.Path is scoped like with NestAppend, since
+	// their checkpoints would otherwise collide with the ones of the outer Step(s).
+	NestInherit NestingPolicy = iota
+	// NestReplace hides the values added to the context by the outer execution,
+	// except its run ID, so the nested Executor executes as if on its own.
+	NestReplace
+	// NestAppend shares the context of the outer execution like NestInherit,
+	// but scopes the Info.Path of the Step(s) of the nested Executor under the
+	// path of the outer Step executing it, e.g. root/1/root/0, which keeps
+	// their checkpoints apart from the ones of the outer Step(s).
+	NestAppend
+)
+
+// WithNesting sets the NestingPolicy of the Executor, NestInherit by default.
+func WithNesting[S any](policy NestingPolicy) ExecutorOption[S] {
+	return func(c *executorConfig[S]) { c.nesting = policy }
+}
+
+// nest returns the context for an execution with ctx, as per the
+// NestingPolicy if ctx belongs to a Step of another Executor.
+func (e *Executor[S]) nest(ctx context.Context) context.Context {
+	durable := ctx.Value(checkpointKey) != nil
+	if e.cfg.nesting == NestInherit && !durable {
+		return ctx
+	}
+
+	outer, ok := stepInfoFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	if e.cfg.nesting == NestReplace {
+		return isolatedContext{ctx}
+	}
+
+	return context.WithValue(ctx, pathPrefixKey, outer.Path)
+}
+
+// isolatedContext hides the values added by an execution, except its run ID and metadata.
+type isolatedContext struct{ context.Context }
+
+func (c isolatedContext) Value(key any) any {
+	if k, ok := key.(ctxKey); ok && k != runIDKey && k != runMetaKey {
+		return nil
+	}
+
+	return c.Context.Value(key)
+}
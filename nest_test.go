@@ -0,0 +1,83 @@
+package dagger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func doneStep(name string) Step[*durableState] {
+	return Named(name, NewStep(func(_ context.Context, state *durableState) error {
+		state.Done = append(state.Done, name)
+		return nil
+	}))
+}
+
+func TestWithNesting(t *testing.T) {
+	testcases := []struct {
+		name      string
+		policy    NestingPolicy
+		done      []string
+		completed []string
+	}{
+		{
+			name:      "Inherit",
+			policy:    NestInherit,
+			done:      []string{"a", "x", "y"},
+			completed: []string{"root/0", "root/1", "root/1/root/0", "root/1/root/1"},
+		},
+		{
+			name:      "Replace",
+			policy:    NestReplace,
+			done:      []string{"a", "x", "y"},
+			completed: []string{"root/0", "root/1"},
+		},
+		{
+			name:      "Append",
+			policy:    NestAppend,
+			done:      []string{"a", "x", "y"},
+			completed: []string{"root/0", "root/1", "root/1/root/0", "root/1/root/1"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			inner, err := New(Series(doneStep("x"), doneStep("y")), WithNesting[*durableState](tc.policy))
+			assert.NoError(t, err)
+
+			outer, err := New(Series(
+				doneStep("a"),
+				Step[*durableState](inner),
+				NewStep(func(context.Context, *durableState) error { return testErrStep }),
+			))
+			assert.NoError(t, err)
+
+			store := NewMemoryStore[*durableState]()
+			state := &durableState{}
+
+			assert.ErrorIs(t, outer.ExecDurable(context.TODO(), "run", state, store), testErrStep)
+			assert.Equal(t, tc.done, state.Done)
+
+			cp, err := store.Load(context.TODO(), "run")
+			assert.NoError(t, err)
+			assert.Equal(t, tc.completed, cp.Completed)
+		})
+	}
+
+	t.Run("NotNested", func(t *testing.T) {
+		var runID string
+
+		dag, err := New[*durableState](NewStep(func(ctx context.Context, _ *durableState) error {
+			runID, _ = RunIDFromContext(ctx)
+			_, ok := stepInfoFromContext(ctx)
+			assert.True(t, ok)
+
+			return nil
+		}), WithNesting[*durableState](NestReplace))
+		assert.NoError(t, err)
+
+		assert.NoError(t, dag.ExecAsync(context.TODO(), &durableState{}).Wait())
+		assert.NotEmpty(t, runID)
+	})
+}
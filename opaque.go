@@ -0,0 +1,39 @@
+package dagger
+
+import (
+	"context"
+	"fmt"
+)
+
+type opaqueStep[S any] struct {
+	step Step[S]
+}
+
+var (
+	_ StepNamer         = (*opaqueStep[any])(nil)
+	_ middlewareSkipper = (*opaqueStep[any])(nil)
+	_ wrapperStep[any]  = (*opaqueStep[any])(nil)
+)
+
+func (s *opaqueStep[S]) StepName() fmt.Stringer { return StepName(s.step) }
+
+// canSkip is always false, since middlewares can not reach the nested Step(s).
+func (s *opaqueStep[S]) canSkip() bool { return false }
+
+func (s *opaqueStep[S]) Exec(ctx context.Context, state S) error { return s.step.Exec(ctx, state) }
+
+func (s *opaqueStep[S]) Unwrap() Step[S] { return s.step }
+
+func (s *opaqueStep[S]) wrapped() Step[S] { return s.step }
+
+// NoMiddleware stops the middlewares of an Executor from propagating below the
+// Step, e.g. for a tight loop of many small Step(s) which should not pay for
+// tracing each of them. Middlewares see the Step as a single leaf Step, which
+// they wrap as a whole, while the Step(s) nested within it are left as is.
+//
+// The nested Step(s) are still part of the DAG for validation, Walk and
+// Document, but Replace and Optimize do not reach them, and ExecDurable
+// checkpoints the Step as a whole.
+func NoMiddleware[S any](step Step[S]) Step[S] {
+	return &opaqueStep[S]{step: step}
+}
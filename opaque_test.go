@@ -0,0 +1,39 @@
+package dagger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoMiddleware(t *testing.T) {
+	var wrapped []string
+
+	dag, err := New(Series(
+		NewStep(noopStep),
+		Named("loop", NoMiddleware(Series(NewStep(noopStep), If(alwaysTrue, NewStep(noopStep))))),
+	))
+	assert.NoError(t, err)
+
+	assert.NoError(t, dag.Use(func(next Step[testState], info Info) Step[testState] {
+		wrapped = append(wrapped, info.Path+" "+info.Name.String())
+		return next
+	}))
+
+	assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+	assert.Equal(t, []string{"root/0 dagger:noopStep", "root/1 loop", "root dagger:seriesStep[testState]"}, wrapped)
+
+	info := stepInfo(NoMiddleware[testState](Series(NewStep(noopStep))))
+	assert.False(t, info.CanSkip)
+	assert.Equal(t, "dagger:seriesStep[testState]", info.Name.String())
+
+	var walked []string
+
+	Walk(dag.start, func(_ Step[testState], info Info, _ int) bool {
+		walked = append(walked, info.Path)
+		return true
+	})
+
+	assert.Equal(t, []string{"root", "root/0", "root/1", "root/1/0", "root/1/1", "root/1/1/then"}, walked)
+}
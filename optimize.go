@@ -0,0 +1,133 @@
+package dagger
+
+// Always returns a Selector which always returns true. Optimize
+// folds the conditional Step(s) using it, e.g. in generated DAG(s).
+func Always[S any]() Selector[S] {
+	return newSelector(func(S) bool { return true }, selectorMeta{name: fmtStr("always"), constant: true, value: true})
+}
+
+// Never returns a Selector which always returns false. Optimize
+// folds the conditional Step(s) using it, e.g. in generated DAG(s).
+func Never[S any]() Selector[S] {
+	return newSelector(func(S) bool { return false }, selectorMeta{name: fmtStr("never"), constant: true})
+}
+
+// constSelector returns the value of a Selector created by Always or Never.
+func constSelector[S any](sel Selector[S]) (value, ok bool) {
+	meta, ok := selectors.load(funcValuePtr(sel))
+	if !ok || !meta.(selectorMeta).constant {
+		return false, false
+	}
+
+	return meta.(selectorMeta).value, true
+}
+
+// Optimize returns an equivalent DAG, with less Step(s) for the middlewares
+// to wrap, which is useful for generated DAG(s):
+//   - Series nested in a Series are flattened
+//   - Series, Parallel and Continue without any Step(s) are removed,
+//     and a Series of a single Step is replaced by the Step
+//   - If, IfNot and IfElse using Always or Never are replaced
+//     by the Step they would execute, if any
+//
+// Step(s) decorated by Named, WithLabels, and similar, are kept as is,
+// since their name and decorations are visible to middlewares, only
+// the Step(s) nested within them are optimized, one by one. Those which
+// do nothing are replaced by an empty Series, rather than removed.
+func Optimize[S any](step Step[S]) Step[S] {
+	if optimized := optimize(step); optimized != nil {
+		return optimized
+	}
+
+	return Series[S]()
+}
+
+// optimize returns the optimized Step, or nil if the Step does nothing.
+func optimize[S any](step Step[S]) Step[S] {
+	switch s := step.(type) {
+	case *seriesStep[S]:
+		steps := optimizeEach(s.steps, true)
+
+		switch len(steps) {
+		case 0:
+			return nil
+		case 1:
+			return steps[0]
+		}
+
+		return &seriesStep[S]{steps: steps}
+	case *parallelStep[S]:
+		if steps := optimizeEach(s.steps, false); len(steps) > 0 {
+			return newParallelStep(steps, s.cfg)
+		}
+
+		return nil
+	case *continueStep[S]:
+		if steps := optimizeEach(s.steps, false); len(steps) > 0 {
+			return &continueStep[S]{steps: steps, cfg: s.cfg}
+		}
+
+		return nil
+	case *ifStep[S]:
+		if v, ok := constSelector(s.condition); ok {
+			if v {
+				return optimize(s.thenStep)
+			}
+
+			return nil
+		}
+
+		if then := optimize(s.thenStep); then != nil {
+			return &ifStep[S]{condition: s.condition, conditionCtx: s.conditionCtx, name: s.name, thenStep: then}
+		}
+
+		return nil
+	case *ifElseStep[S]:
+		if v, ok := constSelector(s.condition); ok {
+			if v {
+				return optimize(s.thenStep)
+			}
+
+			return optimize(s.elseStep)
+		}
+
+		return &ifElseStep[S]{
+			condition:    s.condition,
+			conditionCtx: s.conditionCtx,
+			name:         s.name,
+			thenStep:     Optimize(s.thenStep),
+			elseStep:     Optimize(s.elseStep),
+		}
+	}
+
+	return rebuild(step, func(edge string, child Step[S]) Step[S] {
+		// The failure Step(s) are returned at runtime, they are not part of the DAG.
+		if edge == failureEdge {
+			return child
+		}
+
+		return Optimize(child)
+	})
+}
+
+// optimizeEach optimizes the steps, leaving out the ones which do nothing,
+// and flattening the Series among them, if flatten is set.
+func optimizeEach[S any](steps []Step[S], flatten bool) []Step[S] {
+	optimized := make([]Step[S], 0, len(steps))
+
+	for _, step := range steps {
+		step = optimize(step)
+		if step == nil {
+			continue
+		}
+
+		if series, ok := step.(*seriesStep[S]); ok && flatten {
+			optimized = append(optimized, series.steps...)
+			continue
+		}
+
+		optimized = append(optimized, step)
+	}
+
+	return optimized
+}
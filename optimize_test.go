@@ -0,0 +1,85 @@
+package dagger
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptimize(t *testing.T) {
+	step := func(name string) Step[testState] { return Named(name, NewStep(noopStep)) }
+
+	structure := func(step Step[testState]) []string {
+		var visited []string
+
+		Walk(step, func(_ Step[testState], info Info, depth int) bool {
+			visited = append(visited, strings.Repeat("-", depth)+info.Name.String())
+			return true
+		})
+
+		return visited
+	}
+
+	testcases := []struct {
+		name string
+		step Step[testState]
+		want []string
+	}{
+		{
+			name: "FlattenSeries",
+			step: Series(Series(step("a"), Series(step("b"), step("c"))), step("d")),
+			want: []string{"dagger:seriesStep[testState]", "-a", "-b", "-c", "-d"},
+		},
+		{
+			name: "RemoveEmpty",
+			step: Series(step("a"), Series[testState](), Parallel[testState](), Continue(Series[testState]())),
+			want: []string{"a"},
+		},
+		{
+			name: "FoldConstants",
+			step: Parallel(
+				If(Always[testState](), step("a")),
+				If(Never[testState](), step("b")),
+				IfNot(Never[testState](), step("c")),
+				IfElse(Never[testState](), step("d"), step("e")),
+				If(alwaysTrue, Series(step("f"))),
+			),
+			want: []string{"dagger:parallelStep[testState]", "-a", "-c", "-e", "-dagger:ifStep[testState]", "--f"},
+		},
+		{
+			name: "KeepDecorated",
+			step: Named("group", Series(Series(step("a")), If(Never[testState](), step("b")))),
+			want: []string{"group", "-a", "-dagger:seriesStep[testState]"},
+		},
+		{
+			name: "Empty",
+			step: If(Never[testState](), step("a")),
+			want: []string{"dagger:seriesStep[testState]"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			optimized := Optimize(tc.step)
+			assert.Equal(t, tc.want, structure(optimized))
+
+			_, err := New(optimized)
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("FailureStep", func(t *testing.T) {
+		failureStep := Series(step("delete"))
+
+		optimized, ok := Optimize(OnFailure(step("create"), func(context.Context, testState, error) Step[testState] {
+			return failureStep
+		})).(*resultStep[testState])
+		assert.True(t, ok)
+		assert.Equal(t, failureStep, optimized.wrapFailure(failureStep), "the failure step is not optimized")
+	})
+
+	assert.Equal(t, "always", SelectorName(Always[testState]()).String())
+	assert.Equal(t, "never", SelectorName(Never[testState]()).String())
+}
@@ -0,0 +1,161 @@
+package dagger
+
+// OrderOption positions a middleware created by OrderedMiddleware
+// relative to the other middlewares of a chain.
+type OrderOption func(*ordering)
+
+type ordering struct {
+	name     string
+	priority int
+	before   []string
+	after    []string
+}
+
+// Before places the middleware before, i.e. outside, the middlewares with the given names.
+func Before(names ...string) OrderOption {
+	return func(o *ordering) { o.before = append(o.before, names...) }
+}
+
+// After places the middleware after, i.e. inside, the middlewares with the given names.
+func After(names ...string) OrderOption {
+	return func(o *ordering) { o.after = append(o.after, names...) }
+}
+
+// Priority places the middleware before the middlewares with a lower priority,
+// unless Before or After say otherwise. Middlewares have a priority of 0 by default.
+func Priority(p int) OrderOption {
+	return func(o *ordering) { o.priority = p }
+}
+
+// Ordered is a MiddlewareFunc named and positioned relative to the other
+// middlewares of a chain, it is created by OrderedMiddleware, and added
+// with Executor.UseOrdered or NewOrderedChain.
+type Ordered[S any] struct {
+	mw    MiddlewareFunc[S]
+	order ordering
+}
+
+func (o Ordered[S]) apply(next Step[S], info Info) Step[S] { return o.mw(next, info) }
+
+// OrderedMiddleware names the given MiddlewareFunc and positions it using the
+// given OrderOption(s), which lets independent packages contribute middlewares
+// to an Executor in a deterministic order, regardless of the order of the calls
+// to Executor.Use and Executor.UseOrdered.
+//
+// The order is resolved by NewOrderedChain and the Executor, middlewares without
+// any constraints keep their registration order. Constraints referring to unknown
+// names are ignored, and so are the ones conflicting with each other.
+//
+// To apply an ordered middleware to some Step(s) only, wrap mw with When.
+func OrderedMiddleware[S any](name string, mw MiddlewareFunc[S], opts ...OrderOption) Ordered[S] {
+	o := ordering{name: name}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return Ordered[S]{mw: mw, order: o}
+}
+
+// NewOrderedChain creates a MiddlewareChain of the given Ordered
+// middlewares, ordered as described by OrderedMiddleware.
+func NewOrderedChain[S any](mws ...Ordered[S]) MiddlewareChain[S] {
+	mwc := make(MiddlewareChain[S], len(mws))
+
+	for i, mw := range mws {
+		mwc[i] = mw
+	}
+
+	return mwc.sorted()
+}
+
+func orderingOf[S any](mw middleware[S]) ordering {
+	if o, ok := mw.(Ordered[S]); ok {
+		return o.order
+	}
+
+	return ordering{}
+}
+
+// sorted returns a copy of the chain ordered by the OrderOption(s) of its
+// middlewares. It sorts topologically, picking the middleware with the highest
+// priority, then the lowest index among the ready ones. Conflicting constraints
+// are broken by picking the remaining middleware with the lowest index.
+func (mwc MiddlewareChain[S]) sorted() MiddlewareChain[S] {
+	orders := make([]ordering, len(mwc))
+	ordered := false
+
+	for i, mw := range mwc {
+		orders[i] = orderingOf(mw)
+		ordered = ordered || orders[i].name != ""
+	}
+
+	if !ordered {
+		return append(MiddlewareChain[S](nil), mwc...)
+	}
+
+	indexes := make(map[string][]int)
+	for i, o := range orders {
+		if o.name != "" {
+			indexes[o.name] = append(indexes[o.name], i)
+		}
+	}
+
+	// dependsOn[i] holds the middlewares which must be placed before i.
+	dependsOn := make([]map[int]struct{}, len(mwc))
+	for i := range dependsOn {
+		dependsOn[i] = make(map[int]struct{})
+	}
+
+	for i, o := range orders {
+		for _, name := range o.before {
+			for _, j := range indexes[name] {
+				if j != i {
+					dependsOn[j][i] = struct{}{}
+				}
+			}
+		}
+
+		for _, name := range o.after {
+			for _, j := range indexes[name] {
+				if j != i {
+					dependsOn[i][j] = struct{}{}
+				}
+			}
+		}
+	}
+
+	placed := make([]bool, len(mwc))
+	res := make(MiddlewareChain[S], 0, len(mwc))
+
+	for len(res) < len(mwc) {
+		next := -1
+
+		for i := range mwc {
+			if placed[i] || len(dependsOn[i]) > 0 {
+				continue
+			}
+
+			if next == -1 || orders[i].priority > orders[next].priority {
+				next = i
+			}
+		}
+
+		if next == -1 {
+			for i := range mwc {
+				if !placed[i] {
+					next = i
+					break
+				}
+			}
+		}
+
+		placed[next] = true
+		res = append(res, mwc[next])
+
+		for i := range dependsOn {
+			delete(dependsOn[i], next)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,124 @@
+package dagger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderedMiddleware(t *testing.T) {
+	var order []string
+
+	record := func(name string) MiddlewareFunc[testState] {
+		return func(next Step[testState], info Info) Step[testState] {
+			return NewStep(func(ctx context.Context, state testState) error {
+				order = append(order, name)
+				return next.Exec(ctx, state)
+			})
+		}
+	}
+
+	testcases := []struct {
+		name string
+		mws  func() []middleware[testState]
+		want []string
+	}{
+		{
+			name: "RegistrationOrder",
+			mws: func() []middleware[testState] {
+				return []middleware[testState]{record("a"), record("b"), record("c")}
+			},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name: "BeforeAndAfter",
+			mws: func() []middleware[testState] {
+				return []middleware[testState]{
+					OrderedMiddleware("audit", record("audit"), After("metrics")),
+					OrderedMiddleware("metrics", record("metrics")),
+					OrderedMiddleware("tracing", record("tracing"), Before("metrics")),
+				}
+			},
+			want: []string{"tracing", "metrics", "audit"},
+		},
+		{
+			name: "Priority",
+			mws: func() []middleware[testState] {
+				return []middleware[testState]{
+					record("plain"),
+					OrderedMiddleware("recover", record("recover"), Priority(10)),
+					OrderedMiddleware("last", record("last"), Priority(-1)),
+				}
+			},
+			want: []string{"recover", "plain", "last"},
+		},
+		{
+			name: "UnknownName",
+			mws: func() []middleware[testState] {
+				return []middleware[testState]{
+					record("a"),
+					OrderedMiddleware("b", record("b"), Before("unknown")),
+				}
+			},
+			want: []string{"a", "b"},
+		},
+		{
+			name: "Conflict",
+			mws: func() []middleware[testState] {
+				return []middleware[testState]{
+					OrderedMiddleware("a", record("a"), After("b")),
+					OrderedMiddleware("b", record("b"), After("a")),
+					record("c"),
+				}
+			},
+			want: []string{"c", "a", "b"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			dag, err := New[testState](NewStep(noopStep))
+			assert.NoError(t, err)
+
+			// middlewares are registered one by one, like independent packages would
+			for _, mw := range tc.mws() {
+				switch mw := mw.(type) {
+				case MiddlewareFunc[testState]:
+					assert.NoError(t, dag.Use(mw))
+				case Ordered[testState]:
+					assert.NoError(t, dag.UseOrdered(mw))
+				}
+			}
+
+			order = nil
+			assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+			assert.Equal(t, tc.want, order)
+
+			order = nil
+			assert.NoError(t, MiddlewareChain[testState](tc.mws()).sorted().compile(NewStep(noopStep)).Exec(context.TODO(), testState{}))
+			assert.Equal(t, tc.want, order)
+		})
+	}
+}
+
+func TestNewOrderedChain(t *testing.T) {
+	var order []string
+
+	record := func(name string) MiddlewareFunc[testState] {
+		return func(next Step[testState], info Info) Step[testState] {
+			return NewStep(func(ctx context.Context, state testState) error {
+				order = append(order, name)
+				return next.Exec(ctx, state)
+			})
+		}
+	}
+
+	chain := NewOrderedChain(
+		OrderedMiddleware("audit", When(LeafOnly, record("audit")), After("metrics")),
+		OrderedMiddleware("metrics", record("metrics")),
+	)
+
+	assert.NoError(t, chain.compile(Series(NewStep(noopStep))).Exec(context.TODO(), testState{}))
+	assert.Equal(t, []string{"metrics", "metrics", "audit"}, order)
+}
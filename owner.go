@@ -0,0 +1,87 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type ownedStep[S any] struct {
+	step  Step[S]
+	owner string
+}
+
+var (
+	_ StepNamer         = (*ownedStep[any])(nil)
+	_ middlewareSkipper = (*ownedStep[any])(nil)
+	_ rebuilder[any]    = (*ownedStep[any])(nil)
+	_ wrapperStep[any]  = (*ownedStep[any])(nil)
+)
+
+func (s *ownedStep[S]) StepName() fmt.Stringer { return StepName(s.step) }
+
+func (s *ownedStep[S]) canSkip() bool { return canSkip(s.step) }
+
+func (s *ownedStep[S]) Exec(ctx context.Context, state S) error {
+	err := s.step.Exec(ctx, state)
+	if outcome(err) != "failure" {
+		return err
+	}
+
+	var owned *ErrOwned
+	if errors.As(err, &owned) {
+		return err
+	}
+
+	return &ErrOwned{stepName: StepName(s.step), owner: s.owner, err: err}
+}
+
+func (s *ownedStep[S]) Unwrap() Step[S] { return s.step }
+
+func (s *ownedStep[S]) wrapped() Step[S] { return s.step }
+
+func (s *ownedStep[S]) rebuild(wrap wrapFunc[S]) Step[S] {
+	return &ownedStep[S]{step: rebuild(s.step, wrap), owner: s.owner}
+}
+
+// WithOwner attaches the owner of the Step, e.g. the team responsible
+// for it, so that its failures can be routed to them. Failures of the
+// Step, and of every Step nested within it, are wrapped in an ErrOwned,
+// see OwnerOf. The owner is available to middlewares in Info.Owner, and
+// is included in the AuditRecord(s) of AuditLog.
+//
+// The innermost owner wins, both for Step(s) nested within owned Step(s)
+// and for nested calls on the same Step.
+func WithOwner[S any](step Step[S], owner string) Step[S] {
+	return &ownedStep[S]{step: step, owner: owner}
+}
+
+// OwnerOf returns the owner of the Step which failed with err, set with
+// WithOwner, it reports false if the Step is not owned.
+func OwnerOf(err error) (string, bool) {
+	var owned *ErrOwned
+	if !errors.As(err, &owned) {
+		return "", false
+	}
+
+	return owned.owner, true
+}
+
+// stepOwner returns the owner of the Step, looking through
+// the wrapperStep(s) decorating it, the innermost owner wins.
+func stepOwner[S any](step Step[S]) string {
+	var owner string
+
+	for {
+		if o, ok := step.(*ownedStep[S]); ok {
+			owner = o.owner
+		}
+
+		w, ok := step.(wrapperStep[S])
+		if !ok {
+			return owner
+		}
+
+		step = w.wrapped()
+	}
+}
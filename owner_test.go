@@ -0,0 +1,79 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithOwner(t *testing.T) {
+	create := Named("create", NewStep(func(ctx context.Context, state testState) error { return testErrStep }))
+	notify := Named("notify", NewStep(func(ctx context.Context, state testState) error { return nil }))
+	skip := Named("skip", NewStep(func(ctx context.Context, state testState) error { return &ErrSkip{} }))
+
+	t.Run("Errors", func(t *testing.T) {
+		err := WithOwner(create, "team-compute").Exec(context.TODO(), testState{})
+		assert.EqualError(t, err, "dagger: step 'create' (owner team-compute) failed: step error")
+		assert.True(t, errors.Is(err, testErrStep))
+
+		owner, ok := OwnerOf(err)
+		assert.True(t, ok)
+		assert.Equal(t, "team-compute", owner)
+
+		err = WithOwner(Series(notify, WithOwner(create, "team-compute")), "team-platform").Exec(context.TODO(), testState{})
+		owner, _ = OwnerOf(err)
+		assert.Equal(t, "team-compute", owner, "innermost owner wins")
+
+		err = WithOwner(Series(notify, create), "team-platform").Exec(context.TODO(), testState{})
+		owner, _ = OwnerOf(err)
+		assert.Equal(t, "team-platform", owner)
+
+		assert.NoError(t, WithOwner(notify, "team-compute").Exec(context.TODO(), testState{}))
+		assert.Equal(t, &ErrSkip{}, WithOwner(skip, "team-compute").Exec(context.TODO(), testState{}))
+
+		_, ok = OwnerOf(testErrStep)
+		assert.False(t, ok)
+	})
+
+	t.Run("Info", func(t *testing.T) {
+		owners := map[string]string{}
+
+		dag, err := New(WithOwner(Series(
+			notify,
+			WithOwner(WithOwner(create, "team-compute"), "team-storage"),
+		), "team-platform"))
+		assert.NoError(t, err)
+		assert.NoError(t, dag.Use(func(next Step[testState], info Info) Step[testState] {
+			owners[info.Path] = info.Owner
+			return next
+		}))
+
+		var records []AuditRecord
+		assert.NoError(t, dag.Use(AuditLog[testState](func(record AuditRecord) { records = append(records, record) })))
+
+		err = dag.Exec(context.TODO(), testState{})
+		owner, _ := OwnerOf(err)
+		assert.Equal(t, "team-compute", owner)
+
+		assert.Equal(t, map[string]string{
+			"root": "team-platform", "root/0": "team-platform", "root/1": "team-compute",
+		}, owners)
+
+		walked := map[string]string{}
+		Walk(dag.start, func(_ Step[testState], info Info, _ int) bool {
+			walked[info.Path] = info.Owner
+			return true
+		})
+		assert.Equal(t, owners, walked, "Walk reports the inherited owners too")
+
+		assert.Len(t, records, 2)
+		assert.Equal(t, "team-platform", records[0].Owner)
+		assert.Equal(t, "team-compute", records[1].Owner)
+
+		doc, err := Document(WithOwner(create, "team-compute"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(doc), "- **create** owned by `team-compute`\n")
+	})
+}
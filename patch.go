@@ -0,0 +1,100 @@
+package dagger
+
+import "context"
+
+// Append adds the given Step(s) at the end of the DAG, to the root Series
+// if the DAG is one, or to a new Series holding the DAG otherwise.
+// The DAG is validated, and new SetupStep(s) are set up, as by New.
+//
+// It returns ErrFrozen if the Executor has already executed, ErrInvalid if
+// the new DAG is invalid, and ErrSetup if a new Step failed to set up, in all
+// cases the Executor is left as is.
+func (e *Executor[S]) Append(steps ...Step[S]) error {
+	return e.patch(func(start Step[S]) (Step[S], error) {
+		if s, ok := start.(*seriesStep[S]); ok {
+			return Series(append(append([]Step[S](nil), s.steps...), steps...)...), nil
+		}
+
+		return Series(append([]Step[S]{start}, steps...)...), nil
+	})
+}
+
+// Replace replaces every Step named name in the DAG with step, e.g. to stub
+// a single Step of a DAG defined by production code in tests. The DAG is
+// validated again, as by New. Step(s) returned by a StepErrorHandler at
+// runtime are not part of the DAG, and can not be replaced. New SetupStep(s)
+// are set up, while the replaced Step(s) are only torn down by Close.
+//
+// It returns ErrNoStep if no Step is named name, ErrFrozen if the Executor has
+// already executed, ErrInvalid if the new DAG is invalid, and ErrSetup if step
+// failed to set up, in all cases the Executor is left as is.
+func (e *Executor[S]) Replace(name string, step Step[S]) error {
+	return e.patch(func(start Step[S]) (Step[S], error) {
+		replaced := false
+
+		var replace func(s Step[S]) Step[S]
+		replace = func(s Step[S]) Step[S] {
+			if e.stepName(s).String() == name {
+				replaced = true
+				return step
+			}
+
+			return rebuild(s, func(edge string, child Step[S]) Step[S] {
+				// The failure Step(s) are returned at runtime, they are not part of the DAG.
+				if edge == failureEdge {
+					return child
+				}
+
+				return replace(child)
+			})
+		}
+
+		start = replace(start)
+		if !replaced {
+			return nil, &ErrNoStep{name: name}
+		}
+
+		return start, nil
+	})
+}
+
+// patch replaces the DAG with the one returned by fn, once it is validated.
+func (e *Executor[S]) patch(fn func(start Step[S]) (Step[S], error)) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.frozen.Load() {
+		return &ErrFrozen{}
+	}
+
+	start, err := fn(e.start)
+	if err != nil {
+		return err
+	}
+
+	if err := checkDAGCycles(start); err != nil {
+		return &ErrInvalid{err: err}
+	}
+
+	if err := checkDataDependencies(start); err != nil {
+		return &ErrInvalid{err: err}
+	}
+
+	if err := checkFailureBranches(start); err != nil {
+		return &ErrInvalid{err: err}
+	}
+
+	if err := e.lifecycle.setup(context.Background(), start); err != nil {
+		return err
+	}
+
+	e.start = start
+
+	if e.cfg.uniqueNames {
+		e.names = uniqueNames(e.start, e.cfg.namer)
+	}
+
+	e.compiled = e.build(e.middlewares.sorted())
+
+	return nil
+}
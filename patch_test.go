@@ -0,0 +1,85 @@
+package dagger
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutor_Append(t *testing.T) {
+	var executed []string
+
+	step := func(name string) Step[testState] {
+		return Named(name, NewStep(func(ctx context.Context, _ testState) error {
+			executed = append(executed, name)
+			return nil
+		}))
+	}
+
+	testcases := []struct {
+		name  string
+		start Step[testState]
+	}{
+		{name: "Series", start: Series(step("create"), step("attach"))},
+		{name: "Other", start: Named("create-attach", Series(step("create"), step("attach")))},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			executed = nil
+
+			dag, err := New(tc.start)
+			assert.NoError(t, err)
+			assert.NoError(t, dag.Use(testLogMiddleware[testState](io.Discard, "log")))
+
+			assert.NoError(t, dag.Append(step("publish")))
+			assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+			assert.Equal(t, []string{"create", "attach", "publish"}, executed)
+
+			errFrozen := new(ErrFrozen)
+			assert.ErrorAs(t, dag.Append(step("notify")), &errFrozen)
+		})
+	}
+}
+
+func TestExecutor_Replace(t *testing.T) {
+	var executed []string
+
+	step := func(name string) Step[testState] {
+		return Named(name, NewStep(func(ctx context.Context, _ testState) error {
+			executed = append(executed, name)
+			return nil
+		}))
+	}
+
+	dag, err := New(Series(step("create"), If(alwaysTrue, step("publish")), Parallel(step("publish"))))
+	assert.NoError(t, err)
+
+	assert.NoError(t, dag.Replace("publish", step("fake-publish")))
+
+	errNoStep := new(ErrNoStep)
+	assert.ErrorAs(t, dag.Replace("unknown", step("fake")), &errNoStep)
+
+	assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+	assert.Equal(t, []string{"create", "fake-publish", "fake-publish"}, executed)
+
+	errFrozen := new(ErrFrozen)
+	assert.ErrorAs(t, dag.Replace("create", step("fake-create")), &errFrozen)
+
+	t.Run("FailureStep", func(t *testing.T) {
+		executed = nil
+
+		dag, err := New(Series(
+			OnFailure(Named("create", NewStep(func(context.Context, testState) error { return testErrStep })),
+				func(context.Context, testState, error) Step[testState] { return step("publish") }),
+			step("publish"),
+		))
+		assert.NoError(t, err)
+
+		assert.NoError(t, dag.Replace("publish", step("fake-publish")))
+		assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+		assert.Equal(t, []string{"publish", "fake-publish"}, executed, "the failure step is not replaced")
+	})
+}
@@ -0,0 +1,69 @@
+package dagger
+
+import "context"
+
+// Pipe is a typed stage of a pipeline, which takes an input of type A
+// and produces an output of type B. A TStep[S] is also a Pipe[S, S].
+type Pipe[A, B any] interface {
+	// Exec transforms the input into the output.
+	Exec(ctx context.Context, in A) (B, error)
+}
+
+// PipeFunc helps implement Pipe in place.
+type PipeFunc[A, B any] func(ctx context.Context, in A) (B, error)
+
+func (f PipeFunc[A, B]) Exec(ctx context.Context, in A) (B, error) { return f(ctx, in) }
+
+var _ Pipe[any, any] = (*PipeFunc[any, any])(nil)
+
+// NewPipe is a helper function to create a PipeFunc without explicit mention of generics.
+func NewPipe[A, B any](f func(ctx context.Context, in A) (B, error)) PipeFunc[A, B] { return f }
+
+type thenPipe[A, B, C any] struct {
+	first  Pipe[A, B]
+	second Pipe[B, C]
+}
+
+func (p *thenPipe[A, B, C]) Exec(ctx context.Context, in A) (C, error) {
+	mid, err := p.first.Exec(ctx, in)
+	if err != nil {
+		var zero C
+		return zero, err
+	}
+
+	return p.second.Exec(ctx, mid)
+}
+
+// Then composes two Pipe(s), the output of first is the input of second.
+// If first returns an error, second is not executed.
+//
+// The compiler checks that the stages fit together, e.g.
+//
+//	Then(Then(parse, enrich), persist)
+func Then[A, B, C any](first Pipe[A, B], second Pipe[B, C]) Pipe[A, C] {
+	return &thenPipe[A, B, C]{first: first, second: second}
+}
+
+type pipeStep[S, A, B any] struct {
+	pipe   Pipe[A, B]
+	input  func(state S) A
+	output func(state S, out B)
+}
+
+func (s *pipeStep[S, A, B]) Exec(ctx context.Context, state S) error {
+	out, err := s.pipe.Exec(ctx, s.input(state))
+	if err != nil {
+		return err
+	}
+
+	s.output(state, out)
+
+	return nil
+}
+
+// PipeStep mounts a Pipe in a DAG of Step(s). The input of the Pipe is read
+// from the state using input, and its output is stored in the state using
+// output, which is not called if the Pipe returns an error.
+func PipeStep[S, A, B any](pipe Pipe[A, B], input func(state S) A, output func(state S, out B)) Step[S] {
+	return &pipeStep[S, A, B]{pipe: pipe, input: input, output: output}
+}
@@ -0,0 +1,64 @@
+package dagger
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pipeState struct {
+	raw    string
+	result int
+}
+
+func TestThen(t *testing.T) {
+	parse := NewPipe(func(_ context.Context, in string) (int, error) { return strconv.Atoi(in) })
+	double := NewPipe(func(_ context.Context, in int) (int, error) { return in * 2, nil })
+	format := NewPipe(func(_ context.Context, in int) (string, error) { return strconv.Itoa(in), nil })
+
+	t.Run("Success", func(t *testing.T) {
+		out, err := Then(Then(parse, double), format).Exec(context.TODO(), "21")
+		assert.NoError(t, err)
+		assert.Equal(t, "42", out)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		formatted := false
+		format := NewPipe(func(_ context.Context, in int) (string, error) {
+			formatted = true
+			return strconv.Itoa(in), nil
+		})
+
+		out, err := Then(parse, format).Exec(context.TODO(), "NaN")
+		assert.ErrorIs(t, err, strconv.ErrSyntax)
+		assert.Empty(t, out)
+		assert.False(t, formatted)
+	})
+
+	t.Run("TStep", func(t *testing.T) {
+		out, err := Then[counterState, counterState, counterState](NewTStep(increment), NewTStep(increment)).
+			Exec(context.TODO(), counterState{})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, out.count)
+	})
+}
+
+func TestPipeStep(t *testing.T) {
+	parse := NewPipe(func(_ context.Context, in string) (int, error) { return strconv.Atoi(in) })
+
+	step := PipeStep(
+		parse,
+		func(state *pipeState) string { return state.raw },
+		func(state *pipeState, out int) { state.result = out },
+	)
+
+	state := &pipeState{raw: "42"}
+	assert.NoError(t, Series(step).Exec(context.TODO(), state))
+	assert.Equal(t, 42, state.result)
+
+	state = &pipeState{raw: "NaN", result: -1}
+	assert.ErrorIs(t, step.Exec(context.TODO(), state), strconv.ErrSyntax)
+	assert.Equal(t, -1, state.result)
+}
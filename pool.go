@@ -0,0 +1,190 @@
+package dagger
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// PoolOption configures a Pool.
+type PoolOption[S any] func(*poolConfig[S])
+
+type poolConfig[S any] struct {
+	workers   int
+	queueSize int
+	onResult  func(state S, err error)
+}
+
+// WithWorkers sets the number of states executed concurrently,
+// it defaults to runtime.GOMAXPROCS.
+func WithWorkers[S any](n int) PoolOption[S] {
+	return func(c *poolConfig[S]) {
+		if n > 0 {
+			c.workers = n
+		}
+	}
+}
+
+// WithQueueSize sets the number of states which can be queued
+// before Submit blocks, it defaults to the number of workers.
+func WithQueueSize[S any](n int) PoolOption[S] {
+	return func(c *poolConfig[S]) {
+		if n >= 0 {
+			c.queueSize = n
+		}
+	}
+}
+
+// OnResult sets a function called with each executed state, and the error
+// returned by Exec. It is called from the worker which executed the state.
+// The states still queued when Close gives up waiting are not executed,
+// they are reported with ErrPoolClosed.
+func OnResult[S any](fn func(state S, err error)) PoolOption[S] {
+	return func(c *poolConfig[S]) { c.onResult = fn }
+}
+
+// Pool executes the states submitted to it with a fixed number of workers,
+// against a single Executor. States are queued until a worker is available,
+// and Submit blocks while the queue is full. A Pool is safe for concurrent use.
+type Pool[S any] struct {
+	e   *Executor[S]
+	cfg poolConfig[S]
+
+	queue   chan poolItem[S]
+	running atomic.Int64
+	workers sync.WaitGroup
+
+	mu         sync.RWMutex
+	closed     bool
+	closing    chan struct{}
+	submitters sync.WaitGroup
+
+	// abort cancels the queued and running executions
+	// when Close gives up waiting for them.
+	abortCtx context.Context
+	abort    context.CancelFunc
+}
+
+type poolItem[S any] struct {
+	ctx   context.Context
+	state S
+}
+
+// NewPool creates a Pool executing the states with the Executor,
+// its workers are started right away.
+func NewPool[S any](e *Executor[S], opts ...PoolOption[S]) *Pool[S] {
+	cfg := poolConfig[S]{workers: runtime.GOMAXPROCS(0), queueSize: -1, onResult: func(S, error) {}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.queueSize < 0 {
+		cfg.queueSize = cfg.workers
+	}
+
+	p := &Pool[S]{
+		e:       e,
+		cfg:     cfg,
+		queue:   make(chan poolItem[S], cfg.queueSize),
+		closing: make(chan struct{}),
+	}
+	p.abortCtx, p.abort = context.WithCancel(context.Background())
+
+	p.workers.Add(cfg.workers)
+
+	for i := 0; i < cfg.workers; i++ {
+		go p.work()
+	}
+
+	return p
+}
+
+func (p *Pool[S]) work() {
+	defer p.workers.Done()
+
+	for item := range p.queue {
+		if p.abortCtx.Err() != nil {
+			p.cfg.onResult(item.state, &ErrPoolClosed{})
+			continue
+		}
+
+		p.running.Add(1)
+
+		ctx, cancel := context.WithCancel(item.ctx)
+		stop := context.AfterFunc(p.abortCtx, cancel)
+
+		err := p.e.Exec(ctx, item.state)
+
+		stop()
+		cancel()
+		p.running.Add(-1)
+
+		p.cfg.onResult(item.state, err)
+	}
+}
+
+// Submit queues the state for execution, blocking while the queue is full.
+// It returns ctx.Err() if ctx is done before the state is queued, and
+// ErrPoolClosed if the Pool is closed.
+//
+// The state is executed with the values of ctx, but without its
+// cancellation, since ctx usually ends once Submit returns.
+func (p *Pool[S]) Submit(ctx context.Context, state S) error {
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		return &ErrPoolClosed{}
+	}
+
+	p.submitters.Add(1)
+	p.mu.RUnlock()
+
+	defer p.submitters.Done()
+
+	select {
+	case p.queue <- poolItem[S]{ctx: context.WithoutCancel(ctx), state: state}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-p.closing:
+		return &ErrPoolClosed{}
+	}
+}
+
+// Len returns the number of queued states.
+func (p *Pool[S]) Len() int { return len(p.queue) }
+
+// Running returns the number of states being executed.
+func (p *Pool[S]) Running() int { return int(p.running.Load()) }
+
+// Close stops accepting states and waits until the queued and running ones
+// are executed. If ctx is done first, the context of the running executions
+// is canceled, the queued states are dropped without being executed, and
+// ctx.Err() is returned once the executions return.
+func (p *Pool[S]) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.closing)
+		p.submitters.Wait()
+		close(p.queue)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		p.abort()
+		<-done
+
+		return ctx.Err()
+	}
+}
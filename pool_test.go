@@ -0,0 +1,136 @@
+package dagger
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type poolState struct{ executed bool }
+
+func TestPool(t *testing.T) {
+	t.Run("Drain", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		dag, err := New(NewStep(func(ctx context.Context, state *poolState) error {
+			started <- struct{}{}
+			<-release
+
+			state.executed = true
+
+			return nil
+		}))
+		assert.NoError(t, err)
+
+		var (
+			mu       sync.Mutex
+			executed int
+		)
+
+		pool := NewPool(dag, WithWorkers[*poolState](2), WithQueueSize[*poolState](1),
+			OnResult(func(state *poolState, err error) {
+				assert.NoError(t, err)
+				assert.True(t, state.executed)
+
+				mu.Lock()
+				executed++
+				mu.Unlock()
+			}),
+		)
+
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, pool.Submit(context.TODO(), &poolState{}))
+		}
+
+		<-started
+		<-started
+
+		assert.Equal(t, 2, pool.Running())
+		assert.Equal(t, 1, pool.Len())
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, pool.Submit(ctx, &poolState{}), context.DeadlineExceeded)
+
+		closed := make(chan error)
+		go func() { closed <- pool.Close(context.TODO()) }()
+
+		close(release)
+		<-started
+
+		assert.NoError(t, <-closed)
+		assert.Equal(t, 3, executed)
+
+		errPoolClosed := new(ErrPoolClosed)
+		assert.ErrorAs(t, pool.Submit(context.TODO(), &poolState{}), &errPoolClosed)
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		started := make(chan struct{})
+
+		dag, err := New(NewStep(func(ctx context.Context, _ testState) error {
+			close(started)
+			<-ctx.Done()
+
+			return ctx.Err()
+		}))
+		assert.NoError(t, err)
+
+		var result error
+
+		pool := NewPool(dag, WithWorkers[testState](1), OnResult(func(_ testState, err error) { result = err }))
+		assert.NoError(t, pool.Submit(context.TODO(), testState{}))
+
+		<-started
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+
+		assert.ErrorIs(t, pool.Close(ctx), context.Canceled)
+		assert.ErrorIs(t, result, context.Canceled)
+	})
+
+	t.Run("DropQueued", func(t *testing.T) {
+		started := make(chan struct{})
+		executed := 0
+
+		dag, err := New(NewStep(func(ctx context.Context, _ int) error {
+			executed++
+			close(started)
+			<-ctx.Done()
+
+			return ctx.Err()
+		}))
+		assert.NoError(t, err)
+
+		results := make(map[int]error)
+
+		pool := NewPool(dag, WithWorkers[int](1), WithQueueSize[int](2),
+			OnResult(func(state int, err error) { results[state] = err }),
+		)
+
+		for i := 0; i < 3; i++ {
+			assert.NoError(t, pool.Submit(context.TODO(), i))
+		}
+
+		<-started
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+
+		assert.ErrorIs(t, pool.Close(ctx), context.Canceled)
+		assert.Equal(t, 1, executed)
+		assert.Len(t, results, 3)
+		assert.ErrorIs(t, results[0], context.Canceled)
+
+		for _, state := range []int{1, 2} {
+			errPoolClosed := new(ErrPoolClosed)
+			assert.ErrorAs(t, results[state], &errPoolClosed)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package dagger
+
+import (
+	"context"
+	"fmt"
+)
+
+type prioritizedStep[S any] struct {
+	step     Step[S]
+	priority int
+}
+
+var (
+	_ StepNamer         = (*prioritizedStep[any])(nil)
+	_ middlewareSkipper = (*prioritizedStep[any])(nil)
+	_ rebuilder[any]    = (*prioritizedStep[any])(nil)
+	_ wrapperStep[any]  = (*prioritizedStep[any])(nil)
+)
+
+func (s *prioritizedStep[S]) StepName() fmt.Stringer { return StepName(s.step) }
+
+func (s *prioritizedStep[S]) canSkip() bool { return canSkip(s.step) }
+
+func (s *prioritizedStep[S]) Exec(ctx context.Context, state S) error { return s.step.Exec(ctx, state) }
+
+func (s *prioritizedStep[S]) Unwrap() Step[S] { return s.step }
+
+func (s *prioritizedStep[S]) wrapped() Step[S] { return s.step }
+
+func (s *prioritizedStep[S]) rebuild(wrap wrapFunc[S]) Step[S] {
+	return &prioritizedStep[S]{step: rebuild(s.step, wrap), priority: s.priority}
+}
+
+// WithPriority sets the priority of a Step within Parallel, the steps with a
+// higher priority are started first, which matters when the concurrency is
+// limited, see ParallelN. Giving the long-pole steps of a wide fan-out a higher
+// priority reduces the total duration. Step(s) have a priority of zero by default.
+//
+// The outermost call wins for nested calls on the same Step.
+func WithPriority[S any](step Step[S], priority int) Step[S] {
+	return &prioritizedStep[S]{step: step, priority: priority}
+}
+
+// stepPriority returns the priority of the Step, see WithPriority.
+func stepPriority[S any](step Step[S]) int {
+	for {
+		if p, ok := step.(*prioritizedStep[S]); ok {
+			return p.priority
+		}
+
+		w, ok := step.(wrapperStep[S])
+		if !ok {
+			return 0
+		}
+
+		step = w.wrapped()
+	}
+}
@@ -0,0 +1,178 @@
+package dagger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// runIDHeader carries the run ID of an execution to a StepServer.
+const runIDHeader = "Dagger-Run-Id"
+
+// RemoteOption configures RemoteStep and StepServer.
+type RemoteOption[S any] func(*remoteConfig[S])
+
+type remoteConfig[S any] struct {
+	codec      Codec[S]
+	client     *http.Client
+	propagator TracePropagator
+}
+
+// WithRemoteCodec sets the Codec used to send the state, by default DefaultCodec.
+// The RemoteStep(s) and the StepServer must use compatible Codec(s).
+func WithRemoteCodec[S any](codec Codec[S]) RemoteOption[S] {
+	return func(c *remoteConfig[S]) { c.codec = codec }
+}
+
+// WithHTTPClient sets the client used by RemoteStep, by default http.DefaultClient.
+func WithHTTPClient[S any](client *http.Client) RemoteOption[S] {
+	return func(c *remoteConfig[S]) { c.client = client }
+}
+
+// WithPropagator sets the TracePropagator used by RemoteStep to send the trace
+// context of the execution, and by StepServer to receive it, by default
+// W3CTraceContext.
+func WithPropagator[S any](p TracePropagator) RemoteOption[S] {
+	return func(c *remoteConfig[S]) { c.propagator = p }
+}
+
+func newRemoteConfig[S any](opts []RemoteOption[S]) remoteConfig[S] {
+	cfg := remoteConfig[S]{codec: DefaultCodec[S](), client: http.DefaultClient, propagator: W3CTraceContext{}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return cfg
+}
+
+type remoteStep[S any] struct {
+	name string
+	url  string
+	cfg  remoteConfig[S]
+}
+
+func (s *remoteStep[S]) Exec(ctx context.Context, state S) (S, error) {
+	body, err := s.cfg.codec.Marshal(state)
+	if err != nil {
+		return state, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url+"/"+s.name, bytes.NewReader(body))
+	if err != nil {
+		return state, err
+	}
+
+	if id, ok := RunIDFromContext(ctx); ok {
+		req.Header.Set(runIDHeader, id)
+	}
+
+	s.cfg.propagator.Inject(ctx, req.Header)
+
+	resp, err := s.cfg.client.Do(req)
+	if err != nil {
+		return state, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return state, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return state, &ErrRemote{stepName: s.name, status: resp.StatusCode, msg: strings.TrimSpace(string(data))}
+	}
+
+	return s.cfg.codec.Unmarshal(data)
+}
+
+// RemoteStep returns a TStep which executes the Step registered under name
+// with the StepServer at url, e.g. to split a heavy DAG across services.
+// The state is sent with each request and the one returned is the new state,
+// use Mutate to mount the TStep in a DAG of Step(s) working on a pointer:
+//
+//	Named("resize-disk", Mutate(RemoteStep[VM]("resize-disk", "http://worker:8080/steps")))
+//
+// An error returned by the remote Step is returned as ErrRemote. The run ID
+// and the trace context of the execution are sent along, see WithPropagator.
+func RemoteStep[S any](name, url string, opts ...RemoteOption[S]) TStep[S] {
+	return &remoteStep[S]{name: name, url: strings.TrimSuffix(url, "/"), cfg: newRemoteConfig(opts)}
+}
+
+// StepServer is a http.Handler executing the TStep(s) registered with it
+// on behalf of RemoteStep(s). The name of the TStep is the last element of
+// the request path, and the run ID of the execution, if any, is available
+// to it via RunIDFromContext. The trace context of the request is extracted,
+// see WithPropagator, so that it propagates to RemoteStep(s) and HTTPStep(s)
+// called by the TStep.
+type StepServer[S any] struct {
+	cfg remoteConfig[S]
+
+	mu    sync.RWMutex
+	steps map[string]TStep[S]
+}
+
+// NewStepServer creates a StepServer without any TStep(s).
+func NewStepServer[S any](opts ...RemoteOption[S]) *StepServer[S] {
+	return &StepServer[S]{cfg: newRemoteConfig(opts), steps: make(map[string]TStep[S])}
+}
+
+// Register registers the TStep under name, replacing any previous one.
+func (s *StepServer[S]) Register(name string, step TStep[S]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.steps[name] = step
+}
+
+func (s *StepServer[S]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+
+	s.mu.RLock()
+	step, ok := s.steps[name]
+	s.mu.RUnlock()
+
+	if !ok {
+		http.Error(w, "unknown step '"+name+"'", http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	state, err := s.cfg.codec.Unmarshal(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := s.cfg.propagator.Extract(r.Context(), r.Header)
+	if id := r.Header.Get(runIDHeader); id != "" {
+		ctx = WithRunID(ctx, id)
+	}
+
+	state, err = step.Exec(ctx, state)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	data, err := s.cfg.codec.Marshal(state)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, _ = w.Write(data)
+}
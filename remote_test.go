@@ -0,0 +1,73 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type remoteVM struct {
+	Disk  int    `json:"disk"`
+	RunID string `json:"run_id"`
+}
+
+func TestRemoteStep(t *testing.T) {
+	server := NewStepServer[remoteVM]()
+	server.Register("resize-disk", NewTStep(func(ctx context.Context, vm remoteVM) (remoteVM, error) {
+		vm.Disk *= 2
+		vm.RunID, _ = RunIDFromContext(ctx)
+
+		return vm, nil
+	}))
+	server.Register("fail", NewTStep(func(ctx context.Context, vm remoteVM) (remoteVM, error) {
+		return vm, errors.New("disk is busy")
+	}))
+
+	srv := httptest.NewServer(http.StripPrefix("/steps", server))
+	defer srv.Close()
+
+	t.Run("Success", func(t *testing.T) {
+		dag, err := New(Mutate(RemoteStep[remoteVM]("resize-disk", srv.URL+"/steps/")))
+		assert.NoError(t, err)
+
+		vm := &remoteVM{Disk: 10}
+		assert.NoError(t, dag.Exec(WithRunID(context.TODO(), "run-1"), vm))
+		assert.Equal(t, &remoteVM{Disk: 20, RunID: "run-1"}, vm)
+	})
+
+	testcases := []struct {
+		name       string
+		step       string
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "StepError",
+			step:       "fail",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantErr:    "dagger: remote step 'fail' failed with status 422: disk is busy",
+		},
+		{
+			name:       "UnknownStep",
+			step:       "unknown",
+			wantStatus: http.StatusNotFound,
+			wantErr:    "dagger: remote step 'unknown' failed with status 404: unknown step 'unknown'",
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			vm, err := RemoteStep[remoteVM](tc.step, srv.URL+"/steps").Exec(context.TODO(), remoteVM{Disk: 10})
+			assert.EqualError(t, err, tc.wantErr)
+			assert.Equal(t, remoteVM{Disk: 10}, vm)
+
+			errRemote := new(ErrRemote)
+			assert.ErrorAs(t, err, &errRemote)
+			assert.Equal(t, tc.wantStatus, errRemote.StatusCode())
+		})
+	}
+}
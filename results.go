@@ -0,0 +1,133 @@
+package dagger
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Results holds values produced by Step(s) during executions, along with
+// the name of the Step which produced each of them, and the warnings
+// reported by them, see WithWarnings.
+//
+// It avoids adding optional fields to the state for values that
+// are only shared between a few Step(s).
+type Results struct {
+	mu       sync.RWMutex
+	values   map[string]result
+	warnings []error
+}
+
+type result struct {
+	value    any
+	producer fmt.Stringer
+}
+
+// NewResults creates an empty Results.
+func NewResults() *Results {
+	return &Results{values: make(map[string]result)}
+}
+
+// WithResults returns a copy of ctx carrying the Results. Step(s) executed
+// with this context can use SetResult and GetResult.
+func WithResults(ctx context.Context, r *Results) context.Context {
+	return context.WithValue(ctx, resultsKey, r)
+}
+
+// ResultsFromContext returns the Results carried by ctx, or nil.
+func ResultsFromContext(ctx context.Context) *Results {
+	r, _ := ctx.Value(resultsKey).(*Results)
+	return r
+}
+
+// SetResult stores the value under key in the Results carried by ctx.
+// When called from a Step executed by an Executor, the name of the Step
+// is recorded as the producer of the key.
+//
+// It returns ErrNoResults if ctx does not carry any Results.
+func SetResult[T any](ctx context.Context, key string, value T) error {
+	r := ResultsFromContext(ctx)
+	if r == nil {
+		return &ErrNoResults{}
+	}
+
+	var producer fmt.Stringer
+	if info, ok := stepInfoFromContext(ctx); ok {
+		producer = info.Name
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.values[key] = result{value: value, producer: producer}
+
+	return nil
+}
+
+// GetResult returns the value stored under key in the Results carried by ctx.
+// It returns false if there is no such value, or it is not of type T.
+func GetResult[T any](ctx context.Context, key string) (T, bool) {
+	var zero T
+
+	r := ResultsFromContext(ctx)
+	if r == nil {
+		return zero, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res, ok := r.values[key]
+	if !ok {
+		return zero, false
+	}
+
+	v, ok := res.value.(T)
+
+	return v, ok
+}
+
+// Producer returns the name of the Step which stored the value under key.
+func (r *Results) Producer(key string) (fmt.Stringer, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res, ok := r.values[key]
+	if !ok || res.producer == nil {
+		return nil, false
+	}
+
+	return res.producer, true
+}
+
+// Keys returns the sorted keys of all the stored values.
+func (r *Results) Keys() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]string, 0, len(r.values))
+	for k := range r.values {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Warnings returns the errors classified as warnings
+// during executions, in the order they occurred.
+func (r *Results) Warnings() []error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]error(nil), r.warnings...)
+}
+
+func (r *Results) addWarning(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.warnings = append(r.warnings, err)
+}
@@ -0,0 +1,67 @@
+package dagger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResults(t *testing.T) {
+	t.Run("Executor", func(t *testing.T) {
+		dag, err := New(Series(
+			Named("create", NewStep(func(ctx context.Context, _ testState) error {
+				return SetResult(ctx, "vm-id", 42)
+			})),
+			Named("publish", NewStep(func(ctx context.Context, _ testState) error {
+				id, ok := GetResult[int](ctx, "vm-id")
+				assert.True(t, ok)
+				assert.Equal(t, 42, id)
+
+				_, ok = GetResult[string](ctx, "vm-id")
+				assert.False(t, ok)
+
+				return SetResult(ctx, "published", true)
+			})),
+		))
+		assert.NoError(t, err)
+
+		results := NewResults()
+		assert.NoError(t, dag.Exec(WithResults(context.TODO(), results), testState{}))
+
+		assert.Equal(t, []string{"published", "vm-id"}, results.Keys())
+
+		producer, ok := results.Producer("vm-id")
+		assert.True(t, ok)
+		assert.Equal(t, "create", producer.String())
+
+		producer, ok = results.Producer("published")
+		assert.True(t, ok)
+		assert.Equal(t, "publish", producer.String())
+
+		_, ok = results.Producer("unknown")
+		assert.False(t, ok)
+	})
+
+	t.Run("WithoutExecutor", func(t *testing.T) {
+		results := NewResults()
+		ctx := WithResults(context.TODO(), results)
+
+		assert.NoError(t, SetResult(ctx, "key", "value"))
+
+		v, ok := GetResult[string](ctx, "key")
+		assert.True(t, ok)
+		assert.Equal(t, "value", v)
+
+		_, ok = results.Producer("key")
+		assert.False(t, ok)
+	})
+
+	t.Run("NoResults", func(t *testing.T) {
+		errNoResults := new(ErrNoResults)
+		assert.ErrorAs(t, SetResult(context.TODO(), "key", 1), &errNoResults)
+
+		_, ok := GetResult[int](context.TODO(), "key")
+		assert.False(t, ok)
+	})
+}
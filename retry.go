@@ -0,0 +1,138 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ResetFunc undoes the partial mutations of the state made by a failed
+// attempt of a Step, before it is retried, e.g. clearing an ID set by a
+// half-completed create.
+type ResetFunc[S any] func(ctx context.Context, state S) error
+
+// RetryOption configures Retry.
+type RetryOption[S any] func(*retryConfig[S])
+
+type retryConfig[S any] struct {
+	backoff   Backoff
+	retryable func(err error) bool
+	reset     ResetFunc[S]
+}
+
+// WithRetryDelay waits for baseDelay before the first retry,
+// doubled after each attempt, by default retries are immediate.
+func WithRetryDelay[S any](baseDelay time.Duration) RetryOption[S] {
+	return WithRetryBackoff[S](ExponentialBackoff(baseDelay, 2))
+}
+
+// WithRetryBackoff sets the Backoff deciding the delay before each retry.
+func WithRetryBackoff[S any](b Backoff) RetryOption[S] {
+	return func(c *retryConfig[S]) { c.backoff = b }
+}
+
+// WithRetryIf sets the function deciding whether an attempt which failed
+// with err is retried, by default every error is retried.
+func WithRetryIf[S any](retryable func(err error) bool) RetryOption[S] {
+	return func(c *retryConfig[S]) { c.retryable = retryable }
+}
+
+// WithReset calls reset after each failed attempt which is retried,
+// without it retrying a Step which mutates the state is unsafe.
+// If reset fails, the state is left as is and the Step is not retried.
+func WithReset[S any](reset ResetFunc[S]) RetryOption[S] {
+	return func(c *retryConfig[S]) { c.reset = reset }
+}
+
+type retryStep[S any] struct {
+	step     Step[S]
+	attempts int
+	cfg      retryConfig[S]
+}
+
+var (
+	_ StepNamer         = (*retryStep[any])(nil)
+	_ middlewareSkipper = (*retryStep[any])(nil)
+	_ rebuilder[any]    = (*retryStep[any])(nil)
+)
+
+func (s *retryStep[S]) StepName() fmt.Stringer {
+	return fmtStr(fmt.Sprintf("retry(%s)", StepName(s.step)))
+}
+
+func (s *retryStep[S]) canSkip() bool { return true }
+
+func (s *retryStep[S]) Unwrap() Step[S] { return s.step }
+
+func (s *retryStep[S]) rebuild(wrap wrapFunc[S]) Step[S] {
+	return &retryStep[S]{step: wrap(retryEdge, s.step), attempts: s.attempts, cfg: s.cfg}
+}
+
+// retryEdge is the edge of the Step executed by Retry.
+const retryEdge = "retry"
+
+func (s *retryStep[S]) Exec(ctx context.Context, state S) error {
+	var delay time.Duration
+
+	for attempt := 1; ; attempt++ {
+		if s.cfg.backoff != nil && attempt < s.attempts {
+			delay = s.cfg.backoff(attempt, delay)
+		} else {
+			delay = 0
+		}
+
+		err := s.step.Exec(context.WithValue(ctx, attemptKey, Attempt{Number: attempt, Max: s.attempts, NextBackoff: delay}), state)
+		if ignoreSkip(err) == nil || attempt >= s.attempts || ctx.Err() != nil || !s.cfg.retryable(err) {
+			return err
+		}
+
+		if _, ok := asAbort(err); ok {
+			return err
+		}
+
+		if s.cfg.reset != nil {
+			if resetErr := s.cfg.reset(ctx, state); resetErr != nil {
+				return errors.Join(err, resetErr)
+			}
+		}
+
+		if !sleep(ctx, delay) {
+			return err
+		}
+	}
+}
+
+// Retry executes the Step up to attempts times, until it succeeds. The error
+// of the last attempt is returned, retries stop early if ctx is done. ErrSkip
+// and ErrAbort are returned as is, without retrying the Step.
+// Like If, the Step is a separate node of the DAG, e.g. root/retry for
+// a Retry at the root, which middlewares wrap and see each attempt of,
+// see AttemptFromContext.
+func Retry[S any](step Step[S], attempts int, opts ...RetryOption[S]) Step[S] {
+	s := &retryStep[S]{step: step, attempts: attempts, cfg: retryConfig[S]{retryable: func(error) bool { return true }}}
+	for _, opt := range opts {
+		opt(&s.cfg)
+	}
+
+	return s
+}
+
+// Attempt describes an attempt of a Step executed by Retry.
+type Attempt struct {
+	// Number is the number of the attempt, starting at 1.
+	Number int
+	// Max is the maximum number of attempts.
+	Max int
+	// NextBackoff is the delay before the next attempt, if this one fails
+	// and is retried, it is zero for the last attempt.
+	NextBackoff time.Duration
+}
+
+// AttemptFromContext returns the Attempt of the Step executed with ctx,
+// if it is executed by Retry, e.g. for logging and metrics middlewares
+// to tell the first attempt from the last one.
+func AttemptFromContext(ctx context.Context) (Attempt, bool) {
+	a, ok := ctx.Value(attemptKey).(Attempt)
+	return a, ok
+}
@@ -0,0 +1,137 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type createState struct {
+	ID       string
+	attempts int
+}
+
+// createVM fails after setting the ID in its first two attempts.
+func createVM(_ context.Context, s *createState) error {
+	s.attempts++
+	if s.ID != "" {
+		return errors.New("vm already created")
+	}
+
+	s.ID = "vm-1"
+	if s.attempts < 3 {
+		return testErrStep
+	}
+
+	return nil
+}
+
+func TestRetry(t *testing.T) {
+	step := Retry(NewStep(createVM), 3)
+	assert.Equal(t, "retry("+StepName(NewStep(createVM)).String()+")", StepName(step).String())
+
+	state := &createState{}
+	assert.EqualError(t, step.Exec(context.TODO(), state), "vm already created")
+	assert.Equal(t, 3, state.attempts)
+
+	t.Run("WithReset", func(t *testing.T) {
+		reset := func(_ context.Context, s *createState) error {
+			s.ID = ""
+			return nil
+		}
+
+		state := &createState{}
+		assert.NoError(t, Retry(NewStep(createVM), 3, WithReset(reset)).Exec(context.TODO(), state))
+		assert.Equal(t, "vm-1", state.ID)
+
+		state = &createState{}
+		assert.ErrorIs(t, Retry(NewStep(createVM), 2, WithReset(reset)).Exec(context.TODO(), state), testErrStep)
+		assert.Equal(t, 2, state.attempts)
+
+		errReset := errors.New("reset failed")
+		err := Retry(NewStep(createVM), 3, WithReset(func(context.Context, *createState) error { return errReset })).
+			Exec(context.TODO(), &createState{})
+		assert.ErrorIs(t, err, testErrStep)
+		assert.ErrorIs(t, err, errReset)
+	})
+
+	t.Run("WithRetryIf", func(t *testing.T) {
+		state := &createState{}
+		err := Retry(NewStep(createVM), 3, WithRetryIf[*createState](func(err error) bool { return false })).
+			Exec(context.TODO(), state)
+		assert.ErrorIs(t, err, testErrStep)
+		assert.Equal(t, 1, state.attempts)
+	})
+
+	t.Run("SkipAndAbort", func(t *testing.T) {
+		for _, stepErr := range []error{&ErrSkip{}, Abort("up to date")} {
+			attempts := 0
+
+			err := Retry(NewStep(func(context.Context, *createState) error {
+				attempts++
+				return stepErr
+			}), 3).Exec(context.TODO(), &createState{})
+			assert.ErrorIs(t, err, stepErr)
+			assert.Equal(t, 1, attempts, "%v is retried", stepErr)
+		}
+	})
+
+	t.Run("WithRetryDelay", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Millisecond)
+		defer cancel()
+
+		state := &createState{}
+		err := Retry(NewStep(createVM), 3, WithRetryDelay[*createState](time.Minute)).Exec(ctx, state)
+		assert.ErrorIs(t, err, testErrStep)
+		assert.Equal(t, 1, state.attempts)
+	})
+
+	t.Run("WithRetryBackoff", func(t *testing.T) {
+		state := &createState{}
+		err := Retry(NewStep(createVM), 3, WithRetryBackoff[*createState](ConstantBackoff(time.Microsecond))).
+			Exec(context.TODO(), state)
+		assert.EqualError(t, err, "vm already created")
+		assert.Equal(t, 3, state.attempts)
+	})
+
+	t.Run("Attempt", func(t *testing.T) {
+		var attempts []Attempt
+
+		dag, err := New(Retry(NewStep(createVM), 3,
+			WithRetryDelay[*createState](time.Microsecond),
+			WithReset(func(_ context.Context, s *createState) error {
+				s.ID = ""
+				return nil
+			}),
+		))
+		assert.NoError(t, err)
+		assert.NoError(t, dag.Use(func(next Step[*createState], info Info) Step[*createState] {
+			if info.CanSkip {
+				return next
+			}
+
+			assert.Equal(t, "root/retry", info.Path)
+
+			return StepFunc[*createState](func(ctx context.Context, state *createState) error {
+				attempt, ok := AttemptFromContext(ctx)
+				assert.True(t, ok)
+				attempts = append(attempts, attempt)
+
+				return next.Exec(ctx, state)
+			})
+		}))
+
+		assert.NoError(t, dag.Exec(context.TODO(), &createState{}))
+		assert.Equal(t, []Attempt{
+			{Number: 1, Max: 3, NextBackoff: time.Microsecond},
+			{Number: 2, Max: 3, NextBackoff: 2 * time.Microsecond},
+			{Number: 3, Max: 3},
+		}, attempts)
+
+		_, ok := AttemptFromContext(context.TODO())
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,156 @@
+package dagger
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Run is a handle to an execution started by Executor.ExecAsync.
+type Run struct {
+	id      string
+	meta    map[string]string
+	started time.Time
+	cancel  context.CancelCauseFunc
+	done    chan struct{}
+	err     error
+	abort   *ErrAbort
+	current atomic.Pointer[Info]
+
+	mu sync.Mutex
+	// resumed is closed on Resume, it is nil if the Run is not paused.
+	resumed chan struct{}
+}
+
+// ExecAsync starts executing the DAG with the given state in a new
+// goroutine, and returns a Run to supervise the execution.
+// The context of the execution always carries a run ID.
+func (e *Executor[S]) ExecAsync(ctx context.Context, state S) *Run {
+	ctx, cancel := context.WithCancelCause(e.withConfig(e.nest(ctx)))
+
+	id, ok := RunIDFromContext(ctx)
+	if !ok {
+		id = NewRunID()
+		ctx = WithRunID(ctx, id)
+	}
+
+	r := &Run{id: id, meta: RunMetaFromContext(ctx), started: e.clock().Now(), cancel: cancel, done: make(chan struct{})}
+	e.drain.track(r)
+	e.recent.add(r)
+
+	go func() {
+		defer close(r.done)
+		defer cancel(nil)
+		defer e.drain.untrack(r)
+
+		err := ignoreSkip(e.exec(context.WithValue(ctx, runKey, r), state))
+		if abort, ok := asAbort(err); ok {
+			r.abort, err = abort, nil
+		}
+
+		e.stats.record(err)
+
+		r.err = err
+	}()
+
+	return r
+}
+
+// ID returns the run ID of the execution, which is the one carried by the
+// context given to ExecAsync, or a new one created by NewRunID.
+func (r *Run) ID() string { return r.id }
+
+// Meta returns the metadata of the execution, set with WithRunMeta
+// on the context given to ExecAsync, it must not be modified.
+func (r *Run) Meta() map[string]string { return r.meta }
+
+// Wait waits for the execution to finish and returns its error.
+func (r *Run) Wait() error {
+	<-r.done
+	return r.err
+}
+
+// Aborted returns the reason of the ErrAbort which stopped the execution,
+// it reports false if the execution was not aborted. It must only be
+// called after the execution finishes.
+func (r *Run) Aborted() (string, bool) {
+	if r.abort == nil {
+		return "", false
+	}
+
+	return r.abort.Reason(), true
+}
+
+// Done returns a channel which is closed when the execution finishes.
+func (r *Run) Done() <-chan struct{} { return r.done }
+
+// Cancel cancels the context of the execution with the given cause,
+// which is available to Step(s) via context.Cause. It does not wait
+// for the execution to finish.
+func (r *Run) Cancel(cause error) { r.cancel(cause) }
+
+// CurrentStep returns the Info of the leaf Step executed most recently,
+// it has a nil Name if no Step has started yet.
+func (r *Run) CurrentStep() Info {
+	if info := r.current.Load(); info != nil {
+		return *info
+	}
+
+	return Info{}
+}
+
+// Pause pauses the execution at the next step boundary, i.e. leaf Step(s)
+// which have already started keep executing, but no new leaf Step starts
+// until Resume is called.
+func (r *Run) Pause() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.resumed == nil {
+		r.resumed = make(chan struct{})
+	}
+}
+
+// Resume resumes a paused execution.
+func (r *Run) Resume() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.resumed != nil {
+		close(r.resumed)
+		r.resumed = nil
+	}
+}
+
+// Paused reports whether the execution is paused.
+func (r *Run) Paused() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.resumed != nil
+}
+
+// waitResumed blocks while the Run is paused, it returns
+// the cause of ctx if it is canceled in the meantime.
+func (r *Run) waitResumed(ctx context.Context) error {
+	r.mu.Lock()
+	resumed := r.resumed
+	r.mu.Unlock()
+
+	if resumed == nil {
+		return nil
+	}
+
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+func runFromContext(ctx context.Context) *Run {
+	r, _ := ctx.Value(runKey).(*Run)
+	return r
+}
@@ -0,0 +1,147 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutor_ExecAsync(t *testing.T) {
+	t.Run("Wait", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		dag, err := New(Series(
+			Named("validate", NewStep(noopStep)),
+			Named("create", NewStep(func(ctx context.Context, _ testState) error {
+				close(started)
+				<-release
+				return testErrStep
+			})),
+		))
+		assert.NoError(t, err)
+
+		run := dag.ExecAsync(context.TODO(), testState{})
+		assert.Nil(t, run.CurrentStep().Name)
+
+		<-started
+		assert.Equal(t, "create", run.CurrentStep().Name.String())
+
+		select {
+		case <-run.Done():
+			t.Fatal("run finished before the step was released")
+		default:
+		}
+
+		close(release)
+		assert.ErrorIs(t, run.Wait(), testErrStep)
+		<-run.Done()
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		errShutdown := errors.New("shutdown")
+		started := make(chan struct{})
+
+		dag, err := New[testState](NewStep(func(ctx context.Context, _ testState) error {
+			close(started)
+			<-ctx.Done()
+			return context.Cause(ctx)
+		}))
+		assert.NoError(t, err)
+
+		run := dag.ExecAsync(context.TODO(), testState{})
+		<-started
+
+		run.Cancel(errShutdown)
+		assert.ErrorIs(t, run.Wait(), errShutdown)
+	})
+
+	t.Run("Aborted", func(t *testing.T) {
+		dag, err := New[testState](NewStep(func(ctx context.Context, _ testState) error {
+			return Abort("already provisioned")
+		}))
+		assert.NoError(t, err)
+
+		run := dag.ExecAsync(context.TODO(), testState{})
+		assert.NoError(t, run.Wait())
+
+		reason, ok := run.Aborted()
+		assert.True(t, ok)
+		assert.Equal(t, "already provisioned", reason)
+
+		run = dag.WithAdditionalMiddleware().ExecAsync(context.TODO(), testState{})
+		assert.NoError(t, run.Wait())
+
+		_, ok = run.Aborted()
+		assert.True(t, ok)
+	})
+}
+
+func TestRun_Pause(t *testing.T) {
+	t.Run("Resume", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		published := false
+
+		dag, err := New(Series(
+			Named("create", NewStep(func(ctx context.Context, _ testState) error {
+				close(started)
+				<-release
+				return nil
+			})),
+			Named("publish", NewStep(func(ctx context.Context, _ testState) error {
+				published = true
+				return nil
+			})),
+		))
+		assert.NoError(t, err)
+
+		run := dag.ExecAsync(context.TODO(), testState{})
+		<-started
+
+		run.Pause()
+		run.Pause()
+		assert.True(t, run.Paused())
+
+		close(release)
+
+		// publish must not start while paused, CurrentStep
+		// only changes once a leaf Step starts.
+		assert.Never(t, func() bool { return run.CurrentStep().Name.String() != "create" }, 20*time.Millisecond, time.Millisecond)
+
+		run.Resume()
+		run.Resume()
+		assert.False(t, run.Paused())
+
+		assert.NoError(t, run.Wait())
+		assert.True(t, published)
+	})
+
+	t.Run("CancelWhilePaused", func(t *testing.T) {
+		errShutdown := errors.New("shutdown")
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		dag, err := New(Series(
+			NewStep(func(ctx context.Context, _ testState) error {
+				close(started)
+				<-release
+				return nil
+			}),
+			NewStep(noopStep),
+		))
+		assert.NoError(t, err)
+
+		run := dag.ExecAsync(context.TODO(), testState{})
+		<-started
+
+		run.Pause()
+		close(release)
+		run.Cancel(errShutdown)
+
+		assert.ErrorIs(t, run.Wait(), errShutdown)
+	})
+}
@@ -0,0 +1,55 @@
+package dagger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// NewRunID returns a new random run ID.
+func NewRunID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:]) // never returns an error
+
+	return hex.EncodeToString(b[:])
+}
+
+// WithRunID returns a copy of ctx carrying the run ID, which correlates all
+// the Step(s) of an execution. Step(s) and middlewares get it with RunIDFromContext,
+// and it is included in the errors wrapped by Continue and Parallel.
+func WithRunID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, runIDKey, id)
+}
+
+// RunIDFromContext returns the run ID carried by ctx, if any.
+func RunIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(runIDKey).(string)
+	return id, ok
+}
+
+// MintRunID returns a DAGMiddleware which adds a new run ID, created by NewRunID,
+// to the context of executions which do not carry one already.
+//
+// Run IDs are not added by default, since Exec does not allocate otherwise.
+// Run(s) started with ExecAsync always have one, see Run.ID.
+func MintRunID[S any]() DAGMiddleware[S] {
+	return func(next Step[S]) Step[S] {
+		return StepFunc[S](func(ctx context.Context, state S) error {
+			if _, ok := RunIDFromContext(ctx); !ok {
+				ctx = WithRunID(ctx, NewRunID())
+			}
+
+			return next.Exec(ctx, state)
+		})
+	}
+}
+
+// wrapStepError wraps the error returned by the given Step of a meta Step.
+func wrapStepError[S any](ctx context.Context, step Step[S], err error) error {
+	if id, ok := RunIDFromContext(ctx); ok {
+		return fmt.Errorf("error executing step %s (run %s): %w", StepName(step), id, err)
+	}
+
+	return fmt.Errorf("error executing step %s: %w", StepName(step), err)
+}
@@ -0,0 +1,70 @@
+package dagger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMintRunID(t *testing.T) {
+	var ids []string
+
+	record := NewStep(func(ctx context.Context, state testState) error {
+		id, ok := RunIDFromContext(ctx)
+		assert.True(t, ok)
+
+		ids = append(ids, id)
+
+		return nil
+	})
+
+	dag, err := New(Series(record, record))
+	assert.NoError(t, err)
+	assert.NoError(t, dag.UseDAG(MintRunID[testState]()))
+
+	assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+	assert.Len(t, ids, 2)
+	assert.Len(t, ids[0], 32)
+	assert.Equal(t, ids[0], ids[1])
+
+	assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+	assert.NotEqual(t, ids[0], ids[2])
+
+	assert.NoError(t, dag.Exec(WithRunID(context.TODO(), "req-42"), testState{}))
+	assert.Equal(t, "req-42", ids[4])
+}
+
+func TestRunIDFromContext(t *testing.T) {
+	_, ok := RunIDFromContext(context.TODO())
+	assert.False(t, ok)
+
+	t.Run("ExecAsync", func(t *testing.T) {
+		var id string
+
+		dag, err := New[testState](NewStep(func(ctx context.Context, state testState) error {
+			id, _ = RunIDFromContext(ctx)
+			return nil
+		}))
+		assert.NoError(t, err)
+
+		run := dag.ExecAsync(context.TODO(), testState{})
+		assert.NoError(t, run.Wait())
+		assert.NotEmpty(t, run.ID())
+		assert.Equal(t, run.ID(), id)
+
+		run = dag.ExecAsync(WithRunID(context.TODO(), "req-42"), testState{})
+		assert.NoError(t, run.Wait())
+		assert.Equal(t, "req-42", run.ID())
+	})
+
+	t.Run("ErrorWrapping", func(t *testing.T) {
+		failing := Named("failing", NewStep(func(ctx context.Context, state testState) error { return testErrStep }))
+
+		err := Continue(failing).Exec(WithRunID(context.TODO(), "req-42"), testState{})
+		assert.EqualError(t, err, "error executing step failing (run req-42): step error")
+
+		err = Parallel(failing).Exec(context.TODO(), testState{})
+		assert.EqualError(t, err, "error executing step failing: step error")
+	})
+}
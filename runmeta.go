@@ -0,0 +1,31 @@
+package dagger
+
+import "context"
+
+// WithRunMeta returns a copy of ctx carrying the given metadata of the execution,
+// e.g. the tenant or the request which triggered it. It is merged with the metadata
+// already carried by ctx, if any, the given values taking precedence.
+// Step(s) and middlewares get it with RunMetaFromContext, and it is included
+// in the AuditRecord(s) of AuditLog and returned by Run.Meta.
+//
+// The metadata is shared by nested executions, like the run ID.
+func WithRunMeta(ctx context.Context, meta map[string]string) context.Context {
+	merged := make(map[string]string, len(meta))
+
+	for k, v := range RunMetaFromContext(ctx) {
+		merged[k] = v
+	}
+
+	for k, v := range meta {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, runMetaKey, merged)
+}
+
+// RunMetaFromContext returns the metadata carried by ctx, which must not be modified.
+// It returns nil if ctx does not carry any.
+func RunMetaFromContext(ctx context.Context) map[string]string {
+	meta, _ := ctx.Value(runMetaKey).(map[string]string)
+	return meta
+}
@@ -0,0 +1,54 @@
+package dagger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRunMeta(t *testing.T) {
+	assert.Nil(t, RunMetaFromContext(context.TODO()))
+
+	ctx := WithRunMeta(context.TODO(), map[string]string{"tenant": "acme", "trigger": "api"})
+	ctx = WithRunMeta(ctx, map[string]string{"trigger": "cron"})
+
+	assert.Equal(t, map[string]string{"tenant": "acme", "trigger": "cron"}, RunMetaFromContext(ctx))
+
+	var seen []map[string]string
+
+	record := NewStep(func(ctx context.Context, state testState) error {
+		seen = append(seen, RunMetaFromContext(ctx))
+		return nil
+	})
+
+	inner, err := New(record, WithNesting[testState](NestReplace))
+	assert.NoError(t, err)
+
+	dag, err := New(Series[testState](record, inner))
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, dag.Use(AuditLog[testState](AuditWriter(buf))))
+
+	assert.NoError(t, dag.Exec(ctx, testState{}))
+	assert.Len(t, seen, 2)
+	assert.Equal(t, "cron", seen[0]["trigger"])
+	assert.Equal(t, seen[0], seen[1])
+
+	var record0 AuditRecord
+	assert.NoError(t, json.NewDecoder(buf).Decode(&record0))
+	assert.Equal(t, map[string]string{"tenant": "acme", "trigger": "cron"}, record0.Meta)
+
+	t.Run("ExecAsync", func(t *testing.T) {
+		run := dag.ExecAsync(ctx, testState{})
+		assert.NoError(t, run.Wait())
+		assert.Equal(t, "acme", run.Meta()["tenant"])
+
+		run = dag.ExecAsync(context.TODO(), testState{})
+		assert.NoError(t, run.Wait())
+		assert.Nil(t, run.Meta())
+	})
+}
@@ -0,0 +1,146 @@
+package dagger
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// OverlapPolicy decides what Schedule does when an execution is due
+// while the previous one is still running.
+type OverlapPolicy int
+
+const (
+	// OverlapSkip skips the due execution.
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue starts the due execution once the previous one finishes,
+	// at most one execution is queued, the others are skipped.
+	OverlapQueue
+	// OverlapConcurrent starts the due execution right away.
+	OverlapConcurrent
+)
+
+// ScheduledRun describes an execution started, or skipped, by Schedule.
+type ScheduledRun struct {
+	// Due is the time the execution was due, before the jitter.
+	Due time.Time
+	// Skipped is set if the execution was skipped due to OverlapSkip, or
+	// because an execution was already queued due to OverlapQueue.
+	Skipped bool
+	// Duration is the time the execution took.
+	Duration time.Duration
+	// Err is the error returned by Exec.
+	Err error
+}
+
+// ScheduleOption configures Schedule.
+type ScheduleOption func(*scheduleConfig)
+
+type scheduleConfig struct {
+	overlap OverlapPolicy
+	jitter  time.Duration
+	hook    func(run ScheduledRun)
+}
+
+// WithOverlap sets the OverlapPolicy, it defaults to OverlapSkip.
+func WithOverlap(p OverlapPolicy) ScheduleOption {
+	return func(c *scheduleConfig) { c.overlap = p }
+}
+
+// WithJitter delays each execution by a random duration up to max, to
+// spread the executions of many schedules sharing the same interval.
+func WithJitter(max time.Duration) ScheduleOption {
+	return func(c *scheduleConfig) { c.jitter = max }
+}
+
+// OnScheduledRun sets a hook called after each execution, and for each
+// skipped one. It is called from the goroutine of the execution.
+func OnScheduledRun(hook func(run ScheduledRun)) ScheduleOption {
+	return func(c *scheduleConfig) { c.hook = hook }
+}
+
+// Schedule executes the DAG every interval, with a new state returned by
+// newState each time, until ctx is done. It then waits for the running
+// executions, whose context is canceled as well, and returns ctx.Err().
+// It returns ErrInterval if every is not positive.
+func (e *Executor[S]) Schedule(ctx context.Context, every time.Duration, newState func() S, opts ...ScheduleOption) error {
+	if every <= 0 {
+		return &ErrInterval{every: every}
+	}
+
+	cfg := scheduleConfig{hook: func(ScheduledRun) {}}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	clock := e.clock()
+
+	ticker := clock.NewTicker(every)
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	running := make(chan struct{}, 1)
+
+	for {
+		var due time.Time
+
+		select {
+		case due = <-ticker.C():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		switch cfg.overlap {
+		case OverlapSkip:
+			select {
+			case running <- struct{}{}:
+			default:
+				cfg.hook(ScheduledRun{Due: due, Skipped: true})
+				continue
+			}
+		case OverlapQueue:
+			// The ticks received while an execution is queued are skipped,
+			// rather than left in the ticker to start stale executions.
+		queue:
+			for {
+				select {
+				case running <- struct{}{}:
+					break queue
+				case skipped := <-ticker.C():
+					cfg.hook(ScheduledRun{Due: skipped, Skipped: true})
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		case OverlapConcurrent:
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if cfg.overlap != OverlapConcurrent {
+				defer func() { <-running }()
+			}
+
+			if cfg.jitter > 0 {
+				timer := clock.NewTimer(time.Duration(rand.Int63n(int64(cfg.jitter))))
+				defer timer.Stop()
+
+				select {
+				case <-timer.C():
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			start := clock.Now()
+			err := e.Exec(ctx, newState())
+			cfg.hook(ScheduledRun{Due: due, Duration: clock.Now().Sub(start), Err: err})
+		}()
+	}
+}
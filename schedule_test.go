@@ -0,0 +1,112 @@
+package dagger
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutor_Schedule(t *testing.T) {
+	testcases := []struct {
+		name           string
+		overlap        OverlapPolicy
+		wantConcurrent bool
+		wantSkipped    bool
+	}{
+		{name: "Skip", overlap: OverlapSkip, wantSkipped: true},
+		{name: "Queue", overlap: OverlapQueue, wantSkipped: true},
+		{name: "Concurrent", overlap: OverlapConcurrent, wantConcurrent: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			var running, maxRunning, executed, skipped atomic.Int32
+
+			dag, err := New(NewStep(func(ctx context.Context, _ testState) error {
+				n := running.Add(1)
+				defer running.Add(-1)
+
+				for m := maxRunning.Load(); n > m && !maxRunning.CompareAndSwap(m, n); m = maxRunning.Load() {
+				}
+
+				time.Sleep(15 * time.Millisecond)
+				executed.Add(1)
+
+				return nil
+			}))
+			assert.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.TODO(), 100*time.Millisecond)
+			defer cancel()
+
+			var mu sync.Mutex
+			var runs []ScheduledRun
+
+			err = dag.Schedule(ctx, 5*time.Millisecond, func() testState { return testState{} },
+				WithOverlap(tc.overlap),
+				WithJitter(time.Millisecond),
+				OnScheduledRun(func(run ScheduledRun) {
+					if run.Skipped {
+						skipped.Add(1)
+					}
+
+					mu.Lock()
+					runs = append(runs, run)
+					mu.Unlock()
+				}),
+			)
+			assert.ErrorIs(t, err, context.DeadlineExceeded)
+			assert.Zero(t, running.Load(), "executions still running after Schedule returned")
+
+			assert.Greater(t, executed.Load(), int32(1))
+			assert.Equal(t, tc.wantConcurrent, maxRunning.Load() > 1)
+			assert.Equal(t, tc.wantSkipped, skipped.Load() > 0)
+
+			for _, run := range runs {
+				assert.False(t, run.Due.IsZero())
+			}
+		})
+	}
+}
+
+func TestExecutor_Schedule_Queue(t *testing.T) {
+	dag, err := New(NewStep(func(ctx context.Context, _ testState) error {
+		time.Sleep(40 * time.Millisecond)
+		return nil
+	}))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 250*time.Millisecond)
+	defer cancel()
+
+	var mu sync.Mutex
+	var lags []time.Duration
+
+	err = dag.Schedule(ctx, 10*time.Millisecond, func() testState { return testState{} },
+		WithOverlap(OverlapQueue),
+		OnScheduledRun(func(run ScheduledRun) {
+			if run.Skipped {
+				return
+			}
+
+			mu.Lock()
+			lags = append(lags, time.Since(run.Due)-run.Duration)
+			mu.Unlock()
+		}),
+	)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotEmpty(t, lags)
+
+	for _, lag := range lags {
+		assert.Less(t, lag, 60*time.Millisecond, "a stale execution was started")
+	}
+
+	t.Run("InvalidInterval", func(t *testing.T) {
+		errInterval := new(ErrInterval)
+		assert.ErrorAs(t, dag.Schedule(context.TODO(), 0, func() testState { return testState{} }), &errInterval)
+	})
+}
@@ -0,0 +1,62 @@
+package dagger
+
+import (
+	"context"
+	"fmt"
+)
+
+// ScopeFunc begins a scope around a Step, e.g. a database transaction, see
+// Scoped. It returns the context the Step is executed with, typically carrying
+// the resource of the scope, and the function ending the scope, which is called
+// with the error returned by the Step, and returns the error of the Step.
+type ScopeFunc[S any] func(ctx context.Context, state S) (context.Context, func(err error) error, error)
+
+type scopedStep[S any] struct {
+	step  Step[S]
+	begin ScopeFunc[S]
+}
+
+var (
+	_ StepNamer         = (*scopedStep[any])(nil)
+	_ middlewareSkipper = (*scopedStep[any])(nil)
+	_ rebuilder[any]    = (*scopedStep[any])(nil)
+	_ wrapperStep[any]  = (*scopedStep[any])(nil)
+)
+
+func (s *scopedStep[S]) StepName() fmt.Stringer { return StepName(s.step) }
+
+func (s *scopedStep[S]) canSkip() bool { return canSkip(s.step) }
+
+func (s *scopedStep[S]) Unwrap() Step[S] { return s.step }
+
+func (s *scopedStep[S]) wrapped() Step[S] { return s.step }
+
+func (s *scopedStep[S]) rebuild(wrap wrapFunc[S]) Step[S] {
+	return &scopedStep[S]{step: rebuild(s.step, wrap), begin: s.begin}
+}
+
+func (s *scopedStep[S]) Exec(ctx context.Context, state S) error {
+	ctx, end, err := s.begin(ctx, state)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = end(&ErrPanic{value: r})
+			panic(r)
+		}
+	}()
+
+	return end(s.step.Exec(ctx, state))
+}
+
+// Scoped executes the Step within the scope begun by begin, and ended once the
+// Step returns, or panics. If the Step panics, the scope is ended with ErrPanic,
+// and the panic is propagated. If begin fails, the Step is not executed.
+//
+// The Step(s) nested within the Step are wrapped by middlewares as usual,
+// the scope itself is transparent to them.
+func Scoped[S any](step Step[S], begin ScopeFunc[S]) Step[S] {
+	return &scopedStep[S]{step: step, begin: begin}
+}
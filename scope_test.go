@@ -0,0 +1,74 @@
+package dagger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type scopeKey struct{}
+
+func TestScoped(t *testing.T) {
+	var ended []error
+
+	begin := func(ctx context.Context, _ testState) (context.Context, func(err error) error, error) {
+		return context.WithValue(ctx, scopeKey{}, "tx"), func(err error) error {
+			ended = append(ended, err)
+			return err
+		}, nil
+	}
+
+	inScope := NewStep(func(ctx context.Context, _ testState) error {
+		assert.Equal(t, "tx", ctx.Value(scopeKey{}))
+		return nil
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		ended = nil
+
+		buf := &bytes.Buffer{}
+
+		dag, err := New(Scoped(Series(inScope, inScope), begin))
+		assert.NoError(t, err)
+		assert.NoError(t, dag.Use(testLogMiddleware[testState](buf, "log")))
+
+		assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+		assert.Equal(t, []error{nil}, ended)
+		assert.Contains(t, buf.String(), "TestScoped.func2")
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		ended = nil
+
+		err := Scoped[testState](NewStep(func(ctx context.Context, _ testState) error { return testErrStep }), begin).
+			Exec(context.TODO(), testState{})
+		assert.ErrorIs(t, err, testErrStep)
+		assert.Equal(t, []error{testErrStep}, ended)
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		ended = nil
+
+		step := Scoped[testState](NewStep(func(ctx context.Context, _ testState) error { panic("boom") }), begin)
+
+		assert.PanicsWithValue(t, "boom", func() { _ = step.Exec(context.TODO(), testState{}) })
+		assert.Len(t, ended, 1)
+
+		errPanic := new(ErrPanic)
+		assert.ErrorAs(t, ended[0], &errPanic)
+		assert.Equal(t, "boom", errPanic.Value())
+	})
+
+	t.Run("BeginError", func(t *testing.T) {
+		errBegin := errors.New("begin")
+
+		step := Scoped[testState](inScope, func(ctx context.Context, _ testState) (context.Context, func(err error) error, error) {
+			return ctx, nil, errBegin
+		})
+
+		assert.ErrorIs(t, step.Exec(context.TODO(), testState{}), errBegin)
+	})
+}
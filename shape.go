@@ -0,0 +1,82 @@
+package dagger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Shape describes the structure of a DAG, see Executor.Shape.
+type Shape struct {
+	// Kinds holds the number of Step(s) of each kind, e.g. "Series",
+	// "If" or "Leaf". Meta Step(s) defined outside the package are
+	// counted under the name of their type.
+	Kinds map[string]int
+	// Steps is the total number of Step(s).
+	Steps int
+	// Leaves is the number of leaf Step(s).
+	Leaves int
+	// MaxDepth is the depth of the most nested Step, the root is at depth 0.
+	MaxDepth int
+}
+
+// Shape returns the Shape of the DAG of the Executor.
+func (e *Executor[S]) Shape() Shape {
+	shape := Shape{Kinds: make(map[string]int)}
+
+	Walk(e.start, func(step Step[S], info Info, depth int) bool {
+		shape.Kinds[stepKind(step)]++
+		shape.Steps++
+		shape.MaxDepth = max(shape.MaxDepth, depth)
+
+		if !info.CanSkip {
+			shape.Leaves++
+		}
+
+		return true
+	})
+
+	return shape
+}
+
+// Fingerprint returns a stable hash of the structure of the DAG of the
+// Executor, i.e. the name, kind, and position of every Step, along with the
+// Selector of conditional Step(s). It only changes when the structure does,
+// e.g. to detect changes to the topology of a workflow in CI.
+//
+// Anonymous functions are named after their position in the code,
+// see Named to keep the Fingerprint stable when the code moves.
+func (e *Executor[S]) Fingerprint() string {
+	h := sha256.New()
+
+	e.walk(func(step Step[S], info Info, depth int) bool {
+		_, _ = fmt.Fprintf(h, "%d\t%s\t%s\t%v\n", depth, stepKind(step), info.Name, info.Selector)
+		return true
+	})
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// stepKind returns the kind of a Step, for Shape.
+func stepKind[S any](step Step[S]) string {
+	switch s := unwrapNode(step).(type) {
+	case *seriesStep[S]:
+		return "Series"
+	case *parallelStep[S]:
+		return "Parallel"
+	case *continueStep[S]:
+		return "Continue"
+	case *ifStep[S]:
+		return "If"
+	case *ifElseStep[S]:
+		return "IfElse"
+	case *resultStep[S]:
+		return "Result"
+	default:
+		if canSkip(s) {
+			return stepTypeName(s).String()
+		}
+
+		return "Leaf"
+	}
+}
@@ -0,0 +1,43 @@
+package dagger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutor_Shape(t *testing.T) {
+	dag, err := New(Series(
+		Named("validate", NewStep(noopStep)),
+		Parallel(NewStep(noopStep), If(alwaysTrue, NewStep(noopStep))),
+		OnFailure(NewStep(noopStep), nil),
+	))
+	assert.NoError(t, err)
+
+	assert.Equal(t, Shape{
+		Kinds:    map[string]int{"Series": 1, "Parallel": 1, "If": 1, "Result": 1, "Leaf": 4},
+		Steps:    8,
+		Leaves:   4,
+		MaxDepth: 3,
+	}, dag.Shape())
+}
+
+func TestExecutor_Fingerprint(t *testing.T) {
+	newDAG := func(steps ...Step[testState]) *Executor[testState] {
+		dag, err := New(Series(steps...))
+		assert.NoError(t, err)
+
+		return dag
+	}
+
+	validate := Named("validate", NewStep(noopStep))
+	publish := Named("publish", NewStep(noopStep))
+
+	fingerprint := newDAG(validate, If(alwaysTrue, publish)).Fingerprint()
+	assert.Len(t, fingerprint, 64)
+
+	assert.Equal(t, fingerprint, newDAG(validate, If(alwaysTrue, publish)).Fingerprint(), "same structure")
+	assert.NotEqual(t, fingerprint, newDAG(If(alwaysTrue, publish), validate).Fingerprint(), "reordered")
+	assert.NotEqual(t, fingerprint, newDAG(validate, If(alwaysFalse, publish)).Fingerprint(), "selector changed")
+	assert.NotEqual(t, fingerprint, newDAG(validate, Parallel(publish)).Fingerprint(), "kind changed")
+}
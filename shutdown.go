@@ -0,0 +1,103 @@
+package dagger
+
+import (
+	"context"
+	"sync"
+)
+
+// drainer tracks the executions in flight, so that they can be drained.
+// The zero value is ready to use.
+type drainer struct {
+	mu     sync.Mutex
+	closed bool
+	active int
+	// idle is closed once no execution is in flight after closing,
+	// it is nil until someone waits for it.
+	idle chan struct{}
+	runs map[*Run]struct{}
+}
+
+// acquire registers a new execution, it reports
+// false if the drainer no longer accepts any.
+func (d *drainer) acquire() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.closed {
+		return false
+	}
+
+	d.active++
+
+	return true
+}
+
+func (d *drainer) release() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.active--
+	if d.active == 0 && d.idle != nil {
+		close(d.idle)
+		d.idle = nil
+	}
+}
+
+func (d *drainer) track(r *Run) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.runs == nil {
+		d.runs = make(map[*Run]struct{})
+	}
+
+	d.runs[r] = struct{}{}
+}
+
+func (d *drainer) untrack(r *Run) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.runs, r)
+}
+
+// Shutdown stops the Executor from accepting new executions, and waits for
+// the ones in flight to finish. Exec, and everything built on top of it,
+// returns ErrShutdown once Shutdown is called.
+//
+// If ctx is done before all executions finish, every Run started by ExecAsync
+// is canceled with ErrShutdown as the cause, which stops it before the next
+// leaf Step, and Shutdown returns the error of ctx without waiting further.
+func (e *Executor[S]) Shutdown(ctx context.Context) error {
+	d := &e.drain
+
+	d.mu.Lock()
+	d.closed = true
+
+	if d.active == 0 {
+		d.mu.Unlock()
+		return nil
+	}
+
+	if d.idle == nil {
+		d.idle = make(chan struct{})
+	}
+
+	idle := d.idle
+	d.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for r := range d.runs {
+		r.Cancel(&ErrShutdown{})
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,93 @@
+package dagger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutor_Shutdown(t *testing.T) {
+	t.Run("Idle", func(t *testing.T) {
+		dag, err := New[testState](NewStep(noopStep))
+		assert.NoError(t, err)
+
+		assert.NoError(t, dag.Shutdown(context.TODO()))
+
+		errShutdown := new(ErrShutdown)
+		assert.ErrorAs(t, dag.Exec(context.TODO(), testState{}), &errShutdown)
+	})
+
+	t.Run("Drain", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		published := false
+
+		dag, err := New(Series(
+			NewStep(func(ctx context.Context, _ testState) error {
+				close(started)
+				<-release
+				return nil
+			}),
+			NewStep(func(ctx context.Context, _ testState) error {
+				published = true
+				return nil
+			}),
+		))
+		assert.NoError(t, err)
+
+		errCh := make(chan error)
+		go func() { errCh <- dag.Exec(context.TODO(), testState{}) }()
+		<-started
+
+		shutdown := make(chan error)
+		go func() { shutdown <- dag.Shutdown(context.TODO()) }()
+
+		select {
+		case <-shutdown:
+			t.Fatal("shutdown returned before the execution finished")
+		default:
+		}
+
+		close(release)
+		assert.NoError(t, <-errCh)
+		assert.NoError(t, <-shutdown)
+		assert.True(t, published)
+
+		errShutdown := new(ErrShutdown)
+		assert.ErrorAs(t, dag.ExecAsync(context.TODO(), testState{}).Wait(), &errShutdown)
+	})
+
+	t.Run("Deadline", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+		published := false
+
+		dag, err := New(Series(
+			NewStep(func(ctx context.Context, _ testState) error {
+				close(started)
+				<-release
+				return nil
+			}),
+			NewStep(func(ctx context.Context, _ testState) error {
+				published = true
+				return nil
+			}),
+		))
+		assert.NoError(t, err)
+
+		run := dag.ExecAsync(context.TODO(), testState{})
+		<-started
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+
+		assert.ErrorIs(t, dag.Shutdown(ctx), context.Canceled)
+
+		close(release)
+
+		errShutdown := new(ErrShutdown)
+		assert.ErrorAs(t, run.Wait(), &errShutdown)
+		assert.False(t, published)
+	})
+}
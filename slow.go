@@ -0,0 +1,34 @@
+package dagger
+
+import (
+	"context"
+	"time"
+)
+
+// OnSlow returns a middleware which calls report with the Info of a Step
+// and its duration, whenever the Step takes longer than its threshold,
+// regardless of the outcome of the Step.
+//
+// The threshold of each Step is decided once, by calling threshold with its
+// Info, e.g. based on its Name or Labels. Step(s) with a threshold of zero
+// are not timed at all.
+func OnSlow[S any](threshold func(info Info) time.Duration, report func(info Info, d time.Duration)) MiddlewareFunc[S] {
+	return func(next Step[S], info Info) Step[S] {
+		budget := threshold(info)
+		if budget <= 0 {
+			return next
+		}
+
+		return StepFunc[S](func(ctx context.Context, state S) error {
+			clock := ClockFromContext(ctx)
+			start := clock.Now()
+			err := next.Exec(ctx, state)
+
+			if d := clock.Now().Sub(start); d > budget {
+				report(info, d)
+			}
+
+			return err
+		})
+	}
+}
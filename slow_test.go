@@ -0,0 +1,38 @@
+package dagger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnSlow(t *testing.T) {
+	var slow []string
+
+	sleep := func(d time.Duration) Step[testState] {
+		return NewStep(func(ctx context.Context, state testState) error {
+			time.Sleep(d)
+			return nil
+		})
+	}
+
+	dag, err := New(Series(
+		Named("validate", sleep(0)),
+		WithLabels(Named("create", sleep(20*time.Millisecond)), map[string]string{"sla": "10ms"}),
+		Named("notify", sleep(20*time.Millisecond)),
+	))
+	assert.NoError(t, err)
+
+	assert.NoError(t, dag.Use(OnSlow[testState](func(info Info) time.Duration {
+		d, _ := time.ParseDuration(info.Labels["sla"])
+		return d
+	}, func(info Info, d time.Duration) {
+		assert.GreaterOrEqual(t, d, 20*time.Millisecond)
+		slow = append(slow, info.Name.String())
+	})))
+
+	assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+	assert.Equal(t, []string{"create"}, slow)
+}
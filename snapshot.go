@@ -0,0 +1,156 @@
+package dagger
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Snapshotter can be implemented by a state to control
+// what is captured by the SnapshotDiff middleware.
+type Snapshotter interface {
+	// Snapshot returns a JSON serializable copy of the state.
+	Snapshot() any
+}
+
+// StateDiff describes how a Step changed the state.
+type StateDiff struct {
+	// Step is the name of the Step which changed the state.
+	Step fmt.Stringer
+	// Changes are the changed fields, sorted by their Path.
+	Changes []FieldChange
+	// Err is set if the state could not be captured.
+	Err error
+}
+
+// FieldChange is a change to a single field of the state.
+type FieldChange struct {
+	// Path is the path of the field in the JSON representation
+	// of the state, e.g. `vm.disks[0].size`.
+	Path string
+	// Before is the value before the Step executed, nil if it was added.
+	Before any
+	// After is the value after the Step executed, nil if it was removed.
+	After any
+}
+
+// SnapshotDiff returns a middleware which captures the state before and
+// after each leaf Step and calls report with the differences, if any.
+//
+// The state is captured using Snapshotter, if it is implemented, otherwise
+// the state itself is captured, in both cases via its JSON representation.
+func SnapshotDiff[S any](report func(diff StateDiff)) MiddlewareFunc[S] {
+	return func(next Step[S], info Info) Step[S] {
+		if info.CanSkip {
+			return next
+		}
+
+		return NewStep(func(ctx context.Context, state S) error {
+			before, err := snapshot(state)
+			if err != nil {
+				report(StateDiff{Step: info.Name, Err: err})
+				return next.Exec(ctx, state)
+			}
+
+			stepErr := next.Exec(ctx, state)
+
+			after, err := snapshot(state)
+			if err != nil {
+				report(StateDiff{Step: info.Name, Err: err})
+				return stepErr
+			}
+
+			if changes := diffValues("", before, after, nil); len(changes) > 0 {
+				report(StateDiff{Step: info.Name, Changes: changes})
+			}
+
+			return stepErr
+		})
+	}
+}
+
+func snapshot(state any) (any, error) {
+	if s, ok := state.(Snapshotter); ok {
+		state = s.Snapshot()
+	}
+
+	b, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("dagger: cannot snapshot state: %w", err)
+	}
+
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("dagger: cannot snapshot state: %w", err)
+	}
+
+	return v, nil
+}
+
+// diffValues compares the decoded JSON values before and after,
+// and appends the changes found under path to changes.
+func diffValues(path string, before, after any, changes []FieldChange) []FieldChange {
+	switch b := before.(type) {
+	case map[string]any:
+		a, ok := after.(map[string]any)
+		if !ok {
+			break
+		}
+
+		keys := make([]string, 0, len(b)+len(a))
+		for k := range b {
+			keys = append(keys, k)
+		}
+
+		for k := range a {
+			if _, found := b[k]; !found {
+				keys = append(keys, k)
+			}
+		}
+
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			changes = diffValues(joinPath(path, k), b[k], a[k], changes)
+		}
+
+		return changes
+	case []any:
+		a, ok := after.([]any)
+		if !ok {
+			break
+		}
+
+		for i := 0; i < len(b) || i < len(a); i++ {
+			var bv, av any
+			if i < len(b) {
+				bv = b[i]
+			}
+
+			if i < len(a) {
+				av = a[i]
+			}
+
+			changes = diffValues(path+"["+strconv.Itoa(i)+"]", bv, av, changes)
+		}
+
+		return changes
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		changes = append(changes, FieldChange{Path: path, Before: before, After: after})
+	}
+
+	return changes
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
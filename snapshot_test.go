@@ -0,0 +1,91 @@
+package dagger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type vmState struct {
+	ID    string   `json:"id"`
+	Zone  string   `json:"zone"`
+	Disks []int    `json:"disks"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+type secretState struct {
+	Token string
+	Conn  chan struct{}
+}
+
+func (s *secretState) Snapshot() any { return map[string]bool{"hasToken": s.Token != ""} }
+
+func TestSnapshotDiff(t *testing.T) {
+	t.Run("LeafSteps", func(t *testing.T) {
+		var diffs []StateDiff
+
+		chain := NewChain(SnapshotDiff[*vmState](func(diff StateDiff) { diffs = append(diffs, diff) }))
+
+		step := chain.compile(Series(
+			Named("create", NewStep(func(_ context.Context, state *vmState) error {
+				state.ID = "vm-1"
+				state.Disks = append(state.Disks, 20)
+				return nil
+			})),
+			Named("noop", NewStep(func(_ context.Context, _ *vmState) error { return nil })),
+			Named("tag", NewStep(func(_ context.Context, state *vmState) error {
+				state.Tags = []string{"prod"}
+				state.Disks[0] = 40
+				return testErrStep
+			})),
+		))
+
+		err := step.Exec(context.TODO(), &vmState{Zone: "z1", Disks: []int{10}})
+		assert.ErrorIs(t, err, testErrStep)
+
+		assert.Len(t, diffs, 2)
+		assert.Equal(t, "create", diffs[0].Step.String())
+		assert.Equal(t, []FieldChange{
+			{Path: "disks[1]", Before: nil, After: float64(20)},
+			{Path: "id", Before: "", After: "vm-1"},
+		}, diffs[0].Changes)
+		assert.Equal(t, "tag", diffs[1].Step.String())
+		assert.Equal(t, []FieldChange{
+			{Path: "disks[0]", Before: float64(10), After: float64(40)},
+			{Path: "tags", Before: nil, After: []any{"prod"}},
+		}, diffs[1].Changes)
+	})
+
+	t.Run("Snapshotter", func(t *testing.T) {
+		var diffs []StateDiff
+
+		chain := NewChain(SnapshotDiff[*secretState](func(diff StateDiff) { diffs = append(diffs, diff) }))
+
+		step := chain.compile(NewStep(func(_ context.Context, state *secretState) error {
+			state.Token = "secret"
+			return nil
+		}))
+
+		assert.NoError(t, step.Exec(context.TODO(), &secretState{}))
+		assert.Len(t, diffs, 1)
+		assert.Equal(t, []FieldChange{{Path: "hasToken", Before: false, After: true}}, diffs[0].Changes)
+	})
+
+	t.Run("NotSerializable", func(t *testing.T) {
+		var diffs []StateDiff
+
+		chain := NewChain(SnapshotDiff[chan int](func(diff StateDiff) { diffs = append(diffs, diff) }))
+
+		ran := false
+		step := chain.compile(NewStep(func(_ context.Context, _ chan int) error {
+			ran = true
+			return nil
+		}))
+
+		assert.NoError(t, step.Exec(context.TODO(), make(chan int)))
+		assert.True(t, ran)
+		assert.Len(t, diffs, 1)
+		assert.Error(t, diffs[0].Err)
+	})
+}
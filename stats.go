@@ -0,0 +1,188 @@
+package dagger
+
+import (
+	"context"
+	"expvar"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxStatsSamples is the number of most recent durations
+// kept per Step to compute the latency percentiles.
+const maxStatsSamples = 1024
+
+// Stats are the statistics of the executions of an Executor.
+type Stats struct {
+	// Name is the name of the Executor, if set with WithName.
+	Name     string `json:",omitempty"`
+	Runs     int64
+	Failures int64
+	// Steps holds the statistics of each leaf Step by its Sanitized name,
+	// e.g. pkg_stepName, or of each group of them, see GroupByLabel. It is
+	// only populated once enabled with Executor.EnableStepStats.
+	Steps map[string]StepStats `json:",omitempty"`
+}
+
+// StepStats are the statistics of a leaf Step. The latency percentiles
+// are computed over the most recent executions of the Step.
+type StepStats struct {
+	Count    int64
+	Failures int64
+	P50      time.Duration
+	P90      time.Duration
+	P99      time.Duration
+}
+
+type executorStats struct {
+	runs     atomic.Int64
+	failures atomic.Int64
+
+	mu    sync.Mutex
+	steps map[string]*stepSamples
+}
+
+type stepSamples struct {
+	count     int64
+	failures  int64
+	durations []time.Duration
+	next      int
+}
+
+func (s *executorStats) record(err error) {
+	s.runs.Add(1)
+
+	if err != nil {
+		s.failures.Add(1)
+	}
+}
+
+func (s *executorStats) recordStep(name string, d time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.steps == nil {
+		s.steps = make(map[string]*stepSamples)
+	}
+
+	ss, ok := s.steps[name]
+	if !ok {
+		ss = &stepSamples{}
+		s.steps[name] = ss
+	}
+
+	ss.count++
+	if err != nil {
+		ss.failures++
+	}
+
+	if len(ss.durations) < maxStatsSamples {
+		ss.durations = append(ss.durations, d)
+		return
+	}
+
+	ss.durations[ss.next] = d
+	ss.next = (ss.next + 1) % maxStatsSamples
+}
+
+// Stats returns the statistics of the executions of the Executor.
+// Executions returning ErrSkip or ErrAbort are not counted as failures.
+func (e *Executor[S]) Stats() Stats {
+	stats := Stats{Name: e.cfg.name, Runs: e.stats.runs.Load(), Failures: e.stats.failures.Load()}
+
+	e.stats.mu.Lock()
+	defer e.stats.mu.Unlock()
+
+	if len(e.stats.steps) > 0 {
+		stats.Steps = make(map[string]StepStats, len(e.stats.steps))
+	}
+
+	for name, ss := range e.stats.steps {
+		sorted := append([]time.Duration(nil), ss.durations...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		stats.Steps[name] = StepStats{
+			Count:    ss.count,
+			Failures: ss.failures,
+			P50:      percentile(sorted, 50),
+			P90:      percentile(sorted, 90),
+			P99:      percentile(sorted, 99),
+		}
+	}
+
+	return stats
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	return sorted[(len(sorted)-1)*p/100]
+}
+
+// StepStatsOption configures EnableStepStats.
+type StepStatsOption func(*stepStatsConfig)
+
+type stepStatsConfig struct {
+	groupBy string
+}
+
+// GroupByLabel aggregates the statistics of leaf Step(s) by the value of their
+// label key, e.g. team or stage, rather than by name, to keep the cardinality
+// of the metrics low for DAGs with hundreds of closures. Stats.Steps is then
+// keyed by the Sanitized label value, and Step(s) without the label are
+// aggregated under the empty key.
+func GroupByLabel(key string) StepStatsOption {
+	return func(c *stepStatsConfig) { c.groupBy = key }
+}
+
+// EnableStepStats makes the Executor collect the statistics of each leaf Step,
+// which are reported by Stats. It costs a middleware per leaf Step.
+// It returns ErrFrozen if the Executor has already executed.
+func (e *Executor[S]) EnableStepStats(opts ...StepStatsOption) error {
+	var cfg stepStatsConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	err := e.Use(func(next Step[S], info Info) Step[S] {
+		if info.CanSkip {
+			return next
+		}
+
+		name := sanitizedName(info.Name)
+		if cfg.groupBy != "" {
+			name = sanitize(info.Labels[cfg.groupBy])
+		}
+
+		return StepFunc[S](func(ctx context.Context, state S) error {
+			clock := ClockFromContext(ctx)
+			start := clock.Now()
+			err := next.Exec(ctx, state)
+
+			// The stats are read from ctx rather than e, since
+			// the middleware is shared with the Clone(s) of e.
+			if stats, ok := ctx.Value(statsKey).(*executorStats); ok && !warmingUp(ctx) {
+				stats.recordStep(name, clock.Now().Sub(start), ignoreSkip(err))
+			}
+
+			return err
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	e.stepStats.Store(true)
+
+	return nil
+}
+
+// PublishExpvar publishes the Stats of the Executor with expvar under the
+// given name. Like expvar.Publish, it panics if the name is already in use,
+// e.g. by another Executor, or if it is called twice for the same name.
+func (e *Executor[S]) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any { return e.Stats() }))
+}
@@ -0,0 +1,133 @@
+package dagger
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutor_Stats(t *testing.T) {
+	dag, err := New(Series(
+		Named("validate", NewStep(func(ctx context.Context, state int) error {
+			time.Sleep(time.Duration(state) * time.Millisecond)
+			return nil
+		})),
+		Named("create", NewStep(func(ctx context.Context, state int) error {
+			if state%2 == 1 {
+				return testErrStep
+			}
+
+			return nil
+		})),
+	))
+	assert.NoError(t, err)
+	assert.NoError(t, dag.EnableStepStats())
+
+	for i := 0; i < 4; i++ {
+		_ = dag.Exec(context.TODO(), i)
+	}
+
+	assert.NoError(t, dag.ExecAsync(context.TODO(), 2).Wait())
+
+	stats := dag.Stats()
+	assert.Equal(t, int64(5), stats.Runs)
+	assert.Equal(t, int64(2), stats.Failures)
+	assert.Len(t, stats.Steps, 2)
+
+	validate := stats.Steps["validate"]
+	assert.Equal(t, int64(5), validate.Count)
+	assert.Zero(t, validate.Failures)
+	assert.GreaterOrEqual(t, validate.P50, 2*time.Millisecond)
+	assert.GreaterOrEqual(t, validate.P99, validate.P90)
+	assert.GreaterOrEqual(t, validate.P90, validate.P50)
+
+	assert.Equal(t, StepStats{Count: 5, Failures: 2}, StepStats{
+		Count:    stats.Steps["create"].Count,
+		Failures: stats.Steps["create"].Failures,
+	})
+
+	t.Run("SanitizedNames", func(t *testing.T) {
+		dag, err := New(Series(Named("create-vm", NewStep(noopStep)), NewStep(noopStep)))
+		assert.NoError(t, err)
+		assert.NoError(t, dag.EnableStepStats())
+		assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+
+		names := make([]string, 0, 2)
+		for name := range dag.Stats().Steps {
+			names = append(names, name)
+		}
+
+		assert.ElementsMatch(t, []string{"create_vm", "dagger_noopStep"}, names)
+	})
+
+	t.Run("GroupByLabel", func(t *testing.T) {
+		payments := map[string]string{"team": "payments"}
+
+		dag, err := New(Series(
+			WithLabels(Named("charge", NewStep(noopStep)), payments),
+			WithLabels(Named("refund", NewStep(noopStep)), payments),
+			WithLabels(Named("create", NewStep(noopStep)), map[string]string{"team": "compute-infra"}),
+			Named("notify", NewStep(noopStep)),
+		))
+		assert.NoError(t, err)
+		assert.NoError(t, dag.EnableStepStats(GroupByLabel("team")))
+		assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+
+		counts := make(map[string]int64)
+		for name, ss := range dag.Stats().Steps {
+			counts[name] = ss.Count
+		}
+
+		assert.Equal(t, map[string]int64{"payments": 2, "compute_infra": 1, "": 1}, counts)
+	})
+
+	t.Run("Clone", func(t *testing.T) {
+		dag, err := New(Named("create", NewStep(noopStep)))
+		assert.NoError(t, err)
+		assert.NoError(t, dag.EnableStepStats())
+
+		clone := dag.Clone()
+		assert.NoError(t, clone.Exec(context.TODO(), testState{}))
+
+		assert.Equal(t, int64(1), clone.Stats().Steps["create"].Count)
+		assert.Empty(t, dag.Stats().Steps)
+	})
+
+	t.Run("WithoutStepStats", func(t *testing.T) {
+		dag, err := New[int](NewStep(func(ctx context.Context, state int) error { return nil }))
+		assert.NoError(t, err)
+		assert.NoError(t, dag.Exec(context.TODO(), 0))
+
+		assert.Equal(t, Stats{Runs: 1}, dag.Stats())
+	})
+
+	t.Run("Expvar", func(t *testing.T) {
+		// expvar names are global, and can not be published twice, e.g. with -count
+		name := fmt.Sprintf("dagger_test_stats_%d", expvarRuns.Add(1))
+		dag.PublishExpvar(name)
+
+		var published Stats
+		assert.NoError(t, json.Unmarshal([]byte(expvar.Get(name).String()), &published))
+		assert.Equal(t, int64(5), published.Runs)
+		assert.Equal(t, int64(5), published.Steps["validate"].Count)
+	})
+}
+
+var expvarRuns atomic.Int32
+
+func Test_stepSamples(t *testing.T) {
+	var s executorStats
+
+	for i := 0; i < maxStatsSamples+10; i++ {
+		s.recordStep("s", time.Duration(i), nil)
+	}
+
+	assert.Len(t, s.steps["s"].durations, maxStatsSamples)
+	assert.Equal(t, 10, s.steps["s"].next)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"sync"
 )
 
 // Step is a unit of work to be performed in the DAG.
@@ -25,22 +27,40 @@ var _ Step[any] = (*StepFunc[any])(nil)
 // branch selector for Step(s).
 type Selector[S any] func(state S) bool
 
+// SelectorCtx is a Selector which can consult the context, e.g. for deadlines
+// or feature flags loaded per request, and fail. Its error is returned by the
+// conditional Step using it, wrapped in ErrSelector.
+type SelectorCtx[S any] func(ctx context.Context, state S) (bool, error)
+
 type StepErrorHandler[S any] func(ctx context.Context, state S, err error) Step[S]
 
 type ifStep[S any] struct {
-	condition Selector[S]
-	thenStep  Step[S]
+	condition    Selector[S]
+	conditionCtx SelectorCtx[S]
+	name         fmt.Stringer
+	thenStep     Step[S]
 }
 
-var _ middlewareSkipper = (*ifStep[any])(nil)
+var (
+	_ middlewareSkipper = (*ifStep[any])(nil)
+	_ selectorNamer     = (*ifStep[any])(nil)
+	_ rebuilder[any]    = (*ifStep[any])(nil)
+)
+
+func (s *ifStep[S]) selectorName() fmt.Stringer { return s.name }
 
 func (s *ifStep[S]) canSkip() bool {
 	return true
 }
 
 func (s *ifStep[S]) Exec(ctx context.Context, state S) error {
-	if s.condition(state) {
-		return execWithContext(ctx, s.thenStep, state)
+	ok, err := evalCondition(ctx, state, s.condition, s.conditionCtx, s.name)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		return s.thenStep.Exec(ctx, state)
 	}
 
 	return nil
@@ -48,66 +68,199 @@ func (s *ifStep[S]) Exec(ctx context.Context, state S) error {
 
 func (s *ifStep[S]) Unwrap() Step[S] { return s.thenStep }
 
+func (s *ifStep[S]) rebuild(wrap wrapFunc[S]) Step[S] {
+	return &ifStep[S]{
+		condition:    s.condition,
+		conditionCtx: s.conditionCtx,
+		name:         s.name,
+		thenStep:     wrap("then", s.thenStep),
+	}
+}
+
+// evalCondition evaluates the condition of a conditional Step, which
+// is either a Selector or a SelectorCtx, wrapping the error of the latter.
+func evalCondition[S any](
+	ctx context.Context,
+	state S,
+	condition Selector[S],
+	conditionCtx SelectorCtx[S],
+	name fmt.Stringer,
+) (ok bool, err error) {
+	if enabled, _ := ctx.Value(recoverSelectorsKey).(bool); enabled {
+		defer func() {
+			if r := recover(); r != nil {
+				ok, err = false, &ErrSelector{selector: name, err: &ErrPanic{value: r}}
+			}
+		}()
+	}
+
+	if conditionCtx == nil {
+		return condition(state), nil
+	}
+
+	ok, err = conditionCtx(ctx, state)
+	if err != nil {
+		return false, &ErrSelector{selector: name, err: err}
+	}
+
+	return ok, nil
+}
+
 // If Step takes in a Selector and runs the thenStep, iff Selector returns true.
 func If[S any](condition Selector[S], thenStep Step[S]) Step[S] {
-	return &ifStep[S]{condition: condition, thenStep: thenStep}
+	return &ifStep[S]{condition: condition, name: SelectorName(condition), thenStep: thenStep}
 }
 
 // IfNot Step takes in a Selector and runs the thenStep, iff Selector returns false.
 func IfNot[S any](condition Selector[S], thenStep Step[S]) Step[S] {
-	return &ifStep[S]{condition: func(state S) bool { return !condition(state) }, thenStep: thenStep}
+	var meta selectorMeta
+	if v, ok := constSelector(condition); ok {
+		meta = selectorMeta{constant: true, value: !v}
+	}
+
+	not := newSelector(func(state S) bool { return !condition(state) }, meta)
+
+	return &ifStep[S]{
+		condition: not,
+		name:      fmtStr("!" + SelectorName(condition).String()),
+		thenStep:  thenStep,
+	}
 }
 
 type ifElseStep[S any] struct {
-	condition Selector[S]
-	thenStep  Step[S]
-	elseStep  Step[S]
+	condition    Selector[S]
+	conditionCtx SelectorCtx[S]
+	name         fmt.Stringer
+	thenStep     Step[S]
+	elseStep     Step[S]
 }
 
-var _ middlewareSkipper = (*ifElseStep[any])(nil)
+var (
+	_ middlewareSkipper = (*ifElseStep[any])(nil)
+	_ selectorNamer     = (*ifElseStep[any])(nil)
+	_ rebuilder[any]    = (*ifElseStep[any])(nil)
+)
+
+func (s *ifElseStep[S]) selectorName() fmt.Stringer { return s.name }
 
 func (s *ifElseStep[S]) canSkip() bool {
 	return true
 }
 
 func (s *ifElseStep[S]) Exec(ctx context.Context, state S) error {
-	if s.condition(state) {
-		return execWithContext(ctx, s.thenStep, state)
+	ok, err := evalCondition(ctx, state, s.condition, s.conditionCtx, s.name)
+	if err != nil {
+		return err
 	}
 
-	return execWithContext(ctx, s.elseStep, state)
+	if ok {
+		return s.thenStep.Exec(ctx, state)
+	}
+
+	return s.elseStep.Exec(ctx, state)
 }
 
 func (s *ifElseStep[S]) Unwrap() []Step[S] { return []Step[S]{s.thenStep, s.elseStep} }
 
+func (s *ifElseStep[S]) rebuild(wrap wrapFunc[S]) Step[S] {
+	return &ifElseStep[S]{
+		condition:    s.condition,
+		conditionCtx: s.conditionCtx,
+		name:         s.name,
+		thenStep:     wrap("then", s.thenStep),
+		elseStep:     wrap("else", s.elseStep),
+	}
+}
+
 // IfElse takes in a Selector and
 //   - executes the thenStep, if the Selector returns true
 //   - executes the elseStep, if the Selector returns false
 func IfElse[S any](condition Selector[S], thenStep, elseStep Step[S]) Step[S] {
-	return &ifElseStep[S]{condition: condition, thenStep: thenStep, elseStep: elseStep}
+	return &ifElseStep[S]{
+		condition: condition,
+		name:      SelectorName(condition),
+		thenStep:  thenStep,
+		elseStep:  elseStep,
+	}
+}
+
+// IfCtx is the same as If with a SelectorCtx, an error
+// returned by the SelectorCtx is returned by the Step.
+func IfCtx[S any](condition SelectorCtx[S], thenStep Step[S]) Step[S] {
+	return &ifStep[S]{conditionCtx: condition, name: selectorCtxName(condition), thenStep: thenStep}
+}
+
+// IfNotCtx is the same as IfNot with a SelectorCtx, an error
+// returned by the SelectorCtx is returned by the Step.
+func IfNotCtx[S any](condition SelectorCtx[S], thenStep Step[S]) Step[S] {
+	return &ifStep[S]{
+		conditionCtx: func(ctx context.Context, state S) (bool, error) {
+			ok, err := condition(ctx, state)
+			return !ok, err
+		},
+		name:     fmtStr("!" + selectorCtxName(condition).String()),
+		thenStep: thenStep,
+	}
+}
+
+// IfElseCtx is the same as IfElse with a SelectorCtx, an error returned by
+// the SelectorCtx is returned by the Step, without executing either Step.
+func IfElseCtx[S any](condition SelectorCtx[S], thenStep, elseStep Step[S]) Step[S] {
+	return &ifElseStep[S]{
+		conditionCtx: condition,
+		name:         selectorCtxName(condition),
+		thenStep:     thenStep,
+		elseStep:     elseStep,
+	}
 }
 
 type resultStep[S any] struct {
 	mainStep       Step[S]
 	successStep    Step[S]
 	failureHandler StepErrorHandler[S]
+	// wrapFailure is applied to the Step returned by failureHandler,
+	// since it is only known during execution.
+	wrapFailure func(Step[S]) Step[S]
+	cfg         resultConfig
 }
 
-var _ middlewareSkipper = (*resultStep[any])(nil)
+var (
+	_ middlewareSkipper = (*resultStep[any])(nil)
+	_ rebuilder[any]    = (*resultStep[any])(nil)
+)
 
 func (s *resultStep[S]) canSkip() bool {
 	return true
 }
 
 func (s *resultStep[S]) Exec(ctx context.Context, state S) error {
-	if err := execWithContext(ctx, s.mainStep, state); err != nil {
-		return execWithContext(ctx, s.failureHandler(ctx, state, err), state)
+	if err := ignoreSkip(s.mainStep.Exec(ctx, state)); err != nil {
+		if _, ok := asAbort(err); ok || s.failureHandler == nil {
+			return err
+		}
+
+		failureStep := s.failureHandler(ctx, state, err)
+		if s.wrapFailure != nil {
+			failureStep = s.wrapFailure(failureStep)
+		}
+
+		failureErr := failureStep.Exec(context.WithValue(ctx, failureKey, err), state)
+
+		return s.cfg.policy.resolve(err, ignoreSkip(failureErr))
+	}
+
+	if s.successStep == nil {
+		return nil
 	}
 
-	return execWithContext(ctx, s.successStep, state)
+	return ignoreSkip(s.successStep.Exec(ctx, state))
 }
 
 func (s *resultStep[S]) Unwrap() []Step[S] {
+	if s.successStep == nil {
+		return []Step[S]{s.mainStep}
+	}
+
 	return []Step[S]{
 		s.mainStep,
 		s.successStep,
@@ -115,26 +268,145 @@ func (s *resultStep[S]) Unwrap() []Step[S] {
 	}
 }
 
+// failureHandlerName returns the name of the failure handler, if any, the
+// Step(s) it returns are only known at runtime, so they are not in the DAG.
+func (s *resultStep[S]) failureHandlerName() fmt.Stringer {
+	if s.failureHandler == nil {
+		return nil
+	}
+
+	return funcName(s.failureHandler)
+}
+
+func (s *resultStep[S]) rebuild(wrap wrapFunc[S]) Step[S] {
+	r := &resultStep[S]{
+		mainStep:       wrap("main", s.mainStep),
+		failureHandler: s.failureHandler,
+		wrapFailure:    func(failureStep Step[S]) Step[S] { return wrap(failureEdge, failureStep) },
+		cfg:            s.cfg,
+	}
+
+	if s.successStep != nil {
+		r.successStep = wrap("success", s.successStep)
+	}
+
+	return r
+}
+
 // Result Step executes the mainStep and uses the returned value to
 //   - execute successStep, if the returned error is nil
 //   - call failureHandler to execute returned step, if the returned error is not nil
 //
+// An ErrAbort returned by mainStep is returned as is, without calling failureHandler.
+// By default, the error returned by the Step returned by failureHandler replaces
+// the error of mainStep, use WithErrorPolicy to change it. With WrapError and
+// JoinErrors, an ErrAbort returned by that Step does not abort the DAG, the
+// error of mainStep is returned instead.
+//
 // Note: It is recommended to make sure that the Step returned by
 // failureHandler does not contain any cycles, use New on all possible
 // return Step(s) to assert it in unit tests.
-func Result[S any](mainStep, successStep Step[S], failureHandler StepErrorHandler[S]) Step[S] {
-	return &resultStep[S]{
+func Result[S any](mainStep, successStep Step[S], failureHandler StepErrorHandler[S], opts ...ResultOption) Step[S] {
+	return newResultStep(mainStep, successStep, failureHandler, opts)
+}
+
+// OnFailure is the same as Result without a successStep, the error
+// returned by mainStep is handled by failureHandler, if any.
+func OnFailure[S any](mainStep Step[S], failureHandler StepErrorHandler[S], opts ...ResultOption) Step[S] {
+	return newResultStep(mainStep, nil, failureHandler, opts)
+}
+
+// OnSuccess is the same as Result without a failureHandler, the
+// error returned by mainStep, if any, is returned as is.
+func OnSuccess[S any](mainStep, successStep Step[S]) Step[S] {
+	return &resultStep[S]{mainStep: mainStep, successStep: successStep}
+}
+
+func newResultStep[S any](mainStep, successStep Step[S], failureHandler StepErrorHandler[S], opts []ResultOption) Step[S] {
+	s := &resultStep[S]{
 		mainStep:       mainStep,
 		successStep:    successStep,
 		failureHandler: failureHandler,
 	}
+
+	for _, opt := range opts {
+		opt(&s.cfg)
+	}
+
+	return s
+}
+
+// Rethrow returns the error of the mainStep of the Result executing the
+// failure Step with ctx. It lets a failure Step perform side effects,
+// e.g. a rollback, and then propagate the original error by returning it.
+// Middlewares wrapping a Step with Info.FailureBranch set can use it to
+// tell why the Step is executed, e.g. to tag a rollback with its cause.
+//
+// It returns nil if ctx does not belong to a failure Step.
+func Rethrow(ctx context.Context) error {
+	err, _ := ctx.Value(failureKey).(error)
+	return err
+}
+
+// ErrorPolicy decides the error returned by Result, when mainStep fails,
+// from the error of mainStep and the error of the failure Step.
+type ErrorPolicy int
+
+const (
+	// ReplaceError returns the error of the failure Step,
+	// the error of mainStep is dropped.
+	ReplaceError ErrorPolicy = iota
+	// WrapError returns the error of the failure Step wrapping the error
+	// of mainStep, it returns nil if the failure Step succeeds.
+	WrapError
+	// JoinErrors returns the error of mainStep joined with the error of the
+	// failure Step, the error of mainStep is returned even if the failure
+	// Step succeeds.
+	JoinErrors
+)
+
+// resolve returns the error of Result. With WrapError and JoinErrors, an
+// ErrAbort of the failure Step is dropped in favour of the error of mainStep,
+// since the DAG would otherwise be aborted, hiding the failure of mainStep.
+func (p ErrorPolicy) resolve(mainErr, failureErr error) error {
+	if _, ok := asAbort(failureErr); ok && p != ReplaceError {
+		return mainErr
+	}
+
+	switch p {
+	case WrapError:
+		if failureErr == nil {
+			return nil
+		}
+
+		return fmt.Errorf("%w (while handling: %w)", failureErr, mainErr)
+	case JoinErrors:
+		return errors.Join(mainErr, failureErr)
+	}
+
+	return failureErr
+}
+
+// ResultOption configures Result and OnFailure.
+type ResultOption func(*resultConfig)
+
+type resultConfig struct {
+	policy ErrorPolicy
+}
+
+// WithErrorPolicy sets the ErrorPolicy of Result, it defaults to ReplaceError.
+func WithErrorPolicy(p ErrorPolicy) ResultOption {
+	return func(c *resultConfig) { c.policy = p }
 }
 
 type seriesStep[S any] struct {
 	steps []Step[S]
 }
 
-var _ middlewareSkipper = (*seriesStep[any])(nil)
+var (
+	_ middlewareSkipper = (*seriesStep[any])(nil)
+	_ rebuilder[any]    = (*seriesStep[any])(nil)
+)
 
 func (s *seriesStep[S]) canSkip() bool {
 	return true
@@ -142,7 +414,7 @@ func (s *seriesStep[S]) canSkip() bool {
 
 func (s *seriesStep[S]) Exec(ctx context.Context, state S) error {
 	for _, step := range s.steps {
-		if err := execWithContext(ctx, step, state); err != nil {
+		if err := ignoreSkip(step.Exec(ctx, state)); err != nil {
 			return err
 		}
 	}
@@ -152,29 +424,66 @@ func (s *seriesStep[S]) Exec(ctx context.Context, state S) error {
 
 func (s *seriesStep[S]) Unwrap() []Step[S] { return s.steps }
 
+func (s *seriesStep[S]) rebuild(wrap wrapFunc[S]) Step[S] {
+	return &seriesStep[S]{steps: wrapEach(s.steps, wrap)}
+}
+
 // Series Step executes the given steps one-by-one in sequence,
 // if any Step returns an error, Series also returns that same
-// error and skips the remaining Step(s).
+// error and skips the remaining Step(s). This includes ErrAbort,
+// which stops the DAG without failing it.
 func Series[S any](steps ...Step[S]) Step[S] {
 	return &seriesStep[S]{steps: steps}
 }
 
 type continueStep[S any] struct {
 	steps []Step[S]
+	cfg   continueConfig
 }
 
-var _ middlewareSkipper = (*continueStep[any])(nil)
+var (
+	_ middlewareSkipper = (*continueStep[any])(nil)
+	_ rebuilder[any]    = (*continueStep[any])(nil)
+)
 
 func (s *continueStep[S]) canSkip() bool {
 	return true
 }
 
 func (s *continueStep[S]) Exec(ctx context.Context, state S) error {
-	var err error
+	var (
+		err      error
+		failures int
+	)
 
 	for _, step := range s.steps {
-		if stepErr := execWithContext(ctx, step, state); stepErr != nil {
-			err = errors.Join(err, fmt.Errorf("error executing step %s: %w", StepName(step), stepErr))
+		stepErr := ignoreSkip(step.Exec(ctx, state))
+		if stepErr == nil {
+			continue
+		}
+
+		if _, ok := asAbort(stepErr); ok {
+			if err != nil {
+				return err
+			}
+
+			return stepErr
+		}
+
+		stepErr = wrapStepError(ctx, step, stepErr)
+
+		if s.cfg.isWarning != nil && s.cfg.isWarning(stepErr) {
+			if r := ResultsFromContext(ctx); r != nil {
+				r.addWarning(stepErr)
+			}
+
+			continue
+		}
+
+		err = errors.Join(err, stepErr)
+
+		if failures++; s.cfg.limited && failures > s.cfg.maxFailures {
+			return err
 		}
 	}
 
@@ -183,14 +492,216 @@ func (s *continueStep[S]) Exec(ctx context.Context, state S) error {
 
 func (s *continueStep[S]) Unwrap() []Step[S] { return s.steps }
 
+func (s *continueStep[S]) rebuild(wrap wrapFunc[S]) Step[S] {
+	return &continueStep[S]{steps: wrapEach(s.steps, wrap), cfg: s.cfg}
+}
+
 // Continue Step executes the given steps one-by-one in sequence.
 // It executes all steps, accumulates all errors encountered and returns
 // them using `errors.Join()`.
 // This step is particularly helpful when we want to run certain steps in an order,
 // but not stop execution if any step returns an error.
+//
+// An ErrAbort stops the execution of the remaining steps, it is returned
+// only if no step failed before it, so that aborting never hides failures.
 func Continue[S any](steps ...Step[S]) Step[S] {
 	return &continueStep[S]{steps: steps}
 }
 
+// ContinueOption configures ContinueWith.
+type ContinueOption func(*continueConfig)
+
+type continueConfig struct {
+	isWarning func(err error) bool
+	// maxFailures is only enforced if limited is set,
+	// the zero continueConfig does not limit failures.
+	maxFailures int
+	limited     bool
+}
+
+// WithWarnings classifies the errors of the steps for which isWarning returns
+// true as warnings. Warnings are not returned, they are added to the Results
+// carried by the context, if any, see Results.Warnings.
+func WithWarnings(isWarning func(err error) bool) ContinueOption {
+	return func(c *continueConfig) { c.isWarning = isWarning }
+}
+
+// WithMaxFailures stops executing the remaining steps once more
+// than n of them fail, warnings are not counted as failures.
+// With n = 0, the first failure stops the execution, like Series,
+// and a negative n does not limit the failures, like Continue.
+func WithMaxFailures(n int) ContinueOption {
+	return func(c *continueConfig) { c.maxFailures, c.limited = n, n >= 0 }
+}
+
+// ContinueN is the same as Continue, except that it stops executing
+// the remaining steps once more than maxFailures of them fail, see
+// WithMaxFailures.
+func ContinueN[S any](maxFailures int, steps ...Step[S]) Step[S] {
+	return ContinueWith(steps, WithMaxFailures(maxFailures))
+}
+
+// ContinueWith is the same as Continue, configured with the given options.
+func ContinueWith[S any](steps []Step[S], opts ...ContinueOption) Step[S] {
+	var cfg continueConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return &continueStep[S]{steps: steps, cfg: cfg}
+}
+
+type parallelStep[S any] struct {
+	steps []Step[S]
+	cfg   parallelConfig
+	// order holds the indices of the steps,
+	// by descending priority, see WithPriority.
+	order []int
+}
+
+var (
+	_ middlewareSkipper = (*parallelStep[any])(nil)
+	_ rebuilder[any]    = (*parallelStep[any])(nil)
+)
+
+func newParallelStep[S any](steps []Step[S], cfg parallelConfig) *parallelStep[S] {
+	order := make([]int, len(steps))
+	priorities := make([]int, len(steps))
+
+	for i, step := range steps {
+		order[i] = i
+		priorities[i] = stepPriority(step)
+	}
+
+	sort.SliceStable(order, func(a, b int) bool { return priorities[order[a]] > priorities[order[b]] })
+
+	return &parallelStep[S]{steps: steps, cfg: cfg, order: order}
+}
+
+func (s *parallelStep[S]) canSkip() bool {
+	return true
+}
+
+func (s *parallelStep[S]) Exec(ctx context.Context, state S) error {
+	errs := make([]error, len(s.steps))
+	aborts := make([]error, len(s.steps))
+
+	var (
+		wg  sync.WaitGroup
+		sem chan struct{}
+	)
+
+	if s.cfg.maxConcurrency > 0 {
+		sem = make(chan struct{}, s.cfg.maxConcurrency)
+	}
+
+	for _, i := range s.order {
+		if sem != nil {
+			sem <- struct{}{}
+		}
+
+		wg.Add(1)
+
+		go func(i int, step Step[S]) {
+			defer wg.Done()
+
+			if sem != nil {
+				defer func() { <-sem }()
+			}
+
+			stepErr := ignoreSkip(step.Exec(ctx, state))
+			if _, ok := asAbort(stepErr); ok {
+				aborts[i] = stepErr
+			} else if stepErr != nil {
+				errs[i] = wrapStepError(ctx, step, stepErr)
+			}
+		}(i, s.steps[i])
+	}
+
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	for _, abort := range aborts {
+		if abort != nil {
+			return abort
+		}
+	}
+
+	return nil
+}
+
+func (s *parallelStep[S]) Unwrap() []Step[S] { return s.steps }
+
+// rebuild keeps the order, since the priorities
+// are not visible through the wrapped steps.
+func (s *parallelStep[S]) rebuild(wrap wrapFunc[S]) Step[S] {
+	return &parallelStep[S]{steps: wrapEach(s.steps, wrap), cfg: s.cfg, order: s.order}
+}
+
+// Parallel Step executes the given steps concurrently and waits for all of
+// them to finish. It accumulates all errors encountered and returns them
+// using `errors.Join()`.
+//
+// An ErrAbort does not stop the other steps, it is returned once
+// all of them finish, only if none of them failed.
+//
+// The state is shared by all the steps, they must not modify the same
+// parts of it without synchronization.
+func Parallel[S any](steps ...Step[S]) Step[S] {
+	return newParallelStep(steps, parallelConfig{})
+}
+
+// ParallelOption configures ParallelWith.
+type ParallelOption func(*parallelConfig)
+
+type parallelConfig struct {
+	maxConcurrency int
+}
+
+// WithMaxConcurrency limits the number of steps executing at the same time,
+// the steps are started by descending priority, see WithPriority.
+func WithMaxConcurrency(n int) ParallelOption {
+	return func(c *parallelConfig) { c.maxConcurrency = n }
+}
+
+// ParallelN is the same as Parallel, except that at most maxConcurrency
+// of the steps execute at the same time, see WithMaxConcurrency.
+func ParallelN[S any](maxConcurrency int, steps ...Step[S]) Step[S] {
+	return ParallelWith(steps, WithMaxConcurrency(maxConcurrency))
+}
+
+// ParallelWith is the same as Parallel, configured with the given options.
+func ParallelWith[S any](steps []Step[S], opts ...ParallelOption) Step[S] {
+	var cfg parallelConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return newParallelStep(steps, cfg)
+}
+
 // NewStep is a helper function to create a StepFunc without explicit mention of generic S.
 func NewStep[S any](f func(ctx context.Context, state S) error) StepFunc[S] { return f }
+
+type ctxFuncStep[S any] func(ctx context.Context) error
+
+func (f ctxFuncStep[S]) Exec(ctx context.Context, _ S) error { return f(ctx) }
+
+func (f ctxFuncStep[S]) StepName() fmt.Stringer { return funcName(f) }
+
+type errFuncStep[S any] func() error
+
+func (f errFuncStep[S]) Exec(context.Context, S) error { return f() }
+
+func (f errFuncStep[S]) StepName() fmt.Stringer { return funcName(f) }
+
+// FromFunc lifts a function which does not need the state, like closing
+// a resource, into a Step. The Step is named after the function.
+func FromFunc[S any](f func(ctx context.Context) error) Step[S] { return ctxFuncStep[S](f) }
+
+// FromErrFunc lifts a function which needs neither the context nor the state,
+// like flushing a cache, into a Step. The Step is named after the function.
+func FromErrFunc[S any](f func() error) Step[S] { return errFuncStep[S](f) }
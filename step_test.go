@@ -1,9 +1,13 @@
 package dagger
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -67,6 +71,104 @@ func TestIfElse(t *testing.T) {
 	assert.Equal(t, 3, count)
 }
 
+type flagKey struct{}
+
+func flagEnabled(ctx context.Context, _ testState) (bool, error) {
+	enabled, ok := ctx.Value(flagKey{}).(bool)
+	if !ok {
+		return false, testErrStep
+	}
+
+	return enabled, nil
+}
+
+func TestIfCtx(t *testing.T) {
+	count := 0
+	is := NewStep(func(ctx context.Context, state testState) error {
+		count++
+		return nil
+	})
+	es := NewStep(func(ctx context.Context, state testState) error {
+		count += 2
+		return nil
+	})
+
+	on := context.WithValue(context.TODO(), flagKey{}, true)
+	off := context.WithValue(context.TODO(), flagKey{}, false)
+
+	assert.NoError(t, IfCtx(flagEnabled, is).Exec(on, testState{}))
+	assert.NoError(t, IfCtx(flagEnabled, is).Exec(off, testState{}))
+	assert.Equal(t, 1, count)
+
+	assert.NoError(t, IfNotCtx(flagEnabled, is).Exec(off, testState{}))
+	assert.NoError(t, IfNotCtx(flagEnabled, is).Exec(on, testState{}))
+	assert.Equal(t, 2, count)
+
+	assert.NoError(t, IfElseCtx(flagEnabled, is, es).Exec(on, testState{}))
+	assert.NoError(t, IfElseCtx(flagEnabled, is, es).Exec(off, testState{}))
+	assert.Equal(t, 5, count)
+
+	errSelector := new(ErrSelector)
+
+	for _, step := range []Step[testState]{
+		IfCtx(flagEnabled, is),
+		IfNotCtx(flagEnabled, is),
+		IfElseCtx(flagEnabled, is, es),
+	} {
+		err := step.Exec(context.TODO(), testState{})
+		assert.ErrorIs(t, err, testErrStep)
+		assert.ErrorAs(t, err, &errSelector)
+	}
+
+	assert.Equal(t, 5, count)
+
+	t.Run("SelectorName", func(t *testing.T) {
+		assert.Equal(t, "dagger:flagEnabled", stepInfo(IfCtx(flagEnabled, is)).Selector.String())
+		assert.Equal(t, "!dagger:flagEnabled", stepInfo(IfNotCtx(flagEnabled, is)).Selector.String())
+		assert.Equal(t, "flag-enabled", stepInfo(IfElseCtx(NewSelectorCtx("flag-enabled", flagEnabled), is, es)).Selector.String())
+	})
+
+	t.Run("Middleware", func(t *testing.T) {
+		dag, err := New(IfElseCtx(flagEnabled, is, es))
+		assert.NoError(t, err)
+		assert.NoError(t, dag.Use(func(next Step[testState], info Info) Step[testState] { return next }))
+
+		assert.NoError(t, dag.Exec(on, testState{}))
+		assert.Equal(t, 6, count)
+	})
+}
+
+func TestRecoverSelectorPanics(t *testing.T) {
+	panics := NewSelector("panics", func(testState) bool { panic("boom") })
+	noop := NewStep(noopStep)
+
+	errSelector := new(ErrSelector)
+	errPanic := new(ErrPanic)
+
+	for _, step := range []Step[testState]{
+		If(panics, noop),
+		IfNot(panics, noop),
+		IfElse(panics, noop, noop),
+		IfCtx(func(context.Context, testState) (bool, error) { panic("boom") }, noop),
+	} {
+		dag, err := New(Series(noop, step), RecoverSelectorPanics[testState]())
+		assert.NoError(t, err)
+
+		err = dag.Exec(context.TODO(), testState{})
+		assert.ErrorAs(t, err, &errSelector)
+		assert.ErrorAs(t, err, &errPanic)
+		assert.Equal(t, "boom", errPanic.Value())
+	}
+
+	dag, err := New(If(panics, noop), RecoverSelectorPanics[testState]())
+	assert.NoError(t, err)
+	assert.EqualError(t, dag.Exec(context.TODO(), testState{}), "dagger: selector 'panics' failed: dagger: step panicked: boom")
+
+	dag, err = New(If(panics, noop))
+	assert.NoError(t, err)
+	assert.PanicsWithValue(t, "boom", func() { _ = dag.Exec(context.TODO(), testState{}) })
+}
+
 func TestResult(t *testing.T) {
 	t.Run("SuccessBranch", func(t *testing.T) {
 		success, failure := 0, 0
@@ -97,6 +199,181 @@ func TestResult(t *testing.T) {
 		assert.Equal(t, 0, success)
 		assert.Equal(t, 1, failure)
 	})
+
+	t.Run("SkipIsSuccess", func(t *testing.T) {
+		success := 0
+
+		ss := NewStep(func(ctx context.Context, state testState) error { success++; return nil })
+		ms := NewStep(func(ctx context.Context, state testState) error { return &ErrSkip{} })
+
+		err := Result(ms, ss, func(ctx context.Context, state testState, err error) Step[testState] {
+			return ms
+		}).Exec(context.TODO(), testState{})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, success)
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		handled := false
+
+		ms := NewStep(func(ctx context.Context, state testState) error { return Abort("done") })
+		ss := NewStep(func(ctx context.Context, state testState) error { return nil })
+
+		err := Result(ms, ss, func(ctx context.Context, state testState, err error) Step[testState] {
+			handled = true
+			return ss
+		}).Exec(context.TODO(), testState{})
+
+		errAbort := new(ErrAbort)
+		assert.ErrorAs(t, err, &errAbort)
+		assert.False(t, handled)
+	})
+}
+
+func TestResult_ErrorPolicy(t *testing.T) {
+	errRollback := errors.New("rollback")
+
+	failWith := func(rollbackErr error, opts ...ResultOption) error {
+		return Result(
+			NewStep(func(ctx context.Context, state testState) error { return testErrStep }),
+			NewStep(func(ctx context.Context, state testState) error { return nil }),
+			func(ctx context.Context, state testState, err error) Step[testState] {
+				return NewStep(func(ctx context.Context, state testState) error { return rollbackErr })
+			},
+			opts...,
+		).Exec(context.TODO(), testState{})
+	}
+
+	t.Run("Replace", func(t *testing.T) {
+		assert.NoError(t, failWith(nil))
+
+		err := failWith(errRollback, WithErrorPolicy(ReplaceError))
+		assert.ErrorIs(t, err, errRollback)
+		assert.False(t, errors.Is(err, testErrStep))
+	})
+
+	t.Run("Wrap", func(t *testing.T) {
+		assert.NoError(t, failWith(nil, WithErrorPolicy(WrapError)))
+
+		err := failWith(errRollback, WithErrorPolicy(WrapError))
+		assert.ErrorIs(t, err, errRollback)
+		assert.ErrorIs(t, err, testErrStep)
+		assert.Equal(t, "rollback (while handling: step error)", err.Error())
+	})
+
+	t.Run("Join", func(t *testing.T) {
+		assert.ErrorIs(t, failWith(nil, WithErrorPolicy(JoinErrors)), testErrStep)
+
+		err := failWith(errRollback, WithErrorPolicy(JoinErrors))
+		assert.ErrorIs(t, err, errRollback)
+		assert.ErrorIs(t, err, testErrStep)
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		errAbort := new(ErrAbort)
+
+		assert.ErrorAs(t, failWith(Abort("rolled back")), &errAbort)
+
+		for _, policy := range []ErrorPolicy{WrapError, JoinErrors} {
+			err := failWith(Abort("rolled back"), WithErrorPolicy(policy))
+			assert.Equal(t, testErrStep, err)
+
+			dag, dagErr := New(Series(Result(
+				NewStep(func(ctx context.Context, state testState) error { return testErrStep }),
+				nil,
+				func(ctx context.Context, state testState, err error) Step[testState] {
+					return NewStep(func(ctx context.Context, state testState) error { return Abort("rolled back") })
+				},
+				WithErrorPolicy(policy),
+			)))
+			assert.NoError(t, dagErr)
+			assert.ErrorIs(t, dag.Exec(context.TODO(), testState{}), testErrStep)
+		}
+	})
+}
+
+func TestRethrow(t *testing.T) {
+	rolledBack := false
+
+	err := Result(
+		NewStep(func(ctx context.Context, state testState) error { return testErrStep }),
+		NewStep(func(ctx context.Context, state testState) error { return nil }),
+		func(ctx context.Context, state testState, err error) Step[testState] {
+			return NewStep(func(ctx context.Context, state testState) error {
+				rolledBack = true
+				return Rethrow(ctx)
+			})
+		},
+	).Exec(context.TODO(), testState{})
+
+	assert.ErrorIs(t, err, testErrStep)
+	assert.True(t, rolledBack)
+	assert.NoError(t, Rethrow(context.TODO()))
+
+	t.Run("Middleware", func(t *testing.T) {
+		var causes []string
+
+		dag, err := New(OnFailure(
+			NewStep(func(ctx context.Context, state testState) error { return testErrStep }),
+			func(ctx context.Context, state testState, err error) Step[testState] {
+				return Named("rollback", Series(NewStep(noopStep)))
+			},
+		))
+		assert.NoError(t, err)
+
+		assert.NoError(t, dag.Use(func(next Step[testState], info Info) Step[testState] {
+			if !info.FailureBranch {
+				return next
+			}
+
+			return NewStep(func(ctx context.Context, state testState) error {
+				causes = append(causes, fmt.Sprintf("%s %s: %v", info.Path, info.Name, Rethrow(ctx)))
+				return next.Exec(ctx, state)
+			})
+		}))
+
+		assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+		assert.Equal(t, []string{
+			"root/failure rollback: " + testErrStep.Error(),
+			"root/failure/0 dagger:noopStep: " + testErrStep.Error(),
+		}, causes)
+	})
+}
+
+func TestOnFailure(t *testing.T) {
+	handled := 0
+	handler := func(ctx context.Context, state testState, err error) Step[testState] {
+		return NewStep(func(ctx context.Context, state testState) error { handled++; return nil })
+	}
+
+	err := OnFailure(NewStep(func(ctx context.Context, state testState) error {
+		return nil
+	}), handler).Exec(context.TODO(), testState{})
+	assert.NoError(t, err)
+	assert.Zero(t, handled)
+
+	err = OnFailure(NewStep(func(ctx context.Context, state testState) error {
+		return testErrStep
+	}), handler).Exec(context.TODO(), testState{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, handled)
+}
+
+func TestOnSuccess(t *testing.T) {
+	success := 0
+	ss := NewStep(func(ctx context.Context, state testState) error { success++; return nil })
+
+	err := OnSuccess(NewStep(func(ctx context.Context, state testState) error {
+		return nil
+	}), ss).Exec(context.TODO(), testState{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, success)
+
+	err = OnSuccess(NewStep(func(ctx context.Context, state testState) error {
+		return testErrStep
+	}), ss).Exec(context.TODO(), testState{})
+	assert.ErrorIs(t, err, testErrStep)
+	assert.Equal(t, 1, success)
 }
 
 func TestSeries(t *testing.T) {
@@ -136,6 +413,38 @@ func TestSeries(t *testing.T) {
 		assert.ErrorIs(t, err, testErrStep)
 		assert.Equal(t, []string{"s1"}, res)
 	})
+
+	t.Run("Skip", func(t *testing.T) {
+		var res []string
+		appendStep := appendStepIn(&res)
+
+		err := Series(
+			appendStep("s1"),
+			NewStep(func(ctx context.Context, state testState) error {
+				return &ErrSkip{}
+			}),
+			appendStep("s3"),
+		).Exec(context.TODO(), testState{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"s1", "s3"}, res)
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		var res []string
+		appendStep := appendStepIn(&res)
+
+		err := Series(
+			appendStep("s1"),
+			Series(NewStep(func(ctx context.Context, state testState) error {
+				return Abort("done")
+			})),
+			appendStep("s3"),
+		).Exec(context.TODO(), testState{})
+
+		errAbort := new(ErrAbort)
+		assert.ErrorAs(t, err, &errAbort)
+		assert.Equal(t, []string{"s1"}, res)
+	})
 }
 
 func TestContinue(t *testing.T) {
@@ -182,6 +491,248 @@ func TestContinue(t *testing.T) {
 		assert.ErrorIs(t, err, notFoundStep)
 		assert.Equal(t, []string{"s1", "s3"}, res)
 	})
+
+	t.Run("Abort", func(t *testing.T) {
+		var res []string
+		appendStep := appendStepIn(&res)
+		abort := NewStep(func(ctx context.Context, state testState) error { return Abort("done") })
+
+		err := Continue(appendStep("s1"), abort, appendStep("s3")).Exec(context.TODO(), testState{})
+
+		errAbort := new(ErrAbort)
+		assert.ErrorAs(t, err, &errAbort)
+		assert.Equal(t, []string{"s1"}, res)
+
+		err = Continue(
+			NewStep(func(ctx context.Context, state testState) error { return testErrStep }),
+			abort,
+			appendStep("s3"),
+		).Exec(context.TODO(), testState{})
+
+		assert.ErrorIs(t, err, testErrStep)
+		assert.False(t, errors.As(err, &errAbort))
+		assert.Equal(t, []string{"s1"}, res)
+	})
+}
+
+func TestContinueN(t *testing.T) {
+	var ran []int
+
+	failing := func(i int) Step[testState] {
+		return NewStep(func(ctx context.Context, state testState) error {
+			ran = append(ran, i)
+			return testErrStep
+		})
+	}
+
+	err := ContinueN(1, failing(1), failing(2), failing(3)).Exec(context.TODO(), testState{})
+	assert.ErrorIs(t, err, testErrStep)
+	assert.Len(t, err.(interface{ Unwrap() []error }).Unwrap(), 2)
+	assert.Equal(t, []int{1, 2}, ran)
+
+	ran = nil
+
+	err = ContinueN(3, failing(1), failing(2), failing(3)).Exec(context.TODO(), testState{})
+	assert.ErrorIs(t, err, testErrStep)
+	assert.Equal(t, []int{1, 2, 3}, ran)
+
+	ran = nil
+
+	err = ContinueN(0, failing(1), failing(2), failing(3)).Exec(context.TODO(), testState{})
+	assert.ErrorIs(t, err, testErrStep)
+	assert.Equal(t, []int{1}, ran)
+
+	ran = nil
+
+	err = ContinueN(-1, failing(1), failing(2), failing(3)).Exec(context.TODO(), testState{})
+	assert.ErrorIs(t, err, testErrStep)
+	assert.Equal(t, []int{1, 2, 3}, ran)
+}
+
+func TestContinueWith(t *testing.T) {
+	errNotify := errors.New("notify")
+	isWarning := func(err error) bool { return errors.Is(err, errNotify) }
+
+	t.Run("Warnings", func(t *testing.T) {
+		results := NewResults()
+
+		err := ContinueWith([]Step[testState]{
+			NewStep(func(ctx context.Context, state testState) error { return errNotify }),
+			NewStep(func(ctx context.Context, state testState) error { return nil }),
+		}, WithWarnings(isWarning)).Exec(WithResults(context.TODO(), results), testState{})
+		assert.NoError(t, err)
+
+		warnings := results.Warnings()
+		assert.Len(t, warnings, 1)
+		assert.ErrorIs(t, warnings[0], errNotify)
+	})
+
+	t.Run("Failures", func(t *testing.T) {
+		results := NewResults()
+
+		err := ContinueWith([]Step[testState]{
+			NewStep(func(ctx context.Context, state testState) error { return errNotify }),
+			NewStep(func(ctx context.Context, state testState) error { return testErrStep }),
+		}, WithWarnings(isWarning)).Exec(WithResults(context.TODO(), results), testState{})
+		assert.ErrorIs(t, err, testErrStep)
+		assert.False(t, errors.Is(err, errNotify))
+		assert.Len(t, results.Warnings(), 1)
+	})
+
+	t.Run("Middleware", func(t *testing.T) {
+		dag, err := New(ContinueWith([]Step[testState]{
+			NewStep(func(ctx context.Context, state testState) error { return errNotify }),
+		}, WithWarnings(isWarning)))
+		assert.NoError(t, err)
+		assert.NoError(t, dag.Use(testLogMiddleware[testState](&bytes.Buffer{}, "log")))
+
+		assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+	})
+
+	t.Run("NoResults", func(t *testing.T) {
+		err := ContinueWith([]Step[testState]{
+			NewStep(func(ctx context.Context, state testState) error { return errNotify }),
+		}, WithWarnings(isWarning)).Exec(context.TODO(), testState{})
+		assert.NoError(t, err)
+	})
+}
+
+func TestParallel(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		var (
+			res []string
+			mu  sync.Mutex
+		)
+
+		appendStep := func(name string) Step[testState] {
+			return NewStep(func(ctx context.Context, _ testState) error {
+				mu.Lock()
+				defer mu.Unlock()
+
+				res = append(res, name)
+				return nil
+			})
+		}
+
+		err := Parallel(
+			appendStep("s1"),
+			appendStep("s2"),
+			appendStep("s3"),
+		).Exec(context.TODO(), testState{})
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []string{"s1", "s2", "s3"}, res)
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		started := make(chan struct{})
+		release := make(chan struct{})
+
+		err := Parallel(
+			NewStep(func(ctx context.Context, _ testState) error {
+				close(started)
+				<-release
+				return nil
+			}),
+			NewStep(func(ctx context.Context, _ testState) error {
+				<-started
+				close(release)
+				return nil
+			}),
+		).Exec(context.TODO(), testState{})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		notFoundStep := errors.New("not found")
+
+		err := Parallel(
+			NewStep(func(ctx context.Context, state testState) error { return testErrStep }),
+			NewStep(func(ctx context.Context, state testState) error { return nil }),
+			NewStep(func(ctx context.Context, state testState) error { return notFoundStep }),
+		).Exec(context.TODO(), testState{})
+
+		assert.ErrorIs(t, err, testErrStep)
+		assert.ErrorIs(t, err, notFoundStep)
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		abort := NewStep(func(ctx context.Context, state testState) error { return Abort("done") })
+		noop := NewStep(func(ctx context.Context, state testState) error { return nil })
+
+		errAbort := new(ErrAbort)
+		assert.ErrorAs(t, Parallel(abort, noop).Exec(context.TODO(), testState{}), &errAbort)
+
+		err := Parallel(abort, NewStep(func(ctx context.Context, state testState) error {
+			return testErrStep
+		})).Exec(context.TODO(), testState{})
+		assert.ErrorIs(t, err, testErrStep)
+		assert.False(t, errors.As(err, &errAbort))
+	})
+}
+
+func TestParallelN(t *testing.T) {
+	var (
+		running, maxRunning int
+		order               []string
+		mu                  sync.Mutex
+	)
+
+	step := func(name string) Step[testState] {
+		return NewStep(func(ctx context.Context, _ testState) error {
+			mu.Lock()
+			running++
+			maxRunning = max(maxRunning, running)
+			order = append(order, name)
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+
+			return nil
+		})
+	}
+
+	dag, err := New(ParallelN(1,
+		step("s1"),
+		WithPriority(step("s2"), 10),
+		WithLabels(WithPriority(step("s3"), 5), map[string]string{"k": "v"}),
+	))
+	assert.NoError(t, err)
+	assert.NoError(t, dag.Use(testLogMiddleware[testState](&bytes.Buffer{}, "log")))
+
+	assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+	assert.Equal(t, []string{"s2", "s3", "s1"}, order)
+	assert.Equal(t, 1, maxRunning)
+
+	order, maxRunning = nil, 0
+
+	assert.NoError(t, ParallelWith([]Step[testState]{step("s1"), step("s2"), step("s3")}, WithMaxConcurrency(2)).
+		Exec(context.TODO(), testState{}))
+	assert.Equal(t, 2, maxRunning)
+}
+
+func closeConn(ctx context.Context) error { return ctx.Err() }
+
+func flushCache() error { return testErrStep }
+
+func TestFromFunc(t *testing.T) {
+	step := FromFunc[testState](closeConn)
+	assert.Equal(t, "dagger:closeConn", StepName(step).String())
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	assert.NoError(t, step.Exec(context.TODO(), testState{}))
+	assert.ErrorIs(t, step.Exec(ctx, testState{}), context.Canceled)
+}
+
+func TestFromErrFunc(t *testing.T) {
+	step := FromErrFunc[testState](flushCache)
+	assert.Equal(t, "dagger:flushCache", StepName(step).String())
+	assert.ErrorIs(t, step.Exec(context.TODO(), testState{}), testErrStep)
 }
 
 func Test_canSkip(t *testing.T) {
@@ -228,6 +779,13 @@ func Test_canSkip(t *testing.T) {
 				NewStep(func(context.Context, testState) error { return nil }),
 			),
 		},
+		{
+			name: "Parallel",
+			step: Parallel(
+				NewStep(func(context.Context, testState) error { return nil }),
+				NewStep(func(context.Context, testState) error { return nil }),
+			),
+		},
 	}
 
 	for _, tc := range testcases {
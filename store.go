@@ -0,0 +1,170 @@
+package dagger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// MemoryStore is a StateStore keeping the Checkpoint(s) in memory,
+// it does not survive process restarts. It is useful for tests, and
+// to resume executions within the same process.
+type MemoryStore[S any] struct {
+	mu          sync.Mutex
+	checkpoints map[string]Checkpoint[S]
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore[S any]() *MemoryStore[S] {
+	return &MemoryStore[S]{checkpoints: make(map[string]Checkpoint[S])}
+}
+
+// Load implements StateStore.
+func (m *MemoryStore[S]) Load(_ context.Context, runID string) (Checkpoint[S], error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cp, ok := m.checkpoints[runID]
+	if !ok {
+		return Checkpoint[S]{}, &ErrNoCheckpoint{runID: runID}
+	}
+
+	cp.Completed = append([]string(nil), cp.Completed...)
+
+	return cp, nil
+}
+
+// Save implements StateStore.
+func (m *MemoryStore[S]) Save(_ context.Context, runID string, cp Checkpoint[S]) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cp.Completed = append([]string(nil), cp.Completed...)
+	m.checkpoints[runID] = cp
+
+	return nil
+}
+
+// Delete implements StateStore.
+func (m *MemoryStore[S]) Delete(_ context.Context, runID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.checkpoints, runID)
+
+	return nil
+}
+
+// FileStore is a StateStore keeping each Checkpoint as a JSON file
+// in a directory, the state is serialized using its Codec.
+type FileStore[S any] struct {
+	dir string
+	cfg storeConfig[S]
+	mu  sync.Mutex
+}
+
+// fileCheckpoint is the representation of a Checkpoint in a FileStore.
+type fileCheckpoint struct {
+	State     []byte   `json:"state"`
+	Completed []string `json:"completed"`
+}
+
+// NewFileStore creates a FileStore keeping the Checkpoint(s) in dir,
+// the directory is created if it does not exist.
+func NewFileStore[S any](dir string, opts ...StoreOption[S]) (*FileStore[S], error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &FileStore[S]{dir: dir, cfg: newStoreConfig(opts)}, nil
+}
+
+// Load implements StateStore.
+func (f *FileStore[S]) Load(_ context.Context, runID string) (Checkpoint[S], error) {
+	data, err := os.ReadFile(f.path(runID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Checkpoint[S]{}, &ErrNoCheckpoint{runID: runID}
+	}
+
+	if err != nil {
+		return Checkpoint[S]{}, err
+	}
+
+	var fcp fileCheckpoint
+	if err := json.Unmarshal(data, &fcp); err != nil {
+		return Checkpoint[S]{}, err
+	}
+
+	state, err := f.cfg.codec.Unmarshal(fcp.State)
+	if err != nil {
+		return Checkpoint[S]{}, err
+	}
+
+	return Checkpoint[S]{State: state, Completed: fcp.Completed}, nil
+}
+
+// Save implements StateStore. The file is replaced atomically,
+// so a crash while saving leaves the previous Checkpoint intact.
+func (f *FileStore[S]) Save(_ context.Context, runID string, cp Checkpoint[S]) error {
+	state, err := f.cfg.codec.Marshal(cp.State)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(fileCheckpoint{State: state, Completed: cp.Completed})
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tmp, err := os.CreateTemp(f.dir, "."+url.PathEscape(runID)+"-*")
+	if err != nil {
+		return err
+	}
+
+	// The file is synced before it is renamed, so that the rename does
+	// not replace the previous Checkpoint with an incomplete file.
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path(runID))
+}
+
+// Delete implements StateStore.
+func (f *FileStore[S]) Delete(_ context.Context, runID string) error {
+	err := os.Remove(f.path(runID))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// path returns the path of the file of the run ID, which is escaped
+// so that distinct run IDs, e.g. a/x and b/x, never share a file.
+func (f *FileStore[S]) path(runID string) string {
+	return filepath.Join(f.dir, url.PathEscape(runID)+".json")
+}
@@ -0,0 +1,157 @@
+package dagger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SwitchCase is a case of Switch, see Case and Default.
+type SwitchCase[S any, K comparable] struct {
+	key       K
+	isDefault bool
+	step      Step[S]
+	// label is the edge of the case, set by Switch, see edge.
+	label string
+}
+
+// Case returns a SwitchCase executing step when the key of the state is key.
+func Case[S any, K comparable](key K, step Step[S]) SwitchCase[S, K] {
+	return SwitchCase[S, K]{key: key, step: step}
+}
+
+// Default returns a SwitchCase executing step when no other case matches.
+func Default[S any, K comparable](step Step[S]) SwitchCase[S, K] {
+	return SwitchCase[S, K]{isDefault: true, step: step}
+}
+
+type switchStep[S any, K comparable] struct {
+	key   func(state S) K
+	name  fmt.Stringer
+	cases []SwitchCase[S, K]
+	index map[K]int
+}
+
+var (
+	_ middlewareSkipper = (*switchStep[any, int])(nil)
+	_ selectorNamer     = (*switchStep[any, int])(nil)
+	_ rebuilder[any]    = (*switchStep[any, int])(nil)
+)
+
+func (s *switchStep[S, K]) selectorName() fmt.Stringer { return s.name }
+
+func (s *switchStep[S, K]) canSkip() bool { return true }
+
+func (s *switchStep[S, K]) Exec(ctx context.Context, state S) error {
+	key, err := s.eval(ctx, state)
+	if err != nil {
+		return err
+	}
+
+	if i, ok := s.index[key]; ok {
+		return s.cases[i].step.Exec(ctx, state)
+	}
+
+	for _, c := range s.cases {
+		if c.isDefault {
+			return c.step.Exec(ctx, state)
+		}
+	}
+
+	return nil
+}
+
+// eval returns the key of the state, recovering
+// its panics like evalCondition does.
+func (s *switchStep[S, K]) eval(ctx context.Context, state S) (key K, err error) {
+	if enabled, _ := ctx.Value(recoverSelectorsKey).(bool); enabled {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &ErrSelector{selector: s.name, err: &ErrPanic{value: r}}
+			}
+		}()
+	}
+
+	return s.key(state), nil
+}
+
+func (s *switchStep[S, K]) Unwrap() []Step[S] {
+	steps := make([]Step[S], len(s.cases))
+	for i, c := range s.cases {
+		steps[i] = c.step
+	}
+
+	return steps
+}
+
+func (s *switchStep[S, K]) rebuild(wrap wrapFunc[S]) Step[S] {
+	cases := make([]SwitchCase[S, K], len(s.cases))
+	for i, c := range s.cases {
+		c.step = wrap(c.label, c.step)
+		cases[i] = c
+	}
+
+	return &switchStep[S, K]{key: s.key, name: s.name, cases: cases, index: s.index}
+}
+
+// edgeEscaper escapes the separator of paths in the keys of edges,
+// and the escape character itself, so that keys can not collide.
+var edgeEscaper = strings.NewReplacer("%", "%25", "/", "%2F")
+
+// edge identifies the case within the Switch, e.g. case=gcp, the key is
+// formatted with %v, and escaped so that it does not nest the path.
+func (c SwitchCase[S, K]) edge() string {
+	if c.isDefault {
+		return "default"
+	}
+
+	return "case=" + edgeEscaper.Replace(fmt.Sprint(c.key))
+}
+
+// Switch executes the Step of the case matching the key of the state, or of the
+// Default case if none does, and nothing if there is no Default case. Keys
+// can be strings, or any comparable type, e.g. an enum implementing
+// fmt.Stringer. The first case wins for duplicate keys, and the first Default
+// case if there are many, the others are dropped.
+//
+// Each case is an edge of the DAG labelled with its key, e.g. root/case=gcp,
+// which shows in Info.Path and the diagrams of Document. A / in the key is
+// escaped as %2F, and a case whose key formats like the key of a previous
+// case is labelled with its index instead, e.g. case#2. Info.Selector
+// is the name of the key func.
+func Switch[S any, K comparable](key func(state S) K, cases ...SwitchCase[S, K]) Step[S] {
+	s := &switchStep[S, K]{key: key, name: funcName(key), index: make(map[K]int, len(cases))}
+
+	hasDefault := false
+
+	// unreachable cases are dropped, so that each edge is unique
+	for _, c := range cases {
+		if c.isDefault {
+			if !hasDefault {
+				hasDefault = true
+				s.cases = append(s.cases, c)
+			}
+
+			continue
+		}
+
+		if _, ok := s.index[c.key]; !ok {
+			s.index[c.key] = len(s.cases)
+			s.cases = append(s.cases, c)
+		}
+	}
+
+	labels := make(map[string]struct{}, len(s.cases))
+
+	for i := range s.cases {
+		label := s.cases[i].edge()
+		if _, ok := labels[label]; ok {
+			label = fmt.Sprintf("case#%d", i)
+		}
+
+		labels[label] = struct{}{}
+		s.cases[i].label = label
+	}
+
+	return s
+}
@@ -0,0 +1,120 @@
+package dagger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type cloud int
+
+const (
+	gcp cloud = iota
+	aws
+	azure
+)
+
+func (c cloud) String() string { return [...]string{"gcp", "aws", "azure"}[c] }
+
+type cloudState struct{ cloud cloud }
+
+func cloudOf(s cloudState) cloud { return s.cloud }
+
+func TestSwitch(t *testing.T) {
+	var executed []string
+
+	step := func(name string) Step[cloudState] {
+		return Named(name, NewStep(func(context.Context, cloudState) error {
+			executed = append(executed, name)
+			return nil
+		}))
+	}
+
+	sw := Switch(cloudOf,
+		Case(gcp, step("create-gce")),
+		Case(aws, step("create-ec2")),
+		Case(aws, step("unreachable")),
+		Default[cloudState, cloud](step("unsupported")),
+	)
+
+	for _, c := range []cloud{gcp, aws, azure} {
+		assert.NoError(t, sw.Exec(context.TODO(), cloudState{cloud: c}))
+	}
+
+	assert.Equal(t, []string{"create-gce", "create-ec2", "unsupported"}, executed)
+	assert.NoError(t, Switch(cloudOf, Case(gcp, step("create-gce"))).Exec(context.TODO(), cloudState{cloud: aws}))
+
+	t.Run("Info", func(t *testing.T) {
+		assert.Equal(t, "dagger:cloudOf", stepInfo(sw).Selector.String())
+		assert.True(t, stepInfo(sw).CanSkip)
+
+		paths := make(map[string]string)
+		Walk(sw, func(step Step[cloudState], info Info, depth int) bool {
+			paths[info.Name.String()] = info.Path
+			return true
+		})
+
+		assert.Equal(t, "root/case=gcp", paths["create-gce"])
+		assert.Equal(t, "root/case=aws", paths["create-ec2"])
+		assert.Equal(t, "root/default", paths["unsupported"])
+		assert.NotContains(t, paths, "unreachable")
+	})
+
+	t.Run("Middleware", func(t *testing.T) {
+		executed = nil
+
+		var wrapped []string
+
+		dag, err := New(sw)
+		assert.NoError(t, err)
+		assert.NoError(t, dag.Use(func(next Step[cloudState], info Info) Step[cloudState] {
+			wrapped = append(wrapped, info.Path)
+			return next
+		}))
+
+		assert.NoError(t, dag.Exec(context.TODO(), cloudState{cloud: aws}))
+		assert.Equal(t, []string{"create-ec2"}, executed)
+		assert.Equal(t, []string{"root/case=gcp", "root/case=aws", "root/default", "root"}, wrapped)
+	})
+
+	t.Run("Edges", func(t *testing.T) {
+		type region struct{ name, zone string }
+
+		sw := Switch(func(s testState) region { return region{} },
+			Case(region{"europe/west", "a"}, Named("west-a", NewStep(noopStep))),
+			Case(region{"europe/west", "b"}, Named("west-b", NewStep(noopStep))),
+			Case(region{"europe%2Fwest", "a"}, Named("escaped", NewStep(noopStep))),
+		)
+
+		paths := make(map[string]string)
+		Walk(sw, func(step Step[testState], info Info, depth int) bool {
+			paths[info.Name.String()] = info.Path
+			return true
+		})
+
+		assert.Equal(t, "root/case={europe%2Fwest a}", paths["west-a"])
+		assert.Equal(t, "root/case={europe%2Fwest b}", paths["west-b"])
+		assert.Equal(t, "root/case={europe%252Fwest a}", paths["escaped"])
+
+		anySw := Switch(func(s testState) any { return nil },
+			Case[testState, any](1, Named("one", NewStep(noopStep))),
+			Case[testState, any]("1", Named("one-string", NewStep(noopStep))),
+		)
+
+		paths = make(map[string]string)
+		Walk(anySw, func(step Step[testState], info Info, depth int) bool {
+			paths[info.Name.String()] = info.Path
+			return true
+		})
+
+		assert.Equal(t, "root/case=1", paths["one"])
+		assert.Equal(t, "root/case#1", paths["one-string"], "keys formatting alike get distinct edges")
+	})
+
+	t.Run("Document", func(t *testing.T) {
+		doc, err := Document(Switch(cloudOf, Case(gcp, step("create-gce"))), WithDiagram())
+		assert.NoError(t, err)
+		assert.Contains(t, string(doc), "n0 -->|case=gcp| n1")
+	})
+}
@@ -0,0 +1,108 @@
+package dagger
+
+import (
+	"context"
+	"runtime/trace"
+	"sort"
+)
+
+// TraceOption configures TraceTask, TraceRegions and NewChromeTrace.
+type TraceOption[S any] func(*traceConfig[S])
+
+type traceConfig[S any] struct {
+	attrs func(state S) map[string]string
+}
+
+// WithAttrsFromState adds the attributes returned by attrs to the traces, e.g.
+// the business identifiers of the state like a resource ID or the tenant, so
+// that Step(s) do not have to log them. They are extracted once the Step(s)
+// executed, or when the execution starts for TraceTask.
+func WithAttrsFromState[S any](attrs func(state S) map[string]string) TraceOption[S] {
+	return func(c *traceConfig[S]) { c.attrs = attrs }
+}
+
+func newTraceConfig[S any](opts []TraceOption[S]) traceConfig[S] {
+	var cfg traceConfig[S]
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return cfg
+}
+
+// logAttrs logs the attributes of the state to the current task, if any, in order.
+func (c traceConfig[S]) logAttrs(ctx context.Context, state S) {
+	if c.attrs == nil {
+		return
+	}
+
+	attrs := c.attrs(state)
+
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		trace.Log(ctx, k, attrs[k])
+	}
+}
+
+// TraceTask returns a DAGMiddleware which creates a runtime/trace task for
+// every execution, typed with the name of the start Step. The run ID of the
+// execution, if any, is logged to the task. Use it along with TraceRegions,
+// to inspect executions with `go tool trace`.
+//
+// Tasks are only created while tracing is enabled.
+func TraceTask[S any](opts ...TraceOption[S]) DAGMiddleware[S] {
+	cfg := newTraceConfig(opts)
+
+	return func(next Step[S]) Step[S] {
+		taskType := StepName(next).String()
+
+		return StepFunc[S](func(ctx context.Context, state S) error {
+			if !trace.IsEnabled() {
+				return next.Exec(ctx, state)
+			}
+
+			ctx, task := trace.NewTask(ctx, taskType)
+			defer task.End()
+
+			if id, ok := RunIDFromContext(ctx); ok {
+				trace.Log(ctx, "run-id", id)
+			}
+
+			cfg.logAttrs(ctx, state)
+
+			return next.Exec(ctx, state)
+		})
+	}
+}
+
+// TraceRegions returns a middleware which records a runtime/trace region for
+// every Step, named after the Step. Regions are only recorded while tracing
+// is enabled, and belong to the task created by TraceTask, if any.
+func TraceRegions[S any](opts ...TraceOption[S]) MiddlewareFunc[S] {
+	cfg := newTraceConfig(opts)
+
+	return func(next Step[S], info Info) Step[S] {
+		regionType := info.Name.String()
+
+		return StepFunc[S](func(ctx context.Context, state S) error {
+			if !trace.IsEnabled() {
+				return next.Exec(ctx, state)
+			}
+
+			var err error
+
+			trace.WithRegion(ctx, regionType, func() {
+				err = next.Exec(ctx, state)
+				cfg.logAttrs(ctx, state)
+			})
+
+			return err
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package dagger
+
+import (
+	"bytes"
+	"context"
+	"runtime/trace"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceRegions(t *testing.T) {
+	dag, err := New(Series(
+		Named("validate", NewStep(noopStep)),
+		Named("create", NewStep(func(ctx context.Context, state testState) error { return testErrStep })),
+	))
+	assert.NoError(t, err)
+
+	tenant := WithAttrsFromState(func(testState) map[string]string { return map[string]string{"tenant": "acme-corp"} })
+
+	assert.NoError(t, dag.UseDAG(TraceTask(tenant)))
+	assert.NoError(t, dag.Use(TraceRegions(tenant)))
+
+	assert.ErrorIs(t, dag.Exec(context.TODO(), testState{}), testErrStep)
+
+	buf := new(bytes.Buffer)
+	if err := trace.Start(buf); err != nil {
+		t.Skip("tracing is already enabled:", err)
+	}
+
+	err = dag.Exec(WithRunID(context.TODO(), "req-42"), testState{})
+	trace.Stop()
+
+	assert.ErrorIs(t, err, testErrStep)
+
+	for _, s := range []string{"dagger:seriesStep[testState]", "validate", "create", "req-42", "acme-corp"} {
+		assert.Contains(t, buf.String(), s)
+	}
+}
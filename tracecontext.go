@@ -0,0 +1,67 @@
+package dagger
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+)
+
+// TracePropagator propagates the trace context of executions across services,
+// it is used by RemoteStep and HTTPStep to inject the trace context of ctx into
+// the headers of their requests, and by StepServer to extract it. It matches
+// the TextMapPropagator of OpenTelemetry, which can be adapted with a
+// propagation.HeaderCarrier to connect the spans of a DAG to downstream
+// services. The default is W3CTraceContext.
+type TracePropagator interface {
+	Inject(ctx context.Context, header http.Header)
+	Extract(ctx context.Context, header http.Header) context.Context
+}
+
+// W3CTraceContext is a TracePropagator of the traceparent and tracestate
+// headers of the W3C Trace Context, which are carried by the context,
+// see WithTraceParent.
+type W3CTraceContext struct{}
+
+const (
+	traceParentHeader = "Traceparent"
+	traceStateHeader  = "Tracestate"
+)
+
+var traceParentRegex = regexp.MustCompile(`^[0-9a-f]{2}-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`)
+
+type traceContext struct {
+	parent string
+	state  string
+}
+
+// Inject sets the traceparent and tracestate headers, if ctx carries them.
+func (W3CTraceContext) Inject(ctx context.Context, header http.Header) {
+	tc, ok := ctx.Value(traceContextKey).(traceContext)
+	if !ok {
+		return
+	}
+
+	header.Set(traceParentHeader, tc.parent)
+
+	if tc.state != "" {
+		header.Set(traceStateHeader, tc.state)
+	}
+}
+
+// Extract returns a copy of ctx carrying the traceparent and tracestate
+// headers, if the traceparent is valid, and ctx otherwise.
+func (W3CTraceContext) Extract(ctx context.Context, header http.Header) context.Context {
+	return WithTraceParent(ctx, header.Get(traceParentHeader), header.Get(traceStateHeader))
+}
+
+// WithTraceParent returns a copy of ctx carrying the given W3C traceparent
+// and tracestate, e.g. the ones of the incoming request, which are propagated
+// to RemoteStep(s) and HTTPStep(s) by W3CTraceContext. An invalid traceparent
+// is ignored.
+func WithTraceParent(ctx context.Context, traceparent, tracestate string) context.Context {
+	if !traceParentRegex.MatchString(traceparent) {
+		return ctx
+	}
+
+	return context.WithValue(ctx, traceContextKey, traceContext{parent: traceparent, state: tracestate})
+}
@@ -0,0 +1,59 @@
+package dagger
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestW3CTraceContext(t *testing.T) {
+	header := make(http.Header)
+	W3CTraceContext{}.Inject(context.TODO(), header)
+	assert.Empty(t, header)
+
+	ctx := WithTraceParent(context.TODO(), testTraceParent, "vendor=value")
+	W3CTraceContext{}.Inject(ctx, header)
+	assert.Equal(t, testTraceParent, header.Get("traceparent"))
+	assert.Equal(t, "vendor=value", header.Get("tracestate"))
+
+	extracted := make(http.Header)
+	W3CTraceContext{}.Inject(W3CTraceContext{}.Extract(context.TODO(), header), extracted)
+	assert.Equal(t, header, extracted)
+
+	assert.Nil(t, WithTraceParent(context.TODO(), "invalid", "").Value(traceContextKey))
+}
+
+func TestTracePropagation(t *testing.T) {
+	var traceparent string
+
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer downstream.Close()
+
+	notify := HTTPStep(
+		func(ctx context.Context, vm remoteVM) (*http.Request, error) {
+			return http.NewRequestWithContext(ctx, http.MethodPost, downstream.URL, nil)
+		},
+		func(*http.Response, remoteVM) error { return nil },
+	)
+
+	server := NewStepServer[remoteVM]()
+	server.Register("notify", NewTStep(func(ctx context.Context, vm remoteVM) (remoteVM, error) {
+		return vm, notify.Exec(ctx, vm)
+	}))
+
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	dag, err := New(Mutate(RemoteStep[remoteVM]("notify", srv.URL)))
+	assert.NoError(t, err)
+
+	assert.NoError(t, dag.Exec(WithTraceParent(context.TODO(), testTraceParent, ""), &remoteVM{}))
+	assert.Equal(t, testTraceParent, traceparent)
+}
@@ -0,0 +1,97 @@
+package dagger
+
+import "context"
+
+// TStep is a unit of work which treats the state as a value,
+// instead of mutating it, it returns the new state.
+type TStep[S any] interface {
+	// Exec performs the operation and returns the new state.
+	Exec(ctx context.Context, state S) (S, error)
+}
+
+// TStepFunc helps implement TStep in place.
+type TStepFunc[S any] func(ctx context.Context, state S) (S, error)
+
+func (f TStepFunc[S]) Exec(ctx context.Context, state S) (S, error) { return f(ctx, state) }
+
+var _ TStep[any] = (*TStepFunc[any])(nil)
+
+// NewTStep is a helper function to create a TStepFunc without explicit mention of generic S.
+func NewTStep[S any](f func(ctx context.Context, state S) (S, error)) TStepFunc[S] { return f }
+
+type tSeriesStep[S any] struct {
+	steps []TStep[S]
+}
+
+func (s *tSeriesStep[S]) Exec(ctx context.Context, state S) (S, error) {
+	for _, step := range s.steps {
+		next, err := step.Exec(ctx, state)
+		if err != nil {
+			return state, err
+		}
+
+		state = next
+	}
+
+	return state, nil
+}
+
+// TSeries executes the given steps one-by-one in sequence, passing the state
+// returned by a TStep to the next one. If any TStep returns an error, TSeries
+// returns the state it received along with that error, and skips the remaining TStep(s).
+func TSeries[S any](steps ...TStep[S]) TStep[S] {
+	return &tSeriesStep[S]{steps: steps}
+}
+
+type tIfElseStep[S any] struct {
+	condition Selector[S]
+	thenStep  TStep[S]
+	elseStep  TStep[S]
+}
+
+func (s *tIfElseStep[S]) Exec(ctx context.Context, state S) (S, error) {
+	if s.condition(state) {
+		return s.thenStep.Exec(ctx, state)
+	}
+
+	if s.elseStep == nil {
+		return state, nil
+	}
+
+	return s.elseStep.Exec(ctx, state)
+}
+
+// TIf takes in a Selector and runs the thenStep, iff Selector returns true,
+// otherwise the state is returned as is.
+func TIf[S any](condition Selector[S], thenStep TStep[S]) TStep[S] {
+	return &tIfElseStep[S]{condition: condition, thenStep: thenStep}
+}
+
+// TIfElse takes in a Selector and
+//   - executes the thenStep, if the Selector returns true
+//   - executes the elseStep, if the Selector returns false
+func TIfElse[S any](condition Selector[S], thenStep, elseStep TStep[S]) TStep[S] {
+	return &tIfElseStep[S]{condition: condition, thenStep: thenStep, elseStep: elseStep}
+}
+
+type mutateStep[S any] struct {
+	step TStep[S]
+}
+
+func (s *mutateStep[S]) Exec(ctx context.Context, state *S) error {
+	next, err := s.step.Exec(ctx, *state)
+	if err != nil {
+		return err
+	}
+
+	*state = next
+
+	return nil
+}
+
+// Mutate mounts a TStep in a DAG of Step(s) working on a pointer to the state.
+// The pointed state is replaced by the one returned from the TStep, only if it
+// returns no error.
+func Mutate[S any](step TStep[S]) Step[*S] {
+	return &mutateStep[S]{step: step}
+}
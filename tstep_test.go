@@ -0,0 +1,75 @@
+package dagger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type counterState struct{ count int }
+
+func increment(_ context.Context, state counterState) (counterState, error) {
+	state.count++
+	return state, nil
+}
+
+func failCounter(_ context.Context, state counterState) (counterState, error) {
+	state.count = -1
+	return state, testErrStep
+}
+
+func TestTSeries(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		in := counterState{}
+
+		out, err := TSeries(NewTStep(increment), NewTStep(increment), NewTStep(increment)).Exec(context.TODO(), in)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, out.count)
+		assert.Equal(t, 0, in.count)
+	})
+
+	t.Run("OneStepErrorsOut", func(t *testing.T) {
+		out, err := TSeries(NewTStep(increment), NewTStep(failCounter), NewTStep(increment)).Exec(context.TODO(), counterState{})
+		assert.ErrorIs(t, err, testErrStep)
+		assert.Equal(t, 1, out.count)
+	})
+}
+
+func TestTIfElse(t *testing.T) {
+	isZero := func(state counterState) bool { return state.count == 0 }
+	double := NewTStep(func(_ context.Context, state counterState) (counterState, error) {
+		state.count *= 2
+		return state, nil
+	})
+
+	out, err := TIf(isZero, NewTStep(increment)).Exec(context.TODO(), counterState{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, out.count)
+
+	out, err = TIf(isZero, NewTStep(increment)).Exec(context.TODO(), counterState{count: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, out.count)
+
+	out, err = TIfElse(isZero, NewTStep(increment), double).Exec(context.TODO(), counterState{count: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, 10, out.count)
+}
+
+func TestMutate(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		state := &counterState{count: 1}
+
+		err := Series(Mutate(NewTStep(increment)), Mutate(NewTStep(increment))).Exec(context.TODO(), state)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, state.count)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		state := &counterState{count: 1}
+
+		err := Mutate(NewTStep(failCounter)).Exec(context.TODO(), state)
+		assert.ErrorIs(t, err, testErrStep)
+		assert.Equal(t, 1, state.count)
+	})
+}
@@ -0,0 +1,142 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Validator can be implemented by a state to validate itself, see Validate.
+type Validator interface {
+	Validate() error
+}
+
+// FieldError is the error of a single field of a state, validators
+// can return them, joined with errors.Join, to report each field.
+type FieldError struct {
+	Field string
+	Msg   string
+}
+
+func (e *FieldError) Error() string { return e.Field + ": " + e.Msg }
+
+// ValidateOption configures Validate.
+type ValidateOption[S any] func(*validateConfig[S])
+
+type validateConfig[S any] struct {
+	validators []func(state S) error
+}
+
+// WithValidator adds a func validating the state, e.g. one using
+// a validation library, it is called after the built-in checks.
+func WithValidator[S any](fn func(state S) error) ValidateOption[S] {
+	return func(c *validateConfig[S]) { c.validators = append(c.validators, fn) }
+}
+
+type validateStep[S any] struct {
+	cfg validateConfig[S]
+}
+
+var _ StepNamer = (*validateStep[any])(nil)
+
+func (s *validateStep[S]) StepName() fmt.Stringer { return fmtStr("dagger:Validate") }
+
+func (s *validateStep[S]) Exec(_ context.Context, state S) error {
+	errs := requiredFields(state)
+
+	if v, ok := any(state).(Validator); ok {
+		errs = append(errs, v.Validate())
+	}
+
+	for _, fn := range s.cfg.validators {
+		errs = append(errs, fn(state))
+	}
+
+	err := errors.Join(errs...)
+	if err == nil {
+		return nil
+	}
+
+	return &ErrValidation{fields: fieldErrors(err), err: err}
+}
+
+// Validate returns a Step validating the state, which fails with ErrValidation
+// if the state is invalid. Most DAGs start with such a Step. It checks, in order:
+//   - the fields of a struct state, or a pointer to one, tagged with
+//     `validate:"required"` are not zero
+//   - the Validate method of the state, if it implements Validator
+//   - the funcs added with WithValidator
+//
+// All the checks are executed, their errors are joined.
+func Validate[S any](opts ...ValidateOption[S]) Step[S] {
+	s := &validateStep[S]{}
+	for _, opt := range opts {
+		opt(&s.cfg)
+	}
+
+	return s
+}
+
+// requiredFields returns a FieldError for each zero field
+// of the state which is tagged with `validate:"required"`.
+func requiredFields(state any) []error {
+	v := reflect.ValueOf(state)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs []error
+
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+
+		if !f.IsExported() || !hasTagOption(f.Tag.Get("validate"), "required") {
+			continue
+		}
+
+		if v.Field(i).IsZero() {
+			errs = append(errs, &FieldError{Field: f.Name, Msg: "is required"})
+		}
+	}
+
+	return errs
+}
+
+func hasTagOption(tag, option string) bool {
+	for _, o := range strings.Split(tag, ",") {
+		if strings.TrimSpace(o) == option {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fieldErrors returns the FieldError(s) in err's tree.
+func fieldErrors(err error) []FieldError {
+	var fields []FieldError
+
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case *FieldError:
+			fields = append(fields, *e)
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				walk(err)
+			}
+		case interface{ Unwrap() error }:
+			walk(e.Unwrap())
+		}
+	}
+
+	walk(err)
+
+	return fields
+}
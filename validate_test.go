@@ -0,0 +1,61 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type vmSpec struct {
+	Name     string `validate:"required"`
+	Image    string `validate:"required"`
+	Replicas int
+	internal string `validate:"required"`
+}
+
+func (s *vmSpec) Validate() error {
+	if s.Replicas > 3 {
+		return &FieldError{Field: "Replicas", Msg: "must be at most 3"}
+	}
+
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate[*vmSpec]().Exec(context.TODO(), &vmSpec{Name: "vm", Image: "debian"}))
+	assert.Equal(t, "dagger:Validate", StepName(Validate[*vmSpec]()).String())
+
+	errValidation := new(ErrValidation)
+
+	err := Validate[*vmSpec]().Exec(context.TODO(), &vmSpec{Image: "debian", Replicas: 4})
+	assert.ErrorAs(t, err, &errValidation)
+	assert.Equal(t, []FieldError{
+		{Field: "Name", Msg: "is required"},
+		{Field: "Replicas", Msg: "must be at most 3"},
+	}, errValidation.Fields())
+
+	t.Run("WithValidator", func(t *testing.T) {
+		errImage := errors.New("unknown image")
+		validate := Validate(
+			WithValidator(func(s *vmSpec) error {
+				if s.Image != "debian" {
+					return errImage
+				}
+
+				return nil
+			}),
+		)
+
+		err := validate.Exec(context.TODO(), &vmSpec{Name: "vm", Image: "arch", Replicas: 4})
+		assert.ErrorIs(t, err, errImage)
+		assert.ErrorAs(t, err, &errValidation)
+		assert.Equal(t, []FieldError{{Field: "Replicas", Msg: "must be at most 3"}}, errValidation.Fields())
+		assert.Equal(t, "dagger: invalid state: Replicas: must be at most 3; unknown image", err.Error())
+	})
+
+	t.Run("NotStruct", func(t *testing.T) {
+		assert.NoError(t, Validate[int]().Exec(context.TODO(), 0))
+	})
+}
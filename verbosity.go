@@ -0,0 +1,56 @@
+package dagger
+
+import "context"
+
+// Verbosity decides which Step(s) a middleware wrapped by Verbose applies to.
+type Verbosity int
+
+const (
+	// VerbosityQuiet does not apply the middleware to any Step.
+	VerbosityQuiet Verbosity = iota
+	// VerbosityLeaf applies the middleware to leaf Step(s) only, see LeafOnly.
+	VerbosityLeaf
+	// VerbosityFull applies the middleware to every Step, including
+	// meta Step(s) like Series and If.
+	VerbosityFull
+)
+
+func (v Verbosity) allows(info Info) bool {
+	switch v {
+	case VerbosityQuiet:
+		return false
+	case VerbosityLeaf:
+		return !info.CanSkip
+	}
+
+	return true
+}
+
+// Verbose returns a MiddlewareFunc which applies mw to the Step(s) allowed by
+// the Verbosity, e.g. to run logging and tracing middlewares like ChromeTrace
+// or TraceRegions quietly in production, while WithRunVerbosity turns them up
+// for a single execution when debugging a specific request.
+func Verbose[S any](level Verbosity, mw MiddlewareFunc[S]) MiddlewareFunc[S] {
+	return func(next Step[S], info Info) Step[S] {
+		wrapped := mw(next, info)
+
+		return StepFunc[S](func(ctx context.Context, state S) error {
+			v := level
+			if override, ok := ctx.Value(verbosityKey).(Verbosity); ok {
+				v = override
+			}
+
+			if v.allows(info) {
+				return wrapped.Exec(ctx, state)
+			}
+
+			return next.Exec(ctx, state)
+		})
+	}
+}
+
+// WithRunVerbosity returns a copy of ctx which overrides the Verbosity
+// of the middlewares wrapped by Verbose, for executions with it.
+func WithRunVerbosity(ctx context.Context, v Verbosity) context.Context {
+	return context.WithValue(ctx, verbosityKey, v)
+}
@@ -0,0 +1,61 @@
+package dagger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerbose(t *testing.T) {
+	var traced []string
+
+	trace := func(next Step[testState], info Info) Step[testState] {
+		return StepFunc[testState](func(ctx context.Context, state testState) error {
+			traced = append(traced, info.Name.String())
+			return next.Exec(ctx, state)
+		})
+	}
+
+	noop := NewStep(noopStep)
+	step := Named("provision", Series(Named("create", noop), Named("maybe-publish", If(alwaysTrue, Named("publish", noop)))))
+
+	testcases := []struct {
+		name  string
+		level Verbosity
+		want  []string
+	}{
+		{name: "Quiet", level: VerbosityQuiet},
+		{name: "Leaf", level: VerbosityLeaf, want: []string{"create", "publish"}},
+		{name: "Full", level: VerbosityFull, want: []string{"provision", "create", "maybe-publish", "publish"}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			traced = nil
+
+			dag, err := New(step)
+			assert.NoError(t, err)
+			assert.NoError(t, dag.Use(Verbose(tc.level, trace)))
+
+			assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+			assert.Equal(t, tc.want, traced)
+		})
+	}
+
+	t.Run("WithRunVerbosity", func(t *testing.T) {
+		traced = nil
+
+		dag, err := New(step)
+		assert.NoError(t, err)
+		assert.NoError(t, dag.Use(Verbose(VerbosityQuiet, trace)))
+
+		assert.NoError(t, dag.Exec(WithRunVerbosity(context.TODO(), VerbosityLeaf), testState{}))
+		assert.Equal(t, []string{"create", "publish"}, traced)
+
+		traced = nil
+
+		assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+		assert.Empty(t, traced)
+	})
+}
@@ -0,0 +1,65 @@
+package dagger
+
+import "context"
+
+// WarmupLabel is the label of the Step(s) executed by Executor.Warmup,
+// which must be set to "true", see WithLabels.
+const WarmupLabel = "warmup"
+
+// Warmup executes the DAG with a sample state to prime its dependencies, e.g.
+// connection pools and caches, when a service starts. Only the leaf Step(s)
+// labeled with WarmupLabel are executed, others return ErrSkip, so that Step(s)
+// with side effects are not. Selectors are evaluated with the sample state.
+//
+// The middlewares and ContextDecorator(s) of the Executor apply as they
+// do for Exec, but the Executor is not frozen, nor is the execution
+// counted in its Stats. Warmup does nothing if no Step is labeled.
+func (e *Executor[S]) Warmup(ctx context.Context, sampleState S) error {
+	e.mu.Lock()
+	step, warmup := e.compiled, hasWarmupSteps(e.start)
+	e.mu.Unlock()
+
+	if !warmup {
+		return nil
+	}
+
+	ctx = context.WithValue(e.withConfig(e.nest(ctx)), warmupKey, true)
+
+	return ignoreSkip(step.Exec(ctx, sampleState))
+}
+
+// warmupOnly skips the leaf Step(s) which are not labeled with WarmupLabel,
+// while the DAG is executed by Warmup. It is added by Executor.build.
+func warmupOnly[S any](next Step[S], info Info) Step[S] {
+	if info.CanSkip || isWarmupStep(info) {
+		return next
+	}
+
+	return StepFunc[S](func(ctx context.Context, state S) error {
+		if warmingUp(ctx) {
+			return &ErrSkip{}
+		}
+
+		return next.Exec(ctx, state)
+	})
+}
+
+// warmingUp reports whether ctx is the one of an execution by Warmup.
+func warmingUp(ctx context.Context) bool {
+	v, _ := ctx.Value(warmupKey).(bool)
+	return v
+}
+
+func isWarmupStep(info Info) bool { return info.Labels[WarmupLabel] == "true" }
+
+// hasWarmupSteps reports whether a Step of the DAG is labeled with WarmupLabel.
+func hasWarmupSteps[S any](start Step[S]) bool {
+	found := false
+
+	Walk(start, func(_ Step[S], info Info, _ int) bool {
+		found = found || isWarmupStep(info)
+		return !found
+	})
+
+	return found
+}
@@ -0,0 +1,94 @@
+package dagger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutor_Warmup(t *testing.T) {
+	var executed []string
+
+	step := func(name string) Step[testState] {
+		return Named(name, NewStep(func(context.Context, testState) error {
+			executed = append(executed, name)
+			return nil
+		}))
+	}
+
+	dag, err := New(Series(
+		WithLabels(step("load-cache"), map[string]string{WarmupLabel: "true"}),
+		step("create"),
+		If(alwaysTrue, WithLabels(step("connect"), map[string]string{WarmupLabel: "true"})),
+		WithLabels(step("publish"), map[string]string{WarmupLabel: "false"}),
+	))
+	assert.NoError(t, err)
+
+	var outcomes []string
+	assert.NoError(t, dag.Use(AuditLog[testState](func(record AuditRecord) {
+		outcomes = append(outcomes, record.Step+": "+record.Outcome)
+	})))
+
+	assert.NoError(t, dag.Warmup(context.TODO(), testState{}))
+	assert.Equal(t, []string{"load-cache", "connect"}, executed)
+	assert.Equal(t, []string{"load-cache: success", "create: skipped", "connect: success", "publish: skipped"}, outcomes)
+
+	assert.NoError(t, dag.Use(AuditLog[testState](func(AuditRecord) {})), "Warmup does not freeze the Executor")
+
+	executed = nil
+
+	assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+	assert.Equal(t, []string{"load-cache", "create", "connect", "publish"}, executed)
+
+	t.Run("Dispatcher", func(t *testing.T) {
+		executed = nil
+
+		dag, err := New(Series(
+			WithLabels(step("load-cache"), map[string]string{WarmupLabel: "true"}),
+			step("create"),
+		))
+		assert.NoError(t, err)
+
+		dispatcher := NewDispatcher[testState](NewLocalBackend[testState]())
+		assert.NoError(t, dag.Use(dispatcher.Middleware()))
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+
+		go func() { _ = dispatcher.Work(ctx) }()
+
+		assert.NoError(t, dag.Warmup(context.TODO(), testState{}))
+		assert.Equal(t, []string{"load-cache"}, executed)
+
+		executed = nil
+
+		assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+		assert.Equal(t, []string{"load-cache", "create"}, executed, "the steps of the workers are left as is")
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		dag, err := New(Series(
+			WithLabels(step("load-cache"), map[string]string{WarmupLabel: "true"}),
+			step("create"),
+		))
+		assert.NoError(t, err)
+		assert.NoError(t, dag.EnableStepStats())
+
+		assert.NoError(t, dag.Warmup(context.TODO(), testState{}))
+		assert.Equal(t, Stats{}, dag.Stats())
+
+		assert.NoError(t, dag.Exec(context.TODO(), testState{}))
+		assert.Equal(t, int64(1), dag.Stats().Steps["load_cache"].Count)
+	})
+
+	t.Run("NoWarmupSteps", func(t *testing.T) {
+		executed = nil
+
+		dag, err := New(step("create"))
+		assert.NoError(t, err)
+
+		assert.NoError(t, dag.Warmup(context.TODO(), testState{}))
+		assert.Empty(t, executed)
+	})
+}
@@ -0,0 +1,132 @@
+package dagger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// WatchdogConfig configures Watchdog.
+type WatchdogConfig struct {
+	// Interval is the period of heartbeats and stall checks,
+	// the Step is executed as is if it is not positive.
+	Interval time.Duration
+	// Heartbeat, if set, is called every Interval while the Step executes,
+	// with the time elapsed since the Step started.
+	Heartbeat func(ctx context.Context, elapsed time.Duration)
+	// StallAfter, if set, is the time after which a Step which has not reported
+	// any Progress is considered stalled. Its context is then canceled with an
+	// ErrStalled, which is also added to the warnings of the Results, if any.
+	StallAfter time.Duration
+}
+
+type watchdogStep[S any] struct {
+	step Step[S]
+	cfg  WatchdogConfig
+}
+
+var (
+	_ StepNamer         = (*watchdogStep[any])(nil)
+	_ middlewareSkipper = (*watchdogStep[any])(nil)
+	_ rebuilder[any]    = (*watchdogStep[any])(nil)
+	_ wrapperStep[any]  = (*watchdogStep[any])(nil)
+)
+
+func (s *watchdogStep[S]) StepName() fmt.Stringer { return StepName(s.step) }
+
+func (s *watchdogStep[S]) canSkip() bool { return canSkip(s.step) }
+
+func (s *watchdogStep[S]) Unwrap() Step[S] { return s.step }
+
+func (s *watchdogStep[S]) wrapped() Step[S] { return s.step }
+
+func (s *watchdogStep[S]) rebuild(wrap wrapFunc[S]) Step[S] {
+	return &watchdogStep[S]{step: rebuild(s.step, wrap), cfg: s.cfg}
+}
+
+func (s *watchdogStep[S]) Exec(ctx context.Context, state S) error {
+	if s.cfg.Interval <= 0 {
+		return s.step.Exec(ctx, state)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	clock := ClockFromContext(ctx)
+	start := clock.Now()
+
+	var lastProgress atomic.Int64
+	lastProgress.Store(start.UnixNano())
+
+	ctx = context.WithValue(ctx, progressKey, &lastProgress)
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	// The heartbeat goroutine is joined before returning,
+	// so that no Heartbeat is called after Exec returned.
+	defer func() {
+		close(done)
+		<-stopped
+	}()
+
+	go func() {
+		defer close(stopped)
+
+		ticker := clock.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C():
+				if s.cfg.Heartbeat != nil {
+					s.cfg.Heartbeat(ctx, now.Sub(start))
+				}
+
+				since := now.Sub(time.Unix(0, lastProgress.Load()))
+				if s.cfg.StallAfter > 0 && since > s.cfg.StallAfter {
+					err := &ErrStalled{stepName: StepName(s.step), since: since}
+					if r := ResultsFromContext(ctx); r != nil {
+						r.addWarning(err)
+					}
+
+					cancel(err)
+
+					return
+				}
+			}
+		}
+	}()
+
+	err := s.step.Exec(ctx, state)
+
+	// Step(s) typically return ctx.Err() once canceled, which does not tell
+	// a stall apart from the cancellation of the execution.
+	var stalled *ErrStalled
+	if err != nil && errors.As(context.Cause(ctx), &stalled) {
+		return stalled
+	}
+
+	return err
+}
+
+// Watchdog wraps a long running Step, it calls the heartbeat of the given
+// WatchdogConfig periodically while the Step executes, and cancels the Step
+// if it stalls, i.e. does not report Progress for too long.
+func Watchdog[S any](step Step[S], cfg WatchdogConfig) Step[S] {
+	return &watchdogStep[S]{step: step, cfg: cfg}
+}
+
+// Progress reports that the Step executed with ctx is making progress,
+// which resets the stall timer of its Watchdog, if any.
+func Progress(ctx context.Context) {
+	if last, ok := ctx.Value(progressKey).(*atomic.Int64); ok {
+		last.Store(ClockFromContext(ctx).Now().UnixNano())
+	}
+}
@@ -0,0 +1,85 @@
+package dagger
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchdog(t *testing.T) {
+	t.Run("Heartbeat", func(t *testing.T) {
+		var beats atomic.Int32
+
+		step := Watchdog(Named("create", NewStep(func(ctx context.Context, state testState) error {
+			for beats.Load() < 3 {
+				Progress(ctx)
+				time.Sleep(time.Millisecond)
+			}
+
+			return nil
+		})), WatchdogConfig{
+			Interval:   time.Millisecond,
+			Heartbeat:  func(context.Context, time.Duration) { beats.Add(1) },
+			StallAfter: time.Minute,
+		})
+
+		assert.Equal(t, "create", StepName(step).String())
+		assert.NoError(t, step.Exec(context.TODO(), testState{}))
+	})
+
+	t.Run("Stalled", func(t *testing.T) {
+		results := NewResults()
+
+		dag, err := New(Watchdog(Named("create", NewStep(func(ctx context.Context, state testState) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})), WatchdogConfig{Interval: time.Millisecond, StallAfter: 5 * time.Millisecond}))
+		assert.NoError(t, err)
+
+		err = dag.Exec(WithResults(context.TODO(), results), testState{})
+
+		errStalled := new(ErrStalled)
+		assert.ErrorAs(t, err, &errStalled)
+		assert.Equal(t, "create", errStalled.StepName().String())
+		assert.Greater(t, errStalled.Since(), 5*time.Millisecond)
+
+		assert.Len(t, results.Warnings(), 1)
+		assert.ErrorAs(t, results.Warnings()[0], &errStalled)
+	})
+
+	t.Run("NoHeartbeatAfterExec", func(t *testing.T) {
+		var beats atomic.Int32
+
+		step := Watchdog(NewStep(func(ctx context.Context, state testState) error {
+			for beats.Load() < 3 {
+				time.Sleep(time.Millisecond)
+			}
+
+			return nil
+		}), WatchdogConfig{
+			Interval: time.Millisecond,
+			Heartbeat: func(context.Context, time.Duration) {
+				time.Sleep(time.Millisecond)
+				beats.Add(1)
+			},
+		})
+
+		assert.NoError(t, step.Exec(context.TODO(), testState{}))
+
+		n := beats.Load()
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, n, beats.Load())
+	})
+
+	t.Run("NoInterval", func(t *testing.T) {
+		step := Watchdog(NewStep(func(ctx context.Context, state testState) error {
+			Progress(ctx)
+			return testErrStep
+		}), WatchdogConfig{})
+
+		assert.ErrorIs(t, step.Exec(context.TODO(), testState{}), testErrStep)
+	})
+}